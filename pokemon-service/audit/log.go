@@ -0,0 +1,349 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	auth "pokemon-service/auth"
+	schema "pokemon-service/schema"
+	"sync"
+	"time"
+)
+
+// Longest line read back from the file, far above any recorded entry
+const maxLineSize = 1 << 20
+
+// Tunes the log, zero values fall back to defaults
+type Config struct {
+	// File entries are appended to as NDJSON and read back from on start, empty keeps them in memory only
+	File string
+	// Latest entries kept in memory for queries, exports read every entry from the file
+	MaxEntries int
+	// Entries waiting for the file writer, changes wait for room once it falls this far behind
+	QueueSize int
+}
+
+func (config Config) withDefaults() Config {
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = 10000
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 1024
+	}
+	return config
+}
+
+// Selects entries, empty fields match every entry
+type Filter struct {
+	Actor     string
+	Action    string
+	PokemonId string
+	RequestId string
+	// Entries recorded at or after Since and before Until
+	Since time.Time
+	Until time.Time
+}
+
+func (filter Filter) match(entry schema.AuditEntry, at time.Time) bool {
+	return (len(filter.Actor) <= 0 || entry.Actor == filter.Actor) &&
+		(len(filter.Action) <= 0 || entry.Action == filter.Action) &&
+		(len(filter.PokemonId) <= 0 || entry.PokemonId == filter.PokemonId) &&
+		(len(filter.RequestId) <= 0 || entry.RequestId == filter.RequestId) &&
+		(filter.Since.IsZero() || !at.Before(filter.Since)) &&
+		(filter.Until.IsZero() || at.Before(filter.Until))
+}
+
+// Audit actions by the event types they are recorded from, evictions are not changes anybody made
+var actions = map[string]string{
+	schema.EventCreated: schema.AuditCreate,
+	schema.EventUpdated: schema.AuditUpdate,
+	schema.EventDeleted: schema.AuditDelete,
+}
+
+type record struct {
+	entry schema.AuditEntry
+	at    time.Time
+}
+
+// Log records every change made to a pokemon with who made it and the record before and after. Entries are
+// only ever appended, to the file as well when one is configured, and fill up from the events of the store.
+// The file is written by a goroutine of its own, so the store never waits for the disk.
+type Log struct {
+	config    Config
+	logger    *schema.Logger
+	mutex     sync.Mutex
+	records   []record
+	sequence  uint64
+	file      *os.File
+	queue     chan schema.AuditEntry
+	queueLock sync.RWMutex
+	closed    bool
+	done      chan struct{}
+	// Guards the progress of the writer below, signalled after every entry
+	writeLock sync.Mutex
+	written   *sync.Cond
+	// Sequence of the last entry the writer is done with, bytes of complete lines in file and whether
+	// the writer stopped. Exports read no further than size.
+	writtenSequence uint64
+	size            int64
+	stopped         bool
+}
+
+// Creates a log, reading back the entries already in the configured file
+func NewLog(config Config, logger *schema.Logger) (*Log, error) {
+	log := &Log{config: config.withDefaults(), logger: logger}
+	log.written = sync.NewCond(&log.writeLock)
+	if len(log.config.File) <= 0 {
+		return log, nil
+	}
+	file, err := os.OpenFile(log.config.File, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := log.load(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+	log.file = file
+	log.writtenSequence = log.sequence
+	log.queue = make(chan schema.AuditEntry, log.config.QueueSize)
+	log.done = make(chan struct{})
+	go log.write()
+	return log, nil
+}
+
+// Event bus subscriber appending an entry for every change
+func (log *Log) Handle(event schema.PokemonEvent) {
+	action, ok := actions[event.Type]
+	if !ok || event.Pokemon == nil {
+		return
+	}
+	entry := schema.AuditEntry{
+		Action:    action,
+		PokemonId: event.PokemonId,
+		Actor:     event.Actor,
+		RequestId: event.RequestId,
+		Timestamp: event.OccurredAt,
+	}
+	if len(entry.Actor) <= 0 {
+		entry.Actor = auth.Anonymous
+	}
+	switch action {
+	case schema.AuditCreate:
+		entry.After = event.Pokemon
+	case schema.AuditUpdate:
+		entry.Before, entry.After = event.Previous, event.Pokemon
+	case schema.AuditDelete:
+		entry.Before = event.Pokemon
+	}
+	at, err := time.Parse(time.RFC3339Nano, entry.Timestamp)
+	if err != nil {
+		at = time.Now()
+		entry.Timestamp = at.UTC().Format(time.RFC3339Nano)
+	}
+
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	log.sequence++
+	entry.Sequence = log.sequence
+	log.append(record{entry: entry, at: at})
+	if log.queue == nil {
+		return
+	}
+	//Queued with the mutex held so the file keeps the order of the sequence. The writer never takes the
+	//mutex, a full queue only holds the change up until it has room again.
+	log.queueLock.RLock()
+	defer log.queueLock.RUnlock()
+	if log.closed {
+		log.logger.WarnLogger.Println("Audit log is closed, entry", entry.Sequence, "for pokemon", entry.PokemonId, "is kept in memory only")
+		return
+	}
+	log.queue <- entry
+}
+
+// Entries kept in memory matching filter, the latest first and at most limit of them
+func (log *Log) Query(filter Filter, limit int) []schema.AuditEntry {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	entries := []schema.AuditEntry{}
+	for i := len(log.records) - 1; i >= 0 && len(entries) < limit; i-- {
+		if filter.match(log.records[i].entry, log.records[i].at) {
+			entries = append(entries, log.records[i].entry)
+		}
+	}
+	return entries
+}
+
+// Writes every entry matching filter to w as NDJSON, oldest first. With a file configured the entries are read
+// from it, so the export also has the ones no longer kept in memory.
+func (log *Log) Export(w io.Writer, filter Filter) error {
+	log.mutex.Lock()
+	if log.queue == nil {
+		records := append([]record{}, log.records...)
+		log.mutex.Unlock()
+		encoder := json.NewEncoder(w)
+		for _, item := range records {
+			if !filter.match(item.entry, item.at) {
+				continue
+			}
+			if err := encoder.Encode(item.entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	sequence := log.sequence
+	log.mutex.Unlock()
+
+	//Every entry handled before the export is in it, even the ones still queued for the writer
+	log.writeLock.Lock()
+	for log.writtenSequence < sequence && !log.stopped {
+		log.written.Wait()
+	}
+	size := log.size
+	log.writeLock.Unlock()
+
+	file, err := os.Open(log.config.File)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return readEntries(io.LimitReader(file, size), func(entry schema.AuditEntry, at time.Time, line []byte) error {
+		if !filter.match(entry, at) {
+			return nil
+		}
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+		_, err := w.Write([]byte{'\n'})
+		return err
+	}, nil)
+}
+
+// Stops the writer, entries still queued are written before the file is closed
+func (log *Log) Close() {
+	if log.queue == nil {
+		return
+	}
+	log.queueLock.Lock()
+	if !log.closed {
+		log.closed = true
+		close(log.queue)
+	}
+	log.queueLock.Unlock()
+	<-log.done
+
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	if log.file != nil {
+		log.file.Close()
+		log.file = nil
+	}
+}
+
+// Appends the queued entries to the file until Close
+func (log *Log) write() {
+	defer close(log.done)
+	defer func() {
+		log.writeLock.Lock()
+		log.stopped = true
+		log.writeLock.Unlock()
+		log.written.Broadcast()
+	}()
+	for entry := range log.queue {
+		written := log.writeEntry(entry)
+		log.writeLock.Lock()
+		log.writtenSequence = entry.Sequence
+		log.size += written
+		log.writeLock.Unlock()
+		log.written.Broadcast()
+	}
+}
+
+// Writes entry as a line of the file and returns its size, zero when it could not be written
+func (log *Log) writeEntry(entry schema.AuditEntry) int64 {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		log.logger.ErrorLogger.Println("Unable to encode audit entry", entry.Sequence, "for pokemon", entry.PokemonId, ":", err)
+		return 0
+	}
+	written, err := log.file.Write(append(line, '\n'))
+	if err != nil {
+		log.logger.ErrorLogger.Println("Unable to write audit entry", entry.Sequence, "for pokemon", entry.PokemonId, ":", err)
+		return 0
+	}
+	return int64(written)
+}
+
+// Reads back the entries of file and continues their sequence
+func (log *Log) load(file *os.File) error {
+	err := readEntries(file, func(entry schema.AuditEntry, at time.Time, _ []byte) error {
+		log.append(record{entry: entry, at: at})
+		if entry.Sequence > log.sequence {
+			log.sequence = entry.Sequence
+		}
+		return nil
+	}, func(line []byte) {
+		log.logger.WarnLogger.Println("Skipped unreadable audit entry:", string(line))
+	})
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	log.size = info.Size()
+	//A crash in the middle of a write leaves a partial line, the next entry starts on a line of its own
+	if log.size > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, log.size-1); err != nil {
+			return err
+		}
+		if last[0] != '\n' {
+			written, err := file.Write([]byte{'\n'})
+			if err != nil {
+				return err
+			}
+			log.size += int64(written)
+		}
+	}
+	return nil
+}
+
+// Keeps record in memory, dropping the oldest beyond MaxEntries. Called with the mutex held.
+func (log *Log) append(item record) {
+	log.records = append(log.records, item)
+	if len(log.records) > log.config.MaxEntries {
+		log.records = log.records[len(log.records)-log.config.MaxEntries:]
+	}
+}
+
+// Calls read with every entry in r, unreadable lines go to skip when it is set
+func readEntries(r io.Reader, read func(schema.AuditEntry, time.Time, []byte) error, skip func([]byte)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) <= 0 {
+			continue
+		}
+		var entry schema.AuditEntry
+		err := json.Unmarshal(line, &entry)
+		var at time.Time
+		if err == nil {
+			at, err = time.Parse(time.RFC3339Nano, entry.Timestamp)
+		}
+		if err != nil {
+			if skip != nil {
+				skip(line)
+			}
+			continue
+		}
+		if err := read(entry, at, line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,181 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	schema "pokemon-service/schema"
+	"strings"
+	"testing"
+	"time"
+)
+
+var base = time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+
+func TestLog(t *testing.T) {
+	auditLog, err := NewLog(Config{}, discardLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer auditLog.Close()
+	for _, event := range changes() {
+		auditLog.Handle(event)
+	}
+
+	inputs := []struct {
+		testName string
+		filter   Filter
+		limit    int
+		expected string
+	}{
+		{testName: "TestLogEverything", limit: 10, expected: "[4 3 2 1]"},
+		{testName: "TestLogLimit", limit: 2, expected: "[4 3]"},
+		{testName: "TestLogActor", filter: Filter{Actor: "ash"}, limit: 10, expected: "[3 1]"},
+		{testName: "TestLogAnonymous", filter: Filter{Actor: "anonymous"}, limit: 10, expected: "[4]"},
+		{testName: "TestLogAction", filter: Filter{Action: schema.AuditUpdate}, limit: 10, expected: "[2]"},
+		{testName: "TestLogPokemonId", filter: Filter{PokemonId: "PK1"}, limit: 10, expected: "[4 2 1]"},
+		{testName: "TestLogRequestId", filter: Filter{RequestId: "req-2"}, limit: 10, expected: "[2]"},
+		{testName: "TestLogSinceUntil", filter: Filter{Since: base.Add(time.Minute), Until: base.Add(3 * time.Minute)}, limit: 10, expected: "[3 2]"},
+	}
+	for _, item := range inputs {
+		if sequences := sequencesOf(auditLog.Query(item.filter, item.limit)); sequences != item.expected {
+			t.Errorf("%v: unexpected entries: got %v want %v", item.testName, sequences, item.expected)
+		}
+	}
+
+	//Creates carry the record after, deletes the one before and updates both
+	entries := auditLog.Query(Filter{PokemonId: "PK1"}, 10)
+	if deleted := entries[0]; deleted.Action != schema.AuditDelete || deleted.Before == nil || deleted.After != nil {
+		t.Errorf("unexpected delete entry: %+v", deleted)
+	}
+	if updated := entries[1]; updated.Before == nil || updated.Before.Name != "Bulbasaur" || updated.After == nil || updated.After.Name != "Ivysaur" {
+		t.Errorf("unexpected update entry: %+v", updated)
+	}
+	if created := entries[2]; created.Before != nil || created.After == nil || created.Actor != "ash" || created.RequestId != "req-1" {
+		t.Errorf("unexpected create entry: %+v", created)
+	}
+
+	var exported bytes.Buffer
+	if err := auditLog.Export(&exported, Filter{Actor: "ash"}); err != nil {
+		t.Fatal(err)
+	}
+	if sequences := sequencesOf(decodeLines(t, exported.String())); sequences != "[1 3]" {
+		t.Errorf("unexpected export: %v", sequences)
+	}
+}
+
+func TestLogFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.ndjson")
+	auditLog, err := NewLog(Config{File: path, MaxEntries: 2}, discardLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, event := range changes()[:4] {
+		auditLog.Handle(event)
+	}
+	auditLog.Close()
+
+	//Simulates a crash in the middle of writing an entry
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"Sequence":4,"Action":"del`)
+	file.Close()
+
+	auditLog, err = NewLog(Config{File: path, MaxEntries: 2}, discardLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer auditLog.Close()
+	auditLog.Handle(changes()[4])
+
+	//Memory only keeps the latest entries, the sequence continues after the ones in the file
+	if sequences := sequencesOf(auditLog.Query(Filter{}, 10)); sequences != "[4 3]" {
+		t.Errorf("unexpected entries after reopening: %v", sequences)
+	}
+	var exported bytes.Buffer
+	if err := auditLog.Export(&exported, Filter{}); err != nil {
+		t.Fatal(err)
+	}
+	if sequences := sequencesOf(decodeLines(t, exported.String())); sequences != "[1 2 3 4]" {
+		t.Errorf("unexpected export: %v", sequences)
+	}
+}
+
+func TestLogClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.ndjson")
+	auditLog, err := NewLog(Config{File: path, QueueSize: 1}, discardLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		auditLog.Handle(changes()[0])
+	}
+	auditLog.Close()
+	//Handled after Close, kept in memory only
+	auditLog.Handle(changes()[1])
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries := decodeLines(t, string(data)); len(entries) != 20 || entries[19].Sequence != 20 {
+		t.Errorf("queued entries were not written before closing, got %v", len(entries))
+	}
+	if entries := auditLog.Query(Filter{}, 1); len(entries) != 1 || entries[0].Sequence != 21 {
+		t.Errorf("unexpected entries after closing: %v", sequencesOf(entries))
+	}
+}
+
+// Bulbasaur created by ash and renamed by misty, then Squirtle created by ash and Bulbasaur deleted
+// anonymously, a minute apart. The eviction in between is not recorded.
+func changes() []schema.PokemonEvent {
+	bulbasaur := schema.Pokemon{Id: "PK1", Name: "Bulbasaur"}
+	ivysaur := schema.Pokemon{Id: "PK1", Name: "Ivysaur"}
+	squirtle := schema.Pokemon{Id: "PK2", Name: "Squirtle"}
+	at := func(minutes int) string {
+		return base.Add(time.Duration(minutes) * time.Minute).Format(time.RFC3339Nano)
+	}
+	return []schema.PokemonEvent{
+		{Type: schema.EventCreated, PokemonId: "PK1", Pokemon: &bulbasaur, Actor: "ash", RequestId: "req-1", OccurredAt: at(0)},
+		{Type: schema.EventUpdated, PokemonId: "PK1", Pokemon: &ivysaur, Previous: &bulbasaur, Actor: "misty", RequestId: "req-2", OccurredAt: at(1)},
+		{Type: schema.EventEvicted, PokemonId: "PK2", Pokemon: &squirtle, OccurredAt: at(1)},
+		{Type: schema.EventCreated, PokemonId: "PK2", Pokemon: &squirtle, Actor: "ash", RequestId: "req-3", OccurredAt: at(2)},
+		{Type: schema.EventDeleted, PokemonId: "PK1", Pokemon: &ivysaur, RequestId: "req-4", OccurredAt: at(3)},
+	}
+}
+
+func sequencesOf(entries []schema.AuditEntry) string {
+	sequences := make([]uint64, 0, len(entries))
+	for _, entry := range entries {
+		sequences = append(sequences, entry.Sequence)
+	}
+	return fmt.Sprint(sequences)
+}
+
+func decodeLines(t *testing.T, ndjson string) []schema.AuditEntry {
+	var entries []schema.AuditEntry
+	for _, line := range strings.Split(strings.TrimSpace(ndjson), "\n") {
+		var entry schema.AuditEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid NDJSON line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func discardLogger() *schema.Logger {
+	return &schema.Logger{
+		InfoLogger:  log.New(io.Discard, "Info:", 0),
+		WarnLogger:  log.New(io.Discard, "Warn:", 0),
+		DebugLogger: log.New(io.Discard, "Debug:", 0),
+		ErrorLogger: log.New(io.Discard, "Error:", 0),
+		FatalLogger: log.New(io.Discard, "Fatal:", 0),
+	}
+}
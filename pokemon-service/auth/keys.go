@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Actor of requests that do not identify themselves
+const Anonymous = "anonymous"
+
+type actorKey struct{}
+
+// Identity a key authenticates, the actor changes are recorded under and the tenant it acts in
+type Identity struct {
+	Actor string
+	// Empty for the default tenant
+	Tenant string
+}
+
+// Tenant the identity acts in when requested is asked for, empty asks for its own. Identities only act
+// in their own tenant, anonymous ones in the default tenant, anything else is ErrForeignTenant.
+func (identity Identity) Select(requested string) (string, error) {
+	tenant := identity.Tenant
+	if len(tenant) <= 0 {
+		tenant = DefaultTenant
+	}
+	if len(requested) > 0 && requested != tenant {
+		return "", fmt.Errorf("%w %v, %v acts in tenant %v", ErrForeignTenant, requested, identity.actor(), tenant)
+	}
+	return tenant, nil
+}
+
+func (identity Identity) actor() string {
+	if len(identity.Actor) <= 0 {
+		return Anonymous
+	}
+	return identity.Actor
+}
+
+// Keys maps the API keys callers identify themselves with to the actor they act as
+type Keys struct {
+	actors map[string]Identity
+}
+
+// Parses keys written as actor:key pairs separated by commas, like "ash:s3cret,misty:t0gepi".
+// An actor written as actor@tenant acts in that tenant, the others in the default tenant.
+// No keys leaves every request anonymous.
+func ParseKeys(value string) (*Keys, error) {
+	keys := &Keys{actors: map[string]Identity{}}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if len(pair) <= 0 {
+			continue
+		}
+		actor, key, ok := strings.Cut(pair, ":")
+		actor, key = strings.TrimSpace(actor), strings.TrimSpace(key)
+		if !ok || len(actor) <= 0 || len(key) <= 0 {
+			return nil, fmt.Errorf("invalid API key %q, expected actor:key", pair)
+		}
+		identity := Identity{Actor: actor}
+		if name, tenant, ok := strings.Cut(actor, "@"); ok {
+			if len(name) <= 0 || !ValidTenant(tenant) {
+				return nil, fmt.Errorf("invalid API key %q, expected actor@tenant:key with a tenant of letters, digits, - and _", pair)
+			}
+			identity = Identity{Actor: name, Tenant: tenant}
+		}
+		if _, taken := keys.actors[key]; taken {
+			return nil, fmt.Errorf("API key of %v is also given to another actor", actor)
+		}
+		keys.actors[key] = identity
+	}
+	return keys, nil
+}
+
+// Identity the key belongs to, false for keys that were not configured
+func (keys *Keys) Lookup(key string) (Identity, bool) {
+	if keys == nil || len(key) <= 0 {
+		return Identity{}, false
+	}
+	//Every key is compared so the time taken does not tell how much of a key matched
+	identity, found := Identity{}, false
+	for candidate, owner := range keys.actors {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(key)) == 1 {
+			identity, found = owner, true
+		}
+	}
+	return identity, found
+}
+
+// Tenants the keys act in other than the default one, sorted
+func (keys *Keys) Tenants() []string {
+	if keys == nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	tenants := []string{}
+	for _, identity := range keys.actors {
+		if len(identity.Tenant) > 0 && identity.Tenant != DefaultTenant && !seen[identity.Tenant] {
+			seen[identity.Tenant] = true
+			tenants = append(tenants, identity.Tenant)
+		}
+	}
+	sort.Strings(tenants)
+	return tenants
+}
+
+// Attaches the actor changes made with ctx are recorded under
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor attached with WithActor, Anonymous when there is none
+func Actor(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && len(actor) > 0 {
+		return actor
+	}
+	return Anonymous
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	inputs := []struct {
+		testName string
+		value    string
+		valid    bool
+	}{
+		{testName: "TestParseKeysEmpty", value: "", valid: true},
+		{testName: "TestParseKeysPairs", value: "ash:s3cret, misty:t0gepi,", valid: true},
+		{testName: "TestParseKeysMissingKey", value: "ash:", valid: false},
+		{testName: "TestParseKeysMissingActor", value: ":s3cret", valid: false},
+		{testName: "TestParseKeysNoSeparator", value: "ash", valid: false},
+		{testName: "TestParseKeysSharedKey", value: "ash:s3cret,misty:s3cret", valid: false},
+		{testName: "TestParseKeysTenant", value: "ash@kanto:s3cret", valid: true},
+		{testName: "TestParseKeysMissingTenant", value: "ash@:s3cret", valid: false},
+		{testName: "TestParseKeysInvalidTenant", value: "ash@../etc:s3cret", valid: false},
+	}
+
+	for _, item := range inputs {
+		_, err := ParseKeys(item.value)
+		if (err == nil) != item.valid {
+			t.Errorf("%v: unexpected error: %v", item.testName, err)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	keys, err := ParseKeys("ash:s3cret,misty@johto:t0gepi")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inputs := []struct {
+		key      string
+		identity Identity
+		found    bool
+	}{
+		{key: "s3cret", identity: Identity{Actor: "ash"}, found: true},
+		{key: "t0gepi", identity: Identity{Actor: "misty", Tenant: "johto"}, found: true},
+		{key: "s3cre", found: false},
+		{key: "", found: false},
+	}
+
+	for _, item := range inputs {
+		identity, found := keys.Lookup(item.key)
+		if identity != item.identity || found != item.found {
+			t.Errorf("%q: unexpected lookup: got %v %v want %v %v", item.key, identity, found, item.identity, item.found)
+		}
+	}
+	if tenants := keys.Tenants(); len(tenants) != 1 || tenants[0] != "johto" {
+		t.Errorf("unexpected tenants: %v", tenants)
+	}
+	var none *Keys
+	if _, found := none.Lookup("s3cret"); found {
+		t.Errorf("nil keys found an actor")
+	}
+}
+
+func TestActor(t *testing.T) {
+	if actor := Actor(context.Background()); actor != Anonymous {
+		t.Errorf("unexpected actor without one attached: %v", actor)
+	}
+	if actor := Actor(WithActor(context.Background(), "ash")); actor != "ash" {
+		t.Errorf("unexpected actor: got %v want ash", actor)
+	}
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Tenant of requests that do not name one and of keys not bound to one
+const DefaultTenant = "default"
+
+// The identity of a request is not allowed to act in the tenant it asked for
+var ErrForeignTenant = errors.New("not allowed to act in tenant")
+
+// Tenant names end up in paths and file names, so they are kept to a safe alphabet
+var tenantName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+type tenantKey struct{}
+
+// Whether name can name a tenant
+func ValidTenant(name string) bool {
+	return tenantName.MatchString(name)
+}
+
+// Parses tenants written as name or name:quota separated by commas, like "kanto:500,johto". The quota
+// limits the pokemons the tenant holds, 0 or none leaves it unlimited. The default tenant always exists,
+// its quota can be set the same way.
+func ParseTenants(value string) (map[string]int, error) {
+	tenants := map[string]int{DefaultTenant: 0}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) <= 0 {
+			continue
+		}
+		name, quota, hasQuota := strings.Cut(entry, ":")
+		name = strings.TrimSpace(name)
+		if !ValidTenant(name) {
+			return nil, fmt.Errorf("invalid tenant %q, expected a name of letters, digits, - and _", entry)
+		}
+		limit := 0
+		if hasQuota {
+			parsed, err := strconv.Atoi(strings.TrimSpace(quota))
+			if err != nil || parsed < 0 {
+				return nil, fmt.Errorf("invalid quota of tenant %v, expected a number of pokemons", name)
+			}
+			limit = parsed
+		}
+		tenants[name] = limit
+	}
+	return tenants, nil
+}
+
+// Attaches the tenant whose catalog requests made with ctx are served from
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// Tenant attached with WithTenant, DefaultTenant when there is none
+func Tenant(ctx context.Context) string {
+	if tenant, ok := ctx.Value(tenantKey{}).(string); ok && len(tenant) > 0 {
+		return tenant
+	}
+	return DefaultTenant
+}
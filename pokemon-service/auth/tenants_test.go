@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseTenants(t *testing.T) {
+	inputs := []struct {
+		testName string
+		value    string
+		valid    bool
+		tenants  map[string]int
+	}{
+		{testName: "TestParseTenantsEmpty", value: "", valid: true, tenants: map[string]int{DefaultTenant: 0}},
+		{testName: "TestParseTenantsQuotas", value: "kanto:500, johto,", valid: true, tenants: map[string]int{DefaultTenant: 0, "kanto": 500, "johto": 0}},
+		{testName: "TestParseTenantsDefaultQuota", value: "default:10", valid: true, tenants: map[string]int{DefaultTenant: 10}},
+		{testName: "TestParseTenantsInvalidName", value: "kan/to", valid: false},
+		{testName: "TestParseTenantsInvalidQuota", value: "kanto:many", valid: false},
+		{testName: "TestParseTenantsNegativeQuota", value: "kanto:-1", valid: false},
+	}
+
+	for _, item := range inputs {
+		tenants, err := ParseTenants(item.value)
+		if (err == nil) != item.valid {
+			t.Errorf("%v: unexpected error: %v", item.testName, err)
+			continue
+		}
+		if !item.valid {
+			continue
+		}
+		if len(tenants) != len(item.tenants) {
+			t.Errorf("%v: unexpected tenants: got %v want %v", item.testName, tenants, item.tenants)
+		}
+		for name, quota := range item.tenants {
+			if got, ok := tenants[name]; !ok || got != quota {
+				t.Errorf("%v: unexpected quota of %v: got %v want %v", item.testName, name, got, quota)
+			}
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	inputs := []struct {
+		testName  string
+		identity  Identity
+		requested string
+		tenant    string
+		err       error
+	}{
+		{testName: "TestSelectAnonymous", identity: Identity{}, tenant: DefaultTenant},
+		{testName: "TestSelectAnonymousDefault", identity: Identity{}, requested: DefaultTenant, tenant: DefaultTenant},
+		{testName: "TestSelectAnonymousForeign", identity: Identity{}, requested: "kanto", err: ErrForeignTenant},
+		{testName: "TestSelectOwnTenant", identity: Identity{Actor: "ash", Tenant: "kanto"}, tenant: "kanto"},
+		{testName: "TestSelectOwnTenantByName", identity: Identity{Actor: "ash", Tenant: "kanto"}, requested: "kanto", tenant: "kanto"},
+		{testName: "TestSelectForeignTenant", identity: Identity{Actor: "ash", Tenant: "kanto"}, requested: DefaultTenant, err: ErrForeignTenant},
+	}
+
+	for _, item := range inputs {
+		tenant, err := item.identity.Select(item.requested)
+		if tenant != item.tenant || !errors.Is(err, item.err) {
+			t.Errorf("%v: unexpected selection: got %v %v want %v %v", item.testName, tenant, err, item.tenant, item.err)
+		}
+	}
+}
+
+func TestTenant(t *testing.T) {
+	if tenant := Tenant(context.Background()); tenant != DefaultTenant {
+		t.Errorf("unexpected tenant without one attached: %v", tenant)
+	}
+	if tenant := Tenant(WithTenant(context.Background(), "kanto")); tenant != "kanto" {
+		t.Errorf("unexpected tenant: got %v want kanto", tenant)
+	}
+}
@@ -0,0 +1,183 @@
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	// Returned by codecs that can only carry some payloads, such as CSV which only carries lists
+	ErrNotRepresentable = errors.New("payload cannot be represented in this media type")
+)
+
+// Codec encodes responses to and decodes requests from one media type
+type Codec interface {
+	MediaType() string
+	Encode(w io.Writer, v interface{}) error
+	Decode(r io.Reader, v interface{}) error
+}
+
+var (
+	JSON        Codec = jsonCodec{}
+	MessagePack Codec = msgpackCodec{}
+	Protobuf    Codec = protobufCodec{}
+	CSV         Codec = csvCodec{}
+)
+
+// Response codecs in order of preference, used when the client accepts several equally
+var responseCodecs = []Codec{JSON, MessagePack, Protobuf, CSV}
+
+// Media types each codec is also known by
+var aliases = map[string]Codec{
+	"application/json":                JSON,
+	"application/msgpack":             MessagePack,
+	"application/x-msgpack":           MessagePack,
+	"application/vnd.msgpack":         MessagePack,
+	"application/x-protobuf":          Protobuf,
+	"application/protobuf":            Protobuf,
+	"application/vnd.google.protobuf": Protobuf,
+	"text/csv":                        CSV,
+}
+
+// Media types the response codecs produce, for error messages and documentation
+func MediaTypes() []string {
+	mediaTypes := make([]string, 0, len(responseCodecs))
+	for _, codec := range responseCodecs {
+		mediaTypes = append(mediaTypes, codec.MediaType())
+	}
+	return mediaTypes
+}
+
+// Picks the response codec for an Accept header following its quality values, JSON when the header is empty.
+// Returns false when none of the accepted media types can be produced.
+func Negotiate(accept string) (Codec, bool) {
+	if len(strings.TrimSpace(accept)) <= 0 {
+		return JSON, true
+	}
+
+	ranges := parseAccept(accept)
+	var chosen Codec
+	chosenQuality := 0.0
+	for _, codec := range responseCodecs {
+		if quality := qualityOf(codec, ranges); quality > chosenQuality {
+			chosen, chosenQuality = codec, quality
+		}
+	}
+	return chosen, chosen != nil
+}
+
+// Codec decoding request bodies sent with contentType, JSON when it is empty. CSV bodies are not accepted.
+func ForContentType(contentType string) (Codec, bool) {
+	if len(contentType) <= 0 {
+		return JSON, true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	codec, ok := aliases[mediaType]
+	if !ok || codec == CSV {
+		return nil, false
+	}
+	return codec, true
+}
+
+// Decodes the request body in the media type named by its Content-Type.
+// Errors wrap ErrUnsupportedMediaType when that type cannot be decoded.
+func DecodeRequest(req *http.Request, v interface{}) error {
+	codec, ok := ForContentType(req.Header.Get("Content-Type"))
+	if !ok {
+		return ErrUnsupportedMediaType
+	}
+	return codec.Decode(req.Body, v)
+}
+
+// ResponseWriter carries the codec negotiated for a response down to the code framing it
+type ResponseWriter struct {
+	http.ResponseWriter
+	Codec Codec
+}
+
+func (w *ResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Codec negotiated for w, looking through writers wrapping it. JSON when nothing was negotiated.
+func Of(w http.ResponseWriter) Codec {
+	for {
+		switch writer := w.(type) {
+		case *ResponseWriter:
+			return writer.Codec
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = writer.Unwrap()
+		default:
+			return JSON
+		}
+	}
+}
+
+type acceptRange struct {
+	mediaType string
+	quality   float64
+}
+
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, quality: quality})
+	}
+	return ranges
+}
+
+// Quality the most specific matching range gives to any media type of codec, 0 when none matches
+func qualityOf(codec Codec, ranges []acceptRange) float64 {
+	quality, specificity := 0.0, 0
+	for mediaType, aliased := range aliases {
+		if aliased != codec {
+			continue
+		}
+		for _, accepted := range ranges {
+			matched := 0
+			switch {
+			case accepted.mediaType == mediaType:
+				matched = 3
+			case accepted.mediaType == strings.SplitN(mediaType, "/", 2)[0]+"/*":
+				matched = 2
+			case accepted.mediaType == "*/*":
+				matched = 1
+			}
+			if matched > specificity || (matched == specificity && matched > 0 && accepted.quality > quality) {
+				quality, specificity = accepted.quality, matched
+			}
+		}
+	}
+	return quality
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) MediaType() string { return "application/json" }
+
+func (jsonCodec) Encode(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+func (jsonCodec) Decode(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
@@ -0,0 +1,136 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	schema "pokemon-service/schema"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestNegotiate(t *testing.T) {
+	inputs := []struct {
+		testName string
+		accept   string
+		codec    Codec
+		ok       bool
+	}{
+		{testName: "TestNegotiateEmpty", accept: "", codec: JSON, ok: true},
+		{testName: "TestNegotiateAny", accept: "*/*", codec: JSON, ok: true},
+		{testName: "TestNegotiateMessagePack", accept: "application/msgpack", codec: MessagePack, ok: true},
+		{testName: "TestNegotiateAlias", accept: "application/vnd.google.protobuf", codec: Protobuf, ok: true},
+		{testName: "TestNegotiateQuality", accept: "application/json;q=0.5, text/csv", codec: CSV, ok: true},
+		{testName: "TestNegotiateWildcardFallback", accept: "application/xml, */*;q=0.1", codec: JSON, ok: true},
+		{testName: "TestNegotiateExcluded", accept: "application/json;q=0, application/*", codec: MessagePack, ok: true},
+		{testName: "TestNegotiateUnsupported", accept: "application/xml", ok: false},
+	}
+
+	for _, item := range inputs {
+		codec, ok := Negotiate(item.accept)
+		if ok != item.ok || codec != item.codec {
+			t.Errorf("%v: got %v %v want %v %v", item.testName, codec, ok, item.codec, item.ok)
+		}
+	}
+}
+
+func TestForContentType(t *testing.T) {
+	inputs := []struct {
+		contentType string
+		codec       Codec
+		ok          bool
+	}{
+		{contentType: "", codec: JSON, ok: true},
+		{contentType: "application/json; charset=utf-8", codec: JSON, ok: true},
+		{contentType: "application/x-msgpack", codec: MessagePack, ok: true},
+		{contentType: "application/x-protobuf", codec: Protobuf, ok: true},
+		{contentType: "text/csv", ok: false},
+		{contentType: "application/xml", ok: false},
+	}
+
+	for _, item := range inputs {
+		codec, ok := ForContentType(item.contentType)
+		if ok != item.ok || codec != item.codec {
+			t.Errorf("%q: got %v %v want %v %v", item.contentType, codec, ok, item.codec, item.ok)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	pokemon := schema.Pokemon{Id: "PK10001", Name: "Picachoo1", Type: "TT", Height: "20.9", Weight: "30.9", Abilities: "Eat&Sleep"}
+
+	for _, codec := range []Codec{JSON, MessagePack, Protobuf} {
+		var body bytes.Buffer
+		if err := codec.Encode(&body, &schema.DataResponse{RespCode: 200, Data: []schema.Pokemon{pokemon}}); err != nil {
+			t.Fatalf("%v: unexpected encode error: %v", codec.MediaType(), err)
+		}
+		if body.Len() == 0 {
+			t.Errorf("%v: empty body", codec.MediaType())
+		}
+
+		//Request bodies are single records
+		body.Reset()
+		if codec == Protobuf {
+			body.Write(mustMarshalProto(t, pokemon))
+		} else if err := codec.Encode(&body, pokemon); err != nil {
+			t.Fatal(err)
+		}
+		var decoded schema.Pokemon
+		if err := codec.Decode(&body, &decoded); err != nil || decoded != pokemon {
+			t.Errorf("%v: unexpected decoded pokemon: %+v %v", codec.MediaType(), decoded, err)
+		}
+	}
+}
+
+func TestNotRepresentable(t *testing.T) {
+	var body bytes.Buffer
+	if err := CSV.Encode(&body, &schema.DataResponse{Data: schema.Pokemon{Id: "PK10001"}}); !errors.Is(err, ErrNotRepresentable) {
+		t.Errorf("CSV encoded a single record: %v", err)
+	}
+	if err := Protobuf.Encode(&body, &schema.DataResponse{Data: schema.CacheStats{}}); !errors.Is(err, ErrNotRepresentable) {
+		t.Errorf("protobuf encoded cache statistics: %v", err)
+	}
+
+	body.Reset()
+	pokemons := []schema.Pokemon{{Id: "PK10001", Name: "Picachoo1"}, {Id: "PK10002", Name: "Raichoo", EvolvesFrom: &schema.Evolution{Id: "PK10001", Condition: "Thunder Stone"}}}
+	if err := CSV.Encode(&body, &schema.DataResponse{Data: pokemons}); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(body.String()), "\n"); len(lines) != 3 || lines[1] != "PK10001,Picachoo1,,,,," || lines[2] != "PK10002,Raichoo,,,,,PK10001" {
+		t.Errorf("unexpected CSV: %q", body.String())
+	}
+}
+
+func TestOf(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	if Of(recorder) != JSON {
+		t.Errorf("writer without negotiation is not JSON")
+	}
+	if Of(&ResponseWriter{ResponseWriter: recorder, Codec: CSV}) != CSV {
+		t.Errorf("negotiated codec was not found")
+	}
+	wrapped := &unwrapper{&ResponseWriter{ResponseWriter: recorder, Codec: MessagePack}}
+	if Of(wrapped) != MessagePack {
+		t.Errorf("negotiated codec was not found through a wrapper")
+	}
+}
+
+type unwrapper struct {
+	*ResponseWriter
+}
+
+func (w *unwrapper) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func mustMarshalProto(t *testing.T, pokemon schema.Pokemon) []byte {
+	t.Helper()
+	data, err := proto.Marshal(toProto(pokemon))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
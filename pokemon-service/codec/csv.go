@@ -0,0 +1,43 @@
+package codec
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	schema "pokemon-service/schema"
+)
+
+// CSV writes lists of pokemons as one row per record under a header row, it carries no envelope.
+// Evolutions are flattened to the ID of the earlier stage.
+type csvCodec struct{}
+
+var csvHeader = []string{"ID", "Name", "Type", "Height", "Weight", "Abilities", "EvolvesFrom"}
+
+func (csvCodec) MediaType() string { return "text/csv" }
+
+func (csvCodec) Encode(w io.Writer, v interface{}) error {
+	resp, ok := v.(*schema.DataResponse)
+	if !ok {
+		return fmt.Errorf("%w: %T", ErrNotRepresentable, v)
+	}
+	pokemons, ok := resp.Data.([]schema.Pokemon)
+	if !ok {
+		return fmt.Errorf("%w: %T", ErrNotRepresentable, resp.Data)
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Write(csvHeader)
+	for _, pokemon := range pokemons {
+		evolvesFrom := ""
+		if pokemon.EvolvesFrom != nil {
+			evolvesFrom = pokemon.EvolvesFrom.Id
+		}
+		writer.Write([]string{pokemon.Id, pokemon.Name, pokemon.Type, pokemon.Height, pokemon.Weight, pokemon.Abilities, evolvesFrom})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (csvCodec) Decode(r io.Reader, v interface{}) error {
+	return ErrUnsupportedMediaType
+}
@@ -0,0 +1,95 @@
+package codec
+
+import (
+	"fmt"
+	"io"
+	pb "pokemon-service/pokemonpb"
+	schema "pokemon-service/schema"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Protobuf carries the response envelopes as pokemonpb.HttpResponse and takes pokemon records as
+// pokemonpb.Pokemon, or pokemonpb.AddRequest when they come with a request ID
+type protobufCodec struct{}
+
+func (protobufCodec) MediaType() string { return "application/x-protobuf" }
+
+func (protobufCodec) Encode(w io.Writer, v interface{}) error {
+	var message *pb.HttpResponse
+	switch resp := v.(type) {
+	case *schema.PokemonResponse:
+		message = &pb.HttpResponse{RequestId: resp.RequestId, RequestTs: resp.RequestTs, RespMessage: resp.RespMessage, RespCode: int32(resp.RespCode), Latency: resp.Latency}
+		if resp.Pokemon != (schema.Pokemon{}) {
+			message.Pokemon = toProto(resp.Pokemon)
+		}
+	case *schema.DataResponse:
+		message = &pb.HttpResponse{RequestId: resp.RequestId, RequestTs: resp.RequestTs, RespMessage: resp.RespMessage, RespCode: int32(resp.RespCode), Latency: resp.Latency}
+		switch data := resp.Data.(type) {
+		case nil:
+		case schema.Pokemon:
+			message.Pokemon = toProto(data)
+		case []schema.Pokemon:
+			for _, pokemon := range data {
+				message.Pokemons = append(message.Pokemons, toProto(pokemon))
+			}
+		case schema.BatchGetResult:
+			for _, pokemon := range data.Pokemons {
+				message.Pokemons = append(message.Pokemons, toProto(pokemon))
+			}
+			message.Errors = data.Errors
+		default:
+			return fmt.Errorf("%w: %T", ErrNotRepresentable, data)
+		}
+	default:
+		return fmt.Errorf("%w: %T", ErrNotRepresentable, v)
+	}
+
+	data, err := proto.Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func (protobufCodec) Decode(r io.Reader, v interface{}) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	switch target := v.(type) {
+	case *schema.Pokemon:
+		var message pb.Pokemon
+		if err := proto.Unmarshal(data, &message); err != nil {
+			return err
+		}
+		*target = fromProto(&message)
+	case *schema.PokemonRequest:
+		var message pb.AddRequest
+		if err := proto.Unmarshal(data, &message); err != nil {
+			return err
+		}
+		target.Pokemon = fromProto(message.Pokemon)
+		target.RequestId = message.RequestId
+	default:
+		return fmt.Errorf("%w: %T", ErrNotRepresentable, v)
+	}
+	return nil
+}
+
+func toProto(pokemon schema.Pokemon) *pb.Pokemon {
+	message := &pb.Pokemon{Id: pokemon.Id, Name: pokemon.Name, Type: pokemon.Type, Height: pokemon.Height, Weight: pokemon.Weight, Abilities: pokemon.Abilities}
+	if evolution := pokemon.EvolvesFrom; evolution != nil {
+		message.EvolvesFrom = &pb.Evolution{Id: evolution.Id, Level: int32(evolution.Level), Condition: evolution.Condition}
+	}
+	return message
+}
+
+func fromProto(pokemon *pb.Pokemon) schema.Pokemon {
+	decoded := schema.Pokemon{Id: pokemon.GetId(), Name: pokemon.GetName(), Type: pokemon.GetType(), Height: pokemon.GetHeight(), Weight: pokemon.GetWeight(), Abilities: pokemon.GetAbilities()}
+	if evolution := pokemon.GetEvolvesFrom(); evolution != nil {
+		decoded.EvolvesFrom = &schema.Evolution{Id: evolution.GetId(), Level: int(evolution.GetLevel()), Condition: evolution.GetCondition()}
+	}
+	return decoded
+}
@@ -0,0 +1,169 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	schema "pokemon-service/schema"
+)
+
+var ErrCorruptRecord = errors.New("corrupt record")
+
+// RecordCodec converts pokemon records to and from the bytes stored in the cache.
+// Every record in a cache has to be written with the same codec.
+type RecordCodec interface {
+	Name() string
+	Marshal(pokemon schema.Pokemon) ([]byte, error)
+	Unmarshal(data []byte, pokemon *schema.Pokemon) error
+}
+
+var (
+	JSONRecords        RecordCodec = jsonRecords{}
+	GobRecords         RecordCodec = gobRecords{}
+	MessagePackRecords RecordCodec = msgpackRecords{}
+	BinaryRecords      RecordCodec = binaryRecords{}
+)
+
+var recordCodecs = []RecordCodec{JSONRecords, GobRecords, MessagePackRecords, BinaryRecords}
+
+// Record codec configured by name, JSON when name is empty
+func RecordCodecByName(name string) (RecordCodec, error) {
+	if len(name) <= 0 {
+		return JSONRecords, nil
+	}
+	for _, records := range recordCodecs {
+		if records.Name() == name {
+			return records, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown record codec %q, expected json, gob, msgpack or binary", name)
+}
+
+// Every record codec, for comparing them
+func RecordCodecs() []RecordCodec {
+	return append([]RecordCodec{}, recordCodecs...)
+}
+
+type jsonRecords struct{}
+
+func (jsonRecords) Name() string { return "json" }
+
+func (jsonRecords) Marshal(pokemon schema.Pokemon) ([]byte, error) {
+	return json.Marshal(pokemon)
+}
+
+func (jsonRecords) Unmarshal(data []byte, pokemon *schema.Pokemon) error {
+	return json.Unmarshal(data, pokemon)
+}
+
+// Each record is a gob stream of its own, so it carries the type description too
+type gobRecords struct{}
+
+func (gobRecords) Name() string { return "gob" }
+
+func (gobRecords) Marshal(pokemon schema.Pokemon) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(pokemon)
+	return buf.Bytes(), err
+}
+
+func (gobRecords) Unmarshal(data []byte, pokemon *schema.Pokemon) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(pokemon)
+}
+
+// MessagePack maps keyed by the JSON field names
+type msgpackRecords struct{}
+
+func (msgpackRecords) Name() string { return "msgpack" }
+
+func (msgpackRecords) Marshal(pokemon schema.Pokemon) ([]byte, error) {
+	var buf bytes.Buffer
+	err := MessagePack.Encode(&buf, pokemon)
+	return buf.Bytes(), err
+}
+
+func (msgpackRecords) Unmarshal(data []byte, pokemon *schema.Pokemon) error {
+	return MessagePack.Decode(bytes.NewReader(data), pokemon)
+}
+
+// Versions of the binary layout, written first so the layout can change without misreading old records.
+// Records without an evolution are still written in the first version.
+const (
+	binaryVersion          = 1
+	binaryEvolutionVersion = 2
+)
+
+// Version byte followed by every field as a uvarint length and its bytes, in declaration order.
+// The second version appends the ID and condition of the evolution the same way and its level as a varint.
+type binaryRecords struct{}
+
+func (binaryRecords) Name() string { return "binary" }
+
+func (binaryRecords) Marshal(pokemon schema.Pokemon) ([]byte, error) {
+	fields := binaryFields(&pokemon)
+	version := byte(binaryVersion)
+	if pokemon.EvolvesFrom != nil {
+		version = binaryEvolutionVersion
+		evolution := *pokemon.EvolvesFrom
+		fields = append(fields, &evolution.Id, &evolution.Condition)
+	}
+	size := 1 + binary.MaxVarintLen64
+	for _, field := range fields {
+		size += binary.MaxVarintLen64 + len(*field)
+	}
+	data := make([]byte, 1, size)
+	data[0] = version
+	for _, field := range fields {
+		data = binary.AppendUvarint(data, uint64(len(*field)))
+		data = append(data, *field...)
+	}
+	if pokemon.EvolvesFrom != nil {
+		data = binary.AppendVarint(data, int64(pokemon.EvolvesFrom.Level))
+	}
+	return data, nil
+}
+
+func (binaryRecords) Unmarshal(data []byte, pokemon *schema.Pokemon) error {
+	if len(data) <= 0 || (data[0] != binaryVersion && data[0] != binaryEvolutionVersion) {
+		return fmt.Errorf("%w: unknown binary version", ErrCorruptRecord)
+	}
+	fields := binaryFields(pokemon)
+	var evolution *schema.Evolution
+	if data[0] == binaryEvolutionVersion {
+		evolution = &schema.Evolution{}
+		fields = append(fields, &evolution.Id, &evolution.Condition)
+	}
+	body := data[1:]
+	//One conversion for the whole record, the fields share its memory
+	text := string(body)
+	offset := 0
+	for _, field := range fields {
+		length, read := binary.Uvarint(body[offset:])
+		if read <= 0 || uint64(len(body)-offset-read) < length {
+			return fmt.Errorf("%w: truncated binary record", ErrCorruptRecord)
+		}
+		offset += read
+		*field = text[offset : offset+int(length)]
+		offset += int(length)
+	}
+	if evolution != nil {
+		level, read := binary.Varint(body[offset:])
+		if read <= 0 {
+			return fmt.Errorf("%w: truncated binary record", ErrCorruptRecord)
+		}
+		offset += read
+		evolution.Level = int(level)
+	}
+	if offset < len(body) {
+		return fmt.Errorf("%w: trailing bytes in binary record", ErrCorruptRecord)
+	}
+	pokemon.EvolvesFrom = evolution
+	return nil
+}
+
+func binaryFields(pokemon *schema.Pokemon) []*string {
+	return []*string{&pokemon.Id, &pokemon.Name, &pokemon.Type, &pokemon.Height, &pokemon.Weight, &pokemon.Abilities}
+}
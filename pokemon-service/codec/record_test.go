@@ -0,0 +1,75 @@
+package codec
+
+import (
+	"errors"
+	schema "pokemon-service/schema"
+	"reflect"
+	"testing"
+)
+
+func TestRecordCodecs(t *testing.T) {
+	inputs := []schema.Pokemon{
+		{Id: "PK10001", Name: "Picachoo1", Type: "TT", Height: "20.9", Weight: "30.9", Abilities: "Eat&Sleep"},
+		{Id: "PK10002", Name: "Flabébé"},
+		{Id: "PK10003", Name: "Floette", EvolvesFrom: &schema.Evolution{Id: "PK10002", Level: 19}},
+		{Id: "PK10004", Name: "Florges", EvolvesFrom: &schema.Evolution{Id: "PK10003", Condition: "Shiny Stone"}},
+		{},
+	}
+
+	for _, records := range RecordCodecs() {
+		for _, pokemon := range inputs {
+			data, err := records.Marshal(pokemon)
+			if err != nil {
+				t.Fatalf("%v: unexpected marshal error: %v", records.Name(), err)
+			}
+			var decoded schema.Pokemon
+			if err := records.Unmarshal(data, &decoded); err != nil || !reflect.DeepEqual(decoded, pokemon) {
+				t.Errorf("%v: unexpected round trip: got %+v %v want %+v", records.Name(), decoded, err, pokemon)
+			}
+		}
+	}
+}
+
+func TestBinaryRecordsCorrupt(t *testing.T) {
+	data, _ := BinaryRecords.Marshal(schema.Pokemon{Id: "PK10001", Name: "Picachoo1"})
+	inputs := []struct {
+		testName string
+		data     []byte
+	}{
+		{testName: "TestBinaryEmpty", data: nil},
+		{testName: "TestBinaryVersion", data: append([]byte{9}, data[1:]...)},
+		{testName: "TestBinaryTruncated", data: data[:len(data)-3]},
+		{testName: "TestBinaryTrailing", data: append(append([]byte{}, data...), 0)},
+		{testName: "TestBinaryJson", data: []byte(`{"ID":"PK10001"}`)},
+		{testName: "TestBinaryEvolutionMissing", data: append([]byte{binaryEvolutionVersion}, data[1:]...)},
+	}
+
+	for _, item := range inputs {
+		var pokemon schema.Pokemon
+		if err := BinaryRecords.Unmarshal(item.data, &pokemon); !errors.Is(err, ErrCorruptRecord) {
+			t.Errorf("%v: unexpected error: %v", item.testName, err)
+		}
+	}
+}
+
+func TestRecordCodecByName(t *testing.T) {
+	inputs := []struct {
+		name    string
+		records RecordCodec
+		err     bool
+	}{
+		{name: "", records: JSONRecords},
+		{name: "json", records: JSONRecords},
+		{name: "gob", records: GobRecords},
+		{name: "msgpack", records: MessagePackRecords},
+		{name: "binary", records: BinaryRecords},
+		{name: "xml", err: true},
+	}
+
+	for _, item := range inputs {
+		records, err := RecordCodecByName(item.name)
+		if records != item.records || (err != nil) != item.err {
+			t.Errorf("%q: got %v %v want %v", item.name, records, err, item.records)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package events
+
+import (
+	schema "pokemon-service/schema"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Subscriber receives every published event. It runs on the publisher's goroutine, which is a
+// request handler, so it must hand the event off instead of doing slow work inline.
+type Subscriber func(schema.PokemonEvent)
+
+// Bus fans catalog changes out from the write paths to interested subsystems
+type Bus struct {
+	mutex       sync.RWMutex
+	subscribers map[int]Subscriber
+	nextId      int
+	sequence    uint64
+}
+
+func NewBus() *Bus {
+	return &Bus{subscribers: map[int]Subscriber{}}
+}
+
+// Registers subscriber and returns a function removing it again
+func (bus *Bus) Subscribe(subscriber Subscriber) func() {
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+	id := bus.nextId
+	bus.nextId++
+	bus.subscribers[id] = subscriber
+	return func() {
+		bus.mutex.Lock()
+		defer bus.mutex.Unlock()
+		delete(bus.subscribers, id)
+	}
+}
+
+// Stamps event with an ID, sequence and time, then hands it to every subscriber. A nil bus drops the event.
+func (bus *Bus) Publish(event schema.PokemonEvent) schema.PokemonEvent {
+	if bus == nil {
+		return event
+	}
+	if len(event.EventId) <= 0 {
+		event.EventId = uuid.New().String()
+	}
+	if len(event.OccurredAt) <= 0 {
+		event.OccurredAt = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+
+	//Sequencing and fan out happen under one lock, so every subscriber sees events in sequence order
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+	bus.sequence++
+	event.Sequence = bus.sequence
+	for _, subscriber := range bus.subscribers {
+		subscriber(event)
+	}
+	return event
+}
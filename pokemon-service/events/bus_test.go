@@ -0,0 +1,36 @@
+package events
+
+import (
+	"pokemon-service/schema"
+	"testing"
+)
+
+func TestPublish(t *testing.T) {
+	bus := NewBus()
+	var first, second []schema.PokemonEvent
+	bus.Subscribe(func(event schema.PokemonEvent) { first = append(first, event) })
+	unsubscribe := bus.Subscribe(func(event schema.PokemonEvent) { second = append(second, event) })
+
+	published := bus.Publish(schema.PokemonEvent{Type: schema.EventCreated, PokemonId: "PK10001"})
+	if len(published.EventId) <= 0 || len(published.OccurredAt) <= 0 {
+		t.Errorf("event was not stamped: %+v", published)
+	}
+
+	unsubscribe()
+	bus.Publish(schema.PokemonEvent{Type: schema.EventDeleted, PokemonId: "PK10001"})
+
+	if len(first) != 2 {
+		t.Errorf("unexpected events for first subscriber: got %v want 2", len(first))
+	}
+	if len(second) != 1 || second[0].EventId != published.EventId {
+		t.Errorf("unexpected events for removed subscriber: %+v", second)
+	}
+}
+
+func TestPublishNilBus(t *testing.T) {
+	var bus *Bus
+	event := bus.Publish(schema.PokemonEvent{Type: schema.EventCreated})
+	if event.Type != schema.EventCreated {
+		t.Errorf("nil bus altered event: %+v", event)
+	}
+}
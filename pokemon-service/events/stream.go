@@ -0,0 +1,154 @@
+package events
+
+import (
+	schema "pokemon-service/schema"
+	"sync"
+	"sync/atomic"
+)
+
+// Events buffered per stream subscriber before it is considered too slow and cut off
+const subscriberBuffer = 64
+
+// Selects events by type and pokemon ID, empty sets match everything
+type Filter struct {
+	Types      map[string]bool
+	PokemonIds map[string]bool
+}
+
+func (filter Filter) Match(event schema.PokemonEvent) bool {
+	if len(filter.Types) > 0 && !filter.Types[event.Type] {
+		return false
+	}
+	if len(filter.PokemonIds) > 0 && !filter.PokemonIds[event.PokemonId] {
+		return false
+	}
+	return true
+}
+
+// Live feed of a single stream client. Events is closed when the client fell too far behind
+// or the stream shut down, Overflowed tells both apart.
+type StreamSubscription struct {
+	Events     <-chan schema.PokemonEvent
+	events     chan schema.PokemonEvent
+	filter     Filter
+	overflowed atomic.Bool
+}
+
+// Overflowed reports whether the subscription was cut off for not keeping up
+func (subscription *StreamSubscription) Overflowed() bool {
+	return subscription.overflowed.Load()
+}
+
+// Stream keeps the most recent events in a bounded log, so clients can resume after reconnecting,
+// and pushes new events to live subscribers. Subscribers that do not keep up are disconnected
+// instead of slowing down the publishing write paths.
+type Stream struct {
+	mutex       sync.Mutex
+	log         []schema.PokemonEvent
+	start       int
+	size        int
+	subscribers map[*StreamSubscription]struct{}
+	closed      bool
+}
+
+// Creates a stream retaining up to capacity events for resumption
+func NewStream(capacity int) *Stream {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &Stream{
+		log:         make([]schema.PokemonEvent, capacity),
+		subscribers: map[*StreamSubscription]struct{}{},
+	}
+}
+
+// Event bus subscriber, records event and forwards it to matching subscribers without blocking
+func (stream *Stream) Append(event schema.PokemonEvent) {
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+	if stream.closed {
+		return
+	}
+
+	if stream.size < len(stream.log) {
+		stream.log[(stream.start+stream.size)%len(stream.log)] = event
+		stream.size++
+	} else {
+		stream.log[stream.start] = event
+		stream.start = (stream.start + 1) % len(stream.log)
+	}
+
+	for subscription := range stream.subscribers {
+		if !subscription.filter.Match(event) {
+			continue
+		}
+		select {
+		case subscription.events <- event:
+		default:
+			subscription.overflowed.Store(true)
+			stream.remove(subscription)
+		}
+	}
+}
+
+// Registers a live subscription and returns the retained events after lastSequence matching filter.
+// Both happen atomically, so no event is lost or delivered twice between backlog and live feed.
+// complete is false when events after lastSequence were already dropped from the log.
+func (stream *Stream) Subscribe(filter Filter, lastSequence uint64) (subscription *StreamSubscription, backlog []schema.PokemonEvent, complete bool) {
+	events := make(chan schema.PokemonEvent, subscriberBuffer)
+	subscription = &StreamSubscription{Events: events, events: events, filter: filter}
+
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+	if stream.closed {
+		close(events)
+		return subscription, nil, true
+	}
+
+	complete = true
+	if lastSequence > 0 && stream.size > 0 && stream.log[stream.start].Sequence > lastSequence+1 {
+		complete = false
+	}
+	if lastSequence > 0 {
+		for i := 0; i < stream.size; i++ {
+			event := stream.log[(stream.start+i)%len(stream.log)]
+			if event.Sequence > lastSequence && filter.Match(event) {
+				backlog = append(backlog, event)
+			}
+		}
+	}
+	stream.subscribers[subscription] = struct{}{}
+	return subscription, backlog, complete
+}
+
+// Ends a subscription, safe to call more than once
+func (stream *Stream) Unsubscribe(subscription *StreamSubscription) {
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+	stream.remove(subscription)
+}
+
+// Disconnects every subscriber and stops accepting events, used while shutting down
+func (stream *Stream) Close() {
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+	stream.closed = true
+	for subscription := range stream.subscribers {
+		stream.remove(subscription)
+	}
+}
+
+// Number of live subscribers
+func (stream *Stream) Subscribers() int {
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+	return len(stream.subscribers)
+}
+
+func (stream *Stream) remove(subscription *StreamSubscription) {
+	if _, ok := stream.subscribers[subscription]; !ok {
+		return
+	}
+	delete(stream.subscribers, subscription)
+	close(subscription.events)
+}
@@ -0,0 +1,81 @@
+package events
+
+import (
+	"pokemon-service/schema"
+	"testing"
+)
+
+func TestStreamResume(t *testing.T) {
+	stream := NewStream(3)
+	for i := uint64(1); i <= 5; i++ {
+		stream.Append(schema.PokemonEvent{Sequence: i, Type: schema.EventCreated, PokemonId: "PK10001"})
+	}
+
+	inputs := []struct {
+		testName     string
+		lastSequence uint64
+		backlog      []uint64
+		complete     bool
+	}{
+		{testName: "TestResumeNoLastEventID", lastSequence: 0, backlog: nil, complete: true},
+		{testName: "TestResumeWithinLog", lastSequence: 3, backlog: []uint64{4, 5}, complete: true},
+		{testName: "TestResumeFromOldestRetained", lastSequence: 2, backlog: []uint64{3, 4, 5}, complete: true},
+		{testName: "TestResumeBeyondLog", lastSequence: 1, backlog: []uint64{3, 4, 5}, complete: false},
+		{testName: "TestResumeUpToDate", lastSequence: 5, backlog: nil, complete: true},
+	}
+
+	for _, item := range inputs {
+		subscription, backlog, complete := stream.Subscribe(Filter{}, item.lastSequence)
+		stream.Unsubscribe(subscription)
+		if complete != item.complete {
+			t.Errorf("%v: complete got %v want %v", item.testName, complete, item.complete)
+		}
+		if len(backlog) != len(item.backlog) {
+			t.Errorf("%v: backlog got %+v want %v", item.testName, backlog, item.backlog)
+			continue
+		}
+		for i, sequence := range item.backlog {
+			if backlog[i].Sequence != sequence {
+				t.Errorf("%v: backlog got %+v want %v", item.testName, backlog, item.backlog)
+				break
+			}
+		}
+	}
+}
+
+func TestStreamFilter(t *testing.T) {
+	stream := NewStream(10)
+	subscription, _, _ := stream.Subscribe(Filter{Types: map[string]bool{schema.EventDeleted: true}, PokemonIds: map[string]bool{"PK10001": true}}, 0)
+
+	stream.Append(schema.PokemonEvent{Sequence: 1, Type: schema.EventCreated, PokemonId: "PK10001"})
+	stream.Append(schema.PokemonEvent{Sequence: 2, Type: schema.EventDeleted, PokemonId: "PK10002"})
+	stream.Append(schema.PokemonEvent{Sequence: 3, Type: schema.EventDeleted, PokemonId: "PK10001"})
+	stream.Close()
+
+	received := []uint64{}
+	for event := range subscription.Events {
+		received = append(received, event.Sequence)
+	}
+	if len(received) != 1 || received[0] != 3 {
+		t.Errorf("unexpected events: got %v want [3]", received)
+	}
+	if subscription.Overflowed() {
+		t.Error("closed subscription reported as overflowed")
+	}
+}
+
+func TestStreamSlowSubscriber(t *testing.T) {
+	stream := NewStream(10)
+	slow, _, _ := stream.Subscribe(Filter{}, 0)
+
+	//Never reading from the subscription must not block appending
+	for i := uint64(1); i <= subscriberBuffer+1; i++ {
+		stream.Append(schema.PokemonEvent{Sequence: i})
+	}
+	if !slow.Overflowed() {
+		t.Error("slow subscriber was not cut off")
+	}
+	if stream.Subscribers() != 0 {
+		t.Errorf("unexpected subscribers: got %v want 0", stream.Subscribers())
+	}
+}
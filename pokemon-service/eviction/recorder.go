@@ -0,0 +1,170 @@
+package eviction
+
+import (
+	codec "pokemon-service/codec"
+	schema "pokemon-service/schema"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/allegro/bigcache/v3"
+)
+
+// Reason an entry left the cache
+type Reason string
+
+const (
+	Expired Reason = "Expired"
+	NoSpace Reason = "NoSpace"
+	Deleted Reason = "Deleted"
+)
+
+// Size of the queue between bigcache's callback and the listeners
+const queueSize = 1024
+
+// Describes a single entry leaving the cache
+type Event struct {
+	Key    string
+	Reason Reason
+	At     time.Time
+	// Record stored under Key, only set when Decoded is true
+	Pokemon schema.Pokemon
+	Decoded bool
+}
+
+// Listener reacts to entries leaving the cache
+type Listener func(Event)
+
+// Recorder counts removals by reason, logs them and fans them out to registered listeners.
+// bigcache fires OnRemoveWithReason while holding the shard lock, so listeners run on a separate
+// goroutine and may safely call back into the cache.
+type Recorder struct {
+	logger    *schema.Logger
+	records   codec.RecordCodec
+	counters  map[Reason]*atomic.Int64
+	dropped   atomic.Int64
+	mutex     sync.RWMutex
+	listeners []Listener
+	queue     chan Event
+	queueLock sync.RWMutex
+	closed    bool
+	done      chan struct{}
+}
+
+// Creates a recorder and starts its dispatching goroutine, Close stops it. Removed entries are decoded
+// with records, which has to be the codec the cache is written with, JSON when it is nil.
+func NewRecorder(logger *schema.Logger, records codec.RecordCodec) *Recorder {
+	if records == nil {
+		records = codec.JSONRecords
+	}
+	recorder := &Recorder{
+		logger:  logger,
+		records: records,
+		counters: map[Reason]*atomic.Int64{
+			Expired: {},
+			NoSpace: {},
+			Deleted: {},
+		},
+		queue: make(chan Event, queueSize),
+		done:  make(chan struct{}),
+	}
+	go recorder.dispatch()
+	return recorder
+}
+
+// Registers a listener called for every removal after the recorder counted it
+func (recorder *Recorder) Subscribe(listener Listener) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	recorder.listeners = append(recorder.listeners, listener)
+}
+
+// Matches bigcache's OnRemoveWithReason callback signature so it can be plugged into the cache config
+func (recorder *Recorder) OnRemoveWithReason(key string, entry []byte, reason bigcache.RemoveReason) {
+	event := Event{Key: key, Reason: fromBigcache(reason), At: time.Now()}
+	if err := recorder.records.Unmarshal(entry, &event.Pokemon); err == nil {
+		event.Decoded = true
+	}
+	recorder.counters[event.Reason].Add(1)
+
+	//Never block bigcache, slow listeners lose events instead
+	recorder.queueLock.RLock()
+	defer recorder.queueLock.RUnlock()
+	if recorder.closed {
+		return
+	}
+	select {
+	case recorder.queue <- event:
+	default:
+		recorder.dropped.Add(1)
+	}
+}
+
+// Returns removal counts keyed by reason, plus events dropped because listeners fell behind
+func (recorder *Recorder) Snapshot() map[string]int64 {
+	if recorder == nil {
+		return map[string]int64{}
+	}
+	snapshot := map[string]int64{"Dropped": recorder.dropped.Load()}
+	for reason, counter := range recorder.counters {
+		snapshot[string(reason)] = counter.Load()
+	}
+	return snapshot
+}
+
+// Stops dispatching, events still queued are delivered before it returns
+func (recorder *Recorder) Close() {
+	recorder.queueLock.Lock()
+	if !recorder.closed {
+		recorder.closed = true
+		close(recorder.queue)
+	}
+	recorder.queueLock.Unlock()
+	<-recorder.done
+}
+
+func (recorder *Recorder) dispatch() {
+	defer close(recorder.done)
+	for event := range recorder.queue {
+		recorder.log(event)
+		recorder.mutex.RLock()
+		listeners := recorder.listeners
+		recorder.mutex.RUnlock()
+		for _, listener := range listeners {
+			recorder.notify(listener, event)
+		}
+	}
+}
+
+// Calls listener, a panicking listener must not stop the dispatching goroutine
+func (recorder *Recorder) notify(listener Listener, event Event) {
+	defer func() {
+		if err := recover(); err != nil && recorder.logger != nil {
+			recorder.logger.ErrorLogger.Println("Eviction listener failed for key:", event.Key, "err:", err)
+		}
+	}()
+	listener(event)
+}
+
+func (recorder *Recorder) log(event Event) {
+	if recorder.logger == nil {
+		return
+	}
+	//Deletes are requested by clients and already logged with the request, evictions are not
+	if event.Reason == Deleted {
+		recorder.logger.DebugLogger.Println("Removed key:", event.Key, "reason:", event.Reason)
+		return
+	}
+	recorder.logger.InfoLogger.Println("Evicted key:", event.Key, "reason:", event.Reason)
+}
+
+func fromBigcache(reason bigcache.RemoveReason) Reason {
+	switch reason {
+	case bigcache.Expired:
+		return Expired
+	case bigcache.NoSpace:
+		return NoSpace
+	default:
+		return Deleted
+	}
+}
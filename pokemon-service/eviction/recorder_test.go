@@ -0,0 +1,96 @@
+package eviction
+
+import (
+	"encoding/json"
+	"pokemon-service/schema"
+	"testing"
+	"time"
+
+	"github.com/allegro/bigcache/v3"
+)
+
+func TestOnRemoveWithReason(t *testing.T) {
+	recorder := NewRecorder(nil, nil)
+	received := make(chan Event, 10)
+	recorder.Subscribe(func(event Event) { received <- event })
+
+	record, _ := json.Marshal(schema.Pokemon{Id: "PK10001", Name: "Chespin"})
+	inputs := []struct {
+		key     string
+		entry   []byte
+		reason  bigcache.RemoveReason
+		want    Reason
+		decoded bool
+	}{
+		{key: "PK10001", entry: record, reason: bigcache.Expired, want: Expired, decoded: true},
+		{key: "Chespin", entry: record, reason: bigcache.NoSpace, want: NoSpace, decoded: true},
+		{key: "PK10002", entry: []byte("not json"), reason: bigcache.Deleted, want: Deleted, decoded: false},
+	}
+
+	for _, item := range inputs {
+		recorder.OnRemoveWithReason(item.key, item.entry, item.reason)
+		select {
+		case event := <-received:
+			if event.Key != item.key || event.Reason != item.want || event.Decoded != item.decoded {
+				t.Errorf("unexpected event: got %+v want key %v reason %v decoded %v", event, item.key, item.want, item.decoded)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("listener was not called for key %v", item.key)
+		}
+	}
+
+	recorder.Close()
+	snapshot := recorder.Snapshot()
+	for _, reason := range []string{"Expired", "NoSpace", "Deleted"} {
+		if snapshot[reason] != 1 {
+			t.Errorf("unexpected count for %v: got %v want 1", reason, snapshot[reason])
+		}
+	}
+}
+
+func TestRecorderWithBigcache(t *testing.T) {
+	recorder := NewRecorder(nil, nil)
+	received := make(chan Event, 10)
+	recorder.Subscribe(func(event Event) { received <- event })
+
+	config := bigcache.DefaultConfig(time.Hour)
+	config.OnRemoveWithReason = recorder.OnRemoveWithReason
+	cache, err := bigcache.NewBigCache(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Set("PK10001", []byte(`{"ID":"PK10001","Name":"Chespin"}`))
+	cache.Delete("PK10001")
+
+	select {
+	case event := <-received:
+		if event.Key != "PK10001" || event.Reason != Deleted || event.Pokemon.Name != "Chespin" {
+			t.Errorf("unexpected event: %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("listener was not called for deleted key")
+	}
+
+	cache.Close()
+	recorder.Close()
+	//Removals after close are counted but not dispatched
+	recorder.OnRemoveWithReason("PK10002", nil, bigcache.Deleted)
+	if recorder.Snapshot()["Deleted"] != 2 {
+		t.Errorf("unexpected deleted count: got %v want 2", recorder.Snapshot()["Deleted"])
+	}
+}
+
+func TestPanickingListener(t *testing.T) {
+	recorder := NewRecorder(nil, nil)
+	received := make(chan Event, 1)
+	recorder.Subscribe(func(event Event) { panic("listener failure") })
+	recorder.Subscribe(func(event Event) { received <- event })
+
+	recorder.OnRemoveWithReason("PK10001", nil, bigcache.Expired)
+	select {
+	case <-received:
+	case <-time.After(time.Second):
+		t.Fatal("panicking listener stopped later listeners")
+	}
+	recorder.Close()
+}
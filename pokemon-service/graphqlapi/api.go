@@ -0,0 +1,128 @@
+package graphqlapi
+
+import (
+	"context"
+	"fmt"
+	auth "pokemon-service/auth"
+	history "pokemon-service/history"
+	schema "pokemon-service/schema"
+	store "pokemon-service/store"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+type requestIdKey struct{}
+
+// Attaches the request ID that mutations publish their events with
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, requestId)
+}
+
+// Request ID attached with WithRequestId, empty when there is none
+func RequestId(ctx context.Context) string {
+	requestId, _ := ctx.Value(requestIdKey{}).(string)
+	return requestId
+}
+
+// Request ID and actor the changes made with ctx are recorded under
+func originOf(ctx context.Context) schema.Origin {
+	return schema.Origin{RequestId: RequestId(ctx), Actor: auth.Actor(ctx)}
+}
+
+// API executes GraphQL requests against the store shared with the REST handlers and the gRPC server
+type API struct {
+	schema graphql.Schema
+	limits Limits
+}
+
+// Creates the API, pokemons are read as they were at an earlier time from revisions
+func New(pokemons *store.Store, revisions *history.Revisions, limits Limits) (*API, error) {
+	limits = limits.withDefaults()
+	graphqlSchema, err := newSchema(pokemons, revisions, limits)
+	if err != nil {
+		return nil, err
+	}
+	return &API{schema: graphqlSchema, limits: limits}, nil
+}
+
+// Runs request unless it fails to parse, exceeds the limits or does not validate against the schema.
+// executed tells whether resolvers ran, so callers can answer rejected requests with a client error.
+// Mutations are refused when readOnly is set, as for GET requests.
+func (api *API) Execute(ctx context.Context, request schema.GraphQLRequest, readOnly bool) (result *graphql.Result, executed bool) {
+	if len(request.Query) <= 0 {
+		return rejected(fmt.Errorf("Query is expected")), false
+	}
+	if len(request.Query) > api.limits.MaxQueryLength {
+		return rejected(fmt.Errorf("Query exceeds the limit of %d bytes", api.limits.MaxQueryLength)), false
+	}
+	document, err := parser.Parse(parser.ParseParams{Source: request.Query})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}, false
+	}
+	validation := graphql.ValidateDocument(&api.schema, document, nil)
+	if !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}, false
+	}
+
+	operation, err := selectOperation(document, request.OperationName)
+	if err != nil {
+		return rejected(err), false
+	}
+	if readOnly && operation.Operation == ast.OperationTypeMutation {
+		return rejected(fmt.Errorf("Mutations are only accepted with POST")), false
+	}
+	analyzer := &analyzer{limits: api.limits, fragments: fragments(document), variables: request.Variables}
+	if err := analyzer.check(operation); err != nil {
+		return rejected(err), false
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        api.schema,
+		AST:           document,
+		OperationName: request.OperationName,
+		Args:          request.Variables,
+		Context:       ctx,
+	}), true
+}
+
+// Operation the request runs, the named one or the only one in the document
+func selectOperation(document *ast.Document, name string) (*ast.OperationDefinition, error) {
+	var selected *ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if len(name) > 0 {
+			if operation.Name != nil && operation.Name.Value == name {
+				return operation, nil
+			}
+			continue
+		}
+		if selected != nil {
+			return nil, fmt.Errorf("Must provide operation name if query contains multiple operations")
+		}
+		selected = operation
+	}
+	if selected == nil {
+		return nil, fmt.Errorf("Unknown operation named %q", name)
+	}
+	return selected, nil
+}
+
+func fragments(document *ast.Document) map[string]*ast.FragmentDefinition {
+	fragments := map[string]*ast.FragmentDefinition{}
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			fragments[fragment.Name.Value] = fragment
+		}
+	}
+	return fragments
+}
+
+func rejected(err error) *graphql.Result {
+	return &graphql.Result{Errors: []gqlerrors.FormattedError{gqlerrors.NewFormattedError(err.Error())}}
+}
@@ -0,0 +1,222 @@
+package graphqlapi
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	events "pokemon-service/events"
+	history "pokemon-service/history"
+	idgen "pokemon-service/idgen"
+	"pokemon-service/schema"
+	store "pokemon-service/store"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/allegro/bigcache/v3"
+)
+
+func TestQueries(t *testing.T) {
+	api, _ := loadAPI(t, Limits{})
+
+	inputs := []struct {
+		testName  string
+		query     string
+		variables map[string]interface{}
+		expected  string
+	}{
+		{testName: "TestPokemonById", query: `{ pokemon(id: "PK10001") { name type } }`, expected: `{"pokemon":{"name":"Picachoo1","type":"TT"}}`},
+		{testName: "TestPokemonByName", query: `{ pokemon(name: "Picachoo2") { id } }`, expected: `{"pokemon":{"id":"PK10002"}}`},
+		{testName: "TestPokemonByNormalizedName", query: `{ pokemon(name: " PICACHOO2 ") { id } }`, expected: `{"pokemon":{"id":"PK10002"}}`},
+		{testName: "TestPokemonIdIsNotName", query: `{ pokemon(id: "Picachoo1") { id } }`, expected: `{"pokemon":null}`},
+		{testName: "TestPokemonNotFound", query: `{ pokemon(id: "PK1000908") { id } }`, expected: `{"pokemon":null}`},
+		{testName: "TestPokemonsByIds", query: `query($ids: [String!]!) { pokemonsByIds(ids: $ids) { id } }`, variables: map[string]interface{}{"ids": []interface{}{"PK10002", "PK1000908", "PK10001"}}, expected: `{"pokemonsByIds":[{"id":"PK10002"},null,{"id":"PK10001"}]}`},
+		{testName: "TestPokemonsFilter", query: `{ pokemons(filter: {type: "pp"}) { id } }`, expected: `{"pokemons":[{"id":"PK10002"}]}`},
+		{testName: "TestPokemonsNameContains", query: `{ pokemons(filter: {nameContains: "CHOO"}, limit: 1) { id } }`, expected: `{"pokemons":[{"id":"PK10001"}]}`},
+	}
+
+	for _, item := range inputs {
+		result, executed := api.Execute(context.Background(), schema.GraphQLRequest{Query: item.query, Variables: item.variables}, true)
+		if !executed || result.HasErrors() {
+			t.Errorf("%v: unexpected errors: %v", item.testName, result.Errors)
+			continue
+		}
+		if data, _ := json.Marshal(result.Data); string(data) != item.expected {
+			t.Errorf("%v: unexpected data: got %s want %v", item.testName, data, item.expected)
+		}
+	}
+}
+
+func TestMutations(t *testing.T) {
+	api, published := loadAPI(t, Limits{})
+	ctx := WithRequestId(context.Background(), "req-1")
+
+	mutations := []string{
+		`mutation { addPokemon(pokemon: {id: "PK10003", name: "Picachoo3", type: "EE"}) { id } }`,
+		`mutation { updatePokemon(pokemon: {id: "PK10003", name: "Raichoo3"}) { name } }`,
+		`mutation { deletePokemon(id: "PK10003") { name } }`,
+	}
+	for _, mutation := range mutations {
+		result, executed := api.Execute(ctx, schema.GraphQLRequest{Query: mutation}, false)
+		if !executed || result.HasErrors() {
+			t.Fatalf("unexpected errors for %v: %v", mutation, result.Errors)
+		}
+	}
+
+	result, _ := api.Execute(ctx, schema.GraphQLRequest{Query: `mutation { updatePokemon(pokemon: {id: "PK10003", name: "Raichoo3"}) { name } }`}, false)
+	if !result.HasErrors() {
+		t.Errorf("expected an error updating a deleted pokemon")
+	}
+
+	expected := []string{schema.EventCreated, schema.EventUpdated, schema.EventDeleted}
+	if len(*published) != len(expected) {
+		t.Fatalf("unexpected events: %+v", *published)
+	}
+	for i, eventType := range expected {
+		if (*published)[i].Type != eventType || (*published)[i].RequestId != "req-1" {
+			t.Errorf("unexpected event %v: %+v", i, (*published)[i])
+		}
+	}
+}
+
+func TestAddPokemonExisting(t *testing.T) {
+	api, published := loadAPI(t, Limits{})
+	ctx := context.Background()
+
+	inputs := []struct {
+		testName string
+		mutation string
+		message  string
+	}{
+		{testName: "TestAddTakenId", mutation: `mutation { addPokemon(pokemon: {id: "PK10001", name: "Raichoo1"}) { id } }`, message: "taken by pokemon PK10001"},
+		{testName: "TestAddTakenName", mutation: `mutation { addPokemon(pokemon: {id: "PK10003", name: "Picachoo2"}) { id } }`, message: "taken by pokemon PK10002"},
+	}
+	for _, item := range inputs {
+		result, _ := api.Execute(ctx, schema.GraphQLRequest{Query: item.mutation}, false)
+		if len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Message, item.message) {
+			t.Errorf("%v: unexpected errors: got %v want %v", item.testName, result.Errors, item.message)
+		}
+	}
+	result, _ := api.Execute(ctx, schema.GraphQLRequest{Query: `{ pokemon(id: "PK10001") { name } }`}, true)
+	if data, _ := json.Marshal(result.Data); string(data) != `{"pokemon":{"name":"Picachoo1"}}` {
+		t.Errorf("failed add changed the stored pokemon: %s", data)
+	}
+	if len(*published) != 0 {
+		t.Errorf("failed adds published events: %+v", *published)
+	}
+
+	result, _ = api.Execute(ctx, schema.GraphQLRequest{Query: `mutation { addPokemon(pokemon: {name: "Picachoo3"}) { id } }`}, false)
+	if data, _ := json.Marshal(result.Data); result.HasErrors() || string(data) != `{"addPokemon":{"id":"PK10003"}}` {
+		t.Errorf("unexpected result adding without an id: %s %v", data, result.Errors)
+	}
+	result, _ = api.Execute(ctx, schema.GraphQLRequest{Query: `mutation { addPokemon(pokemon: {name: "Picachoo4"}, upsert: true) { id } }`}, false)
+	if len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Message, store.ErrMissingId.Error()) {
+		t.Errorf("unexpected errors upserting without an id: %v", result.Errors)
+	}
+
+	//Only upsert replaces the stored record
+	result, _ = api.Execute(ctx, schema.GraphQLRequest{Query: `mutation { addPokemon(pokemon: {id: "PK10001", name: "Raichoo1"}, upsert: true) { name } }`}, false)
+	if data, _ := json.Marshal(result.Data); result.HasErrors() || string(data) != `{"addPokemon":{"name":"Raichoo1"}}` {
+		t.Errorf("unexpected upsert result: %s %v", data, result.Errors)
+	}
+}
+
+func TestPokemonAsOf(t *testing.T) {
+	api, _ := loadAPI(t, Limits{})
+	ctx := context.Background()
+	beforeRename := time.Now().Format(time.RFC3339Nano)
+	time.Sleep(2 * time.Millisecond)
+	if result, _ := api.Execute(ctx, schema.GraphQLRequest{Query: `mutation { updatePokemon(pokemon: {id: "PK10001", name: "Raichoo1"}) { name } }`}, false); result.HasErrors() {
+		t.Fatal(result.Errors)
+	}
+
+	inputs := []struct {
+		testName string
+		query    string
+		expected string
+		message  string
+	}{
+		{testName: "TestPokemonAsOfBeforeRename", query: `query($asOf: String) { pokemon(id: "PK10001", asOf: $asOf) { name } }`, expected: `{"pokemon":{"name":"Picachoo1"}}`},
+		{testName: "TestPokemonAsOfUnchanged", query: `query($asOf: String) { pokemon(id: "PK10002", asOf: $asOf) { name } }`, expected: `{"pokemon":{"name":"Picachoo2"}}`},
+		{testName: "TestPokemonAsOfUnknown", query: `query($asOf: String) { pokemon(id: "PK1000908", asOf: $asOf) { name } }`, expected: `{"pokemon":null}`},
+		{testName: "TestPokemonAsOfByName", query: `query($asOf: String) { pokemon(name: "Raichoo1", asOf: $asOf) { name } }`, message: "asOf is only supported with id"},
+		{testName: "TestPokemonAsOfInvalid", query: `{ pokemon(id: "PK10001", asOf: "yesterday") { name } }`, message: "Invalid asOf"},
+	}
+	for _, item := range inputs {
+		result, _ := api.Execute(ctx, schema.GraphQLRequest{Query: item.query, Variables: map[string]interface{}{"asOf": beforeRename}}, true)
+		if len(item.message) > 0 {
+			if len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Message, item.message) {
+				t.Errorf("%v: unexpected errors: got %v want %v", item.testName, result.Errors, item.message)
+			}
+			continue
+		}
+		if result.HasErrors() {
+			t.Errorf("%v: unexpected errors: %v", item.testName, result.Errors)
+			continue
+		}
+		if data, _ := json.Marshal(result.Data); string(data) != item.expected {
+			t.Errorf("%v: unexpected data: got %s want %v", item.testName, data, item.expected)
+		}
+	}
+}
+
+func TestRejectedRequests(t *testing.T) {
+	api, _ := loadAPI(t, Limits{MaxDepth: 3, MaxComplexity: 30, MaxListSize: 10})
+
+	inputs := []struct {
+		testName string
+		request  schema.GraphQLRequest
+		readOnly bool
+		message  string
+	}{
+		{testName: "TestEmptyQuery", request: schema.GraphQLRequest{}, message: "Query is expected"},
+		{testName: "TestSyntaxError", request: schema.GraphQLRequest{Query: `{ pokemon(id: "PK10001") { id }`}, message: "Syntax Error"},
+		{testName: "TestUnknownField", request: schema.GraphQLRequest{Query: `{ pokemon(id: "PK10001") { color } }`}, message: "Cannot query field"},
+		{testName: "TestMutationReadOnly", request: schema.GraphQLRequest{Query: `mutation { deletePokemon(id: "PK10001") { id } }`}, readOnly: true, message: "only accepted with POST"},
+		{testName: "TestDepthLimit", request: schema.GraphQLRequest{Query: `{ __schema { types { fields { type { name } } } } }`}, message: "Query depth 5 exceeds"},
+		{testName: "TestComplexityLimit", request: schema.GraphQLRequest{Query: `{ pokemons(limit: 10) { id name type } }`}, message: "Query complexity 31 exceeds"},
+		{testName: "TestComplexityVariables", request: schema.GraphQLRequest{Query: `query($n: Int) { pokemons(limit: $n) { ...f } } fragment f on Pokemon { id name type }`, Variables: map[string]interface{}{"n": float64(10)}}, message: "Query complexity 31 exceeds"},
+	}
+
+	for _, item := range inputs {
+		result, executed := api.Execute(context.Background(), item.request, item.readOnly)
+		if executed {
+			t.Errorf("%v: request was executed", item.testName)
+			continue
+		}
+		if len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Message, item.message) {
+			t.Errorf("%v: unexpected errors: got %v want %v", item.testName, result.Errors, item.message)
+		}
+	}
+
+	//Within the budget, the resolver still bounds the list size
+	result, executed := api.Execute(context.Background(), schema.GraphQLRequest{Query: `{ pokemons(limit: 11) { id } }`}, true)
+	if !executed || !result.HasErrors() {
+		t.Errorf("expected limit above MaxListSize to fail: %v", result.Errors)
+	}
+}
+
+// API over a store holding two pokemons, events published by writes are collected in the returned slice.
+// Changes made through the API are kept in a history for reads at an earlier time, created pokemons without
+// an ID get the next free one from PK10001 on.
+func loadAPI(t *testing.T, limits Limits) (*API, *[]schema.PokemonEvent) {
+	cache, _ := bigcache.NewBigCache(bigcache.DefaultConfig(24 * time.Hour))
+	pokemons := store.New(cache, nil, nil, nil)
+	pokemons.Add(schema.Pokemon{Id: "PK10001", Name: "Picachoo1", Type: "TT"}, schema.Origin{})
+	pokemons.Add(schema.Pokemon{Id: "PK10002", Name: "Picachoo2", Type: "PP"}, schema.Origin{})
+
+	bus := events.NewBus()
+	published := &[]schema.PokemonEvent{}
+	bus.Subscribe(func(event schema.PokemonEvent) { *published = append(*published, event) })
+	revisions := history.New(history.Config{}, nil)
+	bus.Subscribe(revisions.Handle)
+	ids, err := idgen.NewSequence(idgen.Config{StateFile: filepath.Join(t.TempDir(), "id.sequence"), Start: 10001})
+	if err != nil {
+		t.Fatal(err)
+	}
+	api, err := New(store.New(cache, bus, nil, ids), revisions, limits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return api, published
+}
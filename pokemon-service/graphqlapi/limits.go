@@ -0,0 +1,137 @@
+package graphqlapi
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Bounds what a single request may ask for, zero values fall back to defaults
+type Limits struct {
+	// Deepest field nesting, the standard introspection query needs 12
+	MaxDepth int
+	// Cost budget, every field costs 1 and the selection of a list field is paid once per requested item
+	MaxComplexity int
+	// Largest query document accepted, in bytes
+	MaxQueryLength int
+	// Items returned by pokemons when no limit is given
+	DefaultListSize int
+	// Most items pokemons and pokemonsByIds return at once
+	MaxListSize int
+}
+
+func (limits Limits) withDefaults() Limits {
+	if limits.MaxDepth <= 0 {
+		limits.MaxDepth = 12
+	}
+	if limits.MaxComplexity <= 0 {
+		limits.MaxComplexity = 1000
+	}
+	if limits.MaxQueryLength <= 0 {
+		limits.MaxQueryLength = 8192
+	}
+	if limits.MaxListSize <= 0 {
+		limits.MaxListSize = 100
+	}
+	if limits.DefaultListSize <= 0 {
+		limits.DefaultListSize = 50
+	}
+	if limits.DefaultListSize > limits.MaxListSize {
+		limits.DefaultListSize = limits.MaxListSize
+	}
+	return limits
+}
+
+// Walks an operation before it is executed and measures its depth and cost
+type analyzer struct {
+	limits    Limits
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+func (analyzer *analyzer) check(operation *ast.OperationDefinition) error {
+	depth, complexity := analyzer.measure(operation.GetSelectionSet())
+	if depth > analyzer.limits.MaxDepth {
+		return fmt.Errorf("Query depth %d exceeds the limit of %d", depth, analyzer.limits.MaxDepth)
+	}
+	if complexity > analyzer.limits.MaxComplexity {
+		return fmt.Errorf("Query complexity %d exceeds the limit of %d", complexity, analyzer.limits.MaxComplexity)
+	}
+	return nil
+}
+
+// Depth and cost of a selection set, fragments count as if their fields were written inline
+func (analyzer *analyzer) measure(selectionSet *ast.SelectionSet) (int, int) {
+	if selectionSet == nil {
+		return 0, 0
+	}
+	depth, complexity := 0, 0
+	for _, selection := range selectionSet.Selections {
+		var childDepth, childComplexity int
+		switch node := selection.(type) {
+		case *ast.Field:
+			childDepth, childComplexity = analyzer.measure(node.SelectionSet)
+			childDepth++
+			childComplexity = 1 + analyzer.multiplier(node)*childComplexity
+		case *ast.InlineFragment:
+			childDepth, childComplexity = analyzer.measure(node.SelectionSet)
+		case *ast.FragmentSpread:
+			//Documents are validated first, so fragments exist and do not form cycles
+			if fragment, ok := analyzer.fragments[node.Name.Value]; ok {
+				childDepth, childComplexity = analyzer.measure(fragment.SelectionSet)
+			}
+		}
+		if childDepth > depth {
+			depth = childDepth
+		}
+		complexity += childComplexity
+	}
+	return depth, complexity
+}
+
+// How many items a field may return, so its selection is paid once per item
+func (analyzer *analyzer) multiplier(field *ast.Field) int {
+	switch field.Name.Value {
+	case "pokemons":
+		if limit, ok := analyzer.argument(field, "limit").(int); ok && limit >= 0 {
+			return limit
+		}
+		return analyzer.limits.DefaultListSize
+	case "pokemonsByIds":
+		if ids, ok := analyzer.argument(field, "ids").([]interface{}); ok {
+			return len(ids)
+		}
+		return analyzer.limits.MaxListSize
+	}
+	return 1
+}
+
+// Literal or variable value of a field argument, nil when absent or not an int or list
+func (analyzer *analyzer) argument(field *ast.Field, name string) interface{} {
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != name {
+			continue
+		}
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			limit, err := strconv.Atoi(value.Value)
+			if err != nil {
+				return nil
+			}
+			return limit
+		case *ast.ListValue:
+			return make([]interface{}, len(value.Values))
+		case *ast.Variable:
+			switch variable := analyzer.variables[value.Name.Value].(type) {
+			case float64:
+				return int(variable)
+			case int:
+				return variable
+			case []interface{}:
+				return variable
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,323 @@
+package graphqlapi
+
+import (
+	"errors"
+	"fmt"
+	history "pokemon-service/history"
+	schema "pokemon-service/schema"
+	store "pokemon-service/store"
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Builds the schema, every resolver reads and writes through pokemons
+func newSchema(pokemons *store.Store, revisions *history.Revisions, limits Limits) (graphql.Schema, error) {
+	evolutionType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Evolution",
+		Description: "Link to the pokemon an evolution starts from, mirrors schema.Evolution",
+		Fields: graphql.Fields{
+			"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: evolutionField(func(evolution schema.Evolution) interface{} { return evolution.Id })},
+			"level":     &graphql.Field{Type: graphql.Int, Resolve: evolutionField(func(evolution schema.Evolution) interface{} { return evolution.Level })},
+			"condition": &graphql.Field{Type: graphql.String, Resolve: evolutionField(func(evolution schema.Evolution) interface{} { return evolution.Condition })},
+		},
+	})
+
+	pokemonType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Pokemon",
+		Description: "Pokemon record, mirrors schema.Pokemon",
+		Fields: graphql.Fields{
+			"id":        pokemonField(graphql.NewNonNull(graphql.String), func(pokemon schema.Pokemon) string { return pokemon.Id }),
+			"name":      pokemonField(graphql.NewNonNull(graphql.String), func(pokemon schema.Pokemon) string { return pokemon.Name }),
+			"type":      pokemonField(graphql.String, func(pokemon schema.Pokemon) string { return pokemon.Type }),
+			"height":    pokemonField(graphql.String, func(pokemon schema.Pokemon) string { return pokemon.Height }),
+			"weight":    pokemonField(graphql.String, func(pokemon schema.Pokemon) string { return pokemon.Weight }),
+			"abilities": pokemonField(graphql.String, func(pokemon schema.Pokemon) string { return pokemon.Abilities }),
+			"evolvesFrom": &graphql.Field{
+				Type:        evolutionType,
+				Description: "Earlier stage of the evolution chain, null for the first stage",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					pokemon, _ := p.Source.(schema.Pokemon)
+					if pokemon.EvolvesFrom == nil {
+						return nil, nil
+					}
+					return *pokemon.EvolvesFrom, nil
+				},
+			},
+		},
+	})
+
+	filterType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "PokemonFilter",
+		Description: "Criteria a listed pokemon has to match, all of them compare case insensitively",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"type":         &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"abilities":    &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"nameContains": &graphql.InputObjectFieldConfig{Type: graphql.String},
+		},
+	})
+
+	evolutionInputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "EvolutionInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"id":        &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"level":     &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"condition": &graphql.InputObjectFieldConfig{Type: graphql.String},
+		},
+	})
+
+	inputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "PokemonInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"id":          &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "Expected by updates, addPokemon allocates the next free one when it is left out"},
+			"name":        &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"type":        &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"height":      &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"weight":      &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"abilities":   &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"evolvesFrom": &graphql.InputObjectFieldConfig{Type: evolutionInputType},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"pokemon": &graphql.Field{
+				Type:        pokemonType,
+				Description: "Single pokemon by id or by name regardless of case, accents and whitespace, null when it does not exist",
+				Args: graphql.FieldConfigArgument{
+					"id":   &graphql.ArgumentConfig{Type: graphql.String},
+					"name": &graphql.ArgumentConfig{Type: graphql.String},
+					"asOf": &graphql.ArgumentConfig{Type: graphql.String, Description: "RFC 3339 timestamp to read the pokemon by id as it was at from its history"},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, _ := p.Args["id"].(string)
+					name, _ := p.Args["name"].(string)
+					if (len(id) > 0) == (len(name) > 0) {
+						return nil, errors.New("Either id or name is expected")
+					}
+					if asOf, _ := p.Args["asOf"].(string); len(asOf) > 0 {
+						//Names change between revisions, so past records are only read by ID
+						if len(id) <= 0 {
+							return nil, errors.New("asOf is only supported with id")
+						}
+						return lookupAsOf(pokemons, revisions, id, asOf)
+					}
+					if len(name) > 0 {
+						return lookup(pokemons, name, true)
+					}
+					return lookup(pokemons, id, false)
+				},
+			},
+			"pokemonsByIds": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(pokemonType)),
+				Description: "Several pokemons in one round trip, in the order of ids with null for unknown ones",
+				Args: graphql.FieldConfigArgument{
+					"ids": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					ids, _ := p.Args["ids"].([]interface{})
+					if len(ids) > limits.MaxListSize {
+						return nil, fmt.Errorf("At most %d ids can be requested at once", limits.MaxListSize)
+					}
+					result := make([]interface{}, 0, len(ids))
+					for _, id := range ids {
+						pokemon, err := lookup(pokemons, id.(string), false)
+						if err != nil {
+							return nil, err
+						}
+						result = append(result, pokemon)
+					}
+					return result, nil
+				},
+			},
+			"pokemons": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(pokemonType))),
+				Description: "Pokemons ordered by id, optionally filtered",
+				Args: graphql.FieldConfigArgument{
+					"filter": &graphql.ArgumentConfig{Type: filterType},
+					"limit":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: limits.DefaultListSize},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					limit, _ := p.Args["limit"].(int)
+					if limit < 0 || limit > limits.MaxListSize {
+						return nil, fmt.Errorf("limit must be between 0 and %d", limits.MaxListSize)
+					}
+					filter, _ := p.Args["filter"].(map[string]interface{})
+					matches := pokemons.List(matcher(filter))
+					if len(matches) > limit {
+						matches = matches[:limit]
+					}
+					return matches, nil
+				},
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"addPokemon": &graphql.Field{
+				Type:        pokemonType,
+				Description: "Adds a pokemon, failing when its id or name is taken. An existing one with the same id is only replaced with upsert: true",
+				Args: graphql.FieldConfigArgument{
+					"pokemon": &graphql.ArgumentConfig{Type: graphql.NewNonNull(inputType)},
+					"upsert":  &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					pokemon := fromInput(p.Args["pokemon"])
+					if upsert, _ := p.Args["upsert"].(bool); upsert {
+						//Without an ID there is nothing to replace
+						if len(pokemon.Id) <= 0 {
+							return nil, mutationError("Unable to add data to cache", store.ErrMissingId)
+						}
+						if _, err := pokemons.Add(pokemon, originOf(p.Context)); err != nil {
+							return nil, mutationError(fmt.Sprintf("Unable to add data to cache for Id:%v", pokemon.Id), err)
+						}
+						return pokemon, nil
+					}
+					created, err := pokemons.Create(pokemon, originOf(p.Context))
+					if err != nil {
+						return nil, mutationError(fmt.Sprintf("Unable to add data to cache for Id:%v", pokemon.Id), err)
+					}
+					return created, nil
+				},
+			},
+			"updatePokemon": &graphql.Field{
+				Type:        pokemonType,
+				Description: "Replaces an existing pokemon",
+				Args:        graphql.FieldConfigArgument{"pokemon": &graphql.ArgumentConfig{Type: graphql.NewNonNull(inputType)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					pokemon := fromInput(p.Args["pokemon"])
+					if err := pokemons.Update(pokemon, originOf(p.Context)); err != nil {
+						return nil, mutationError(fmt.Sprintf("Unable to get data from cache for Id to update:%v", pokemon.Id), err)
+					}
+					return pokemon, nil
+				},
+			},
+			"deletePokemon": &graphql.Field{
+				Type:        pokemonType,
+				Description: "Deletes a pokemon and returns the removed record",
+				Args:        graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, _ := p.Args["id"].(string)
+					pokemon, err := pokemons.Delete(id, originOf(p.Context))
+					if err != nil {
+						return nil, mutationError(fmt.Sprintf("Unable to get data from cache for Id to delete:%v", id), err)
+					}
+					return pokemon, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+func pokemonField(fieldType graphql.Output, get func(schema.Pokemon) string) *graphql.Field {
+	return &graphql.Field{
+		Type: fieldType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			pokemon, _ := p.Source.(schema.Pokemon)
+			return get(pokemon), nil
+		},
+	}
+}
+
+func evolutionField(get func(schema.Evolution) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		evolution, _ := p.Source.(schema.Evolution)
+		return get(evolution), nil
+	}
+}
+
+// Error of a failed mutation, taken or missing keys, broken evolution chains and a full catalog are told apart so the
+// caller knows what to fix. A taken key names the pokemon holding it.
+func mutationError(message string, err error) error {
+	if errors.Is(err, store.ErrExists) || errors.Is(err, store.ErrMissingId) || errors.Is(err, store.ErrInvalidEvolution) || errors.Is(err, store.ErrHasEvolutions) || errors.Is(err, store.ErrQuotaExceeded) {
+		return fmt.Errorf("%v: %v", message, err)
+	}
+	return errors.New(message)
+}
+
+// Pokemon with the ID key, or carrying the name key when byName, nil without error when there is none.
+// Names are matched regardless of case, accents and whitespace.
+func lookup(pokemons *store.Store, key string, byName bool) (interface{}, error) {
+	var pokemon schema.Pokemon
+	var err error
+	if byName {
+		pokemon, err = pokemons.GetByName(key)
+	} else if pokemon, err = pokemons.Get(key); err == nil && pokemon.Id != key {
+		//Records are stored under their name as well, a name is not an ID
+		err = store.ErrNotFound
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get data from cache for:%v", key)
+	}
+	return pokemon, nil
+}
+
+// Pokemon with the given ID as it was at rawAsOf, nil when it did not exist then
+func lookupAsOf(pokemons *store.Store, revisions *history.Revisions, id string, rawAsOf string) (interface{}, error) {
+	if revisions == nil {
+		return nil, errors.New("No history is kept, asOf is not supported")
+	}
+	asOf, err := time.Parse(time.RFC3339Nano, rawAsOf)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid asOf:%v, an RFC 3339 timestamp is expected", rawAsOf)
+	}
+	pokemon, err := revisions.Read(id, asOf, pokemons.Get)
+	if errors.Is(err, store.ErrNotFound) || errors.Is(err, history.ErrNoRecord) {
+		return nil, nil
+	}
+	if errors.Is(err, history.ErrExpired) {
+		return nil, fmt.Errorf("Unable to get data for:%v as of %v: %v", id, rawAsOf, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get data from cache for:%v", id)
+	}
+	return pokemon, nil
+}
+
+func matcher(filter map[string]interface{}) func(schema.Pokemon) bool {
+	pokemonType, _ := filter["type"].(string)
+	abilities, _ := filter["abilities"].(string)
+	nameContains, _ := filter["nameContains"].(string)
+	nameContains = strings.ToLower(nameContains)
+	return func(pokemon schema.Pokemon) bool {
+		if len(pokemonType) > 0 && !strings.EqualFold(pokemon.Type, pokemonType) {
+			return false
+		}
+		if len(abilities) > 0 && !strings.EqualFold(pokemon.Abilities, abilities) {
+			return false
+		}
+		return strings.Contains(strings.ToLower(pokemon.Name), nameContains)
+	}
+}
+
+func fromInput(input interface{}) schema.Pokemon {
+	fields, _ := input.(map[string]interface{})
+	value := func(name string) string {
+		text, _ := fields[name].(string)
+		return text
+	}
+	pokemon := schema.Pokemon{
+		Id:        value("id"),
+		Name:      value("name"),
+		Type:      value("type"),
+		Height:    value("height"),
+		Weight:    value("weight"),
+		Abilities: value("abilities"),
+	}
+	if evolution, ok := fields["evolvesFrom"].(map[string]interface{}); ok {
+		id, _ := evolution["id"].(string)
+		level, _ := evolution["level"].(int)
+		condition, _ := evolution["condition"].(string)
+		pokemon.EvolvesFrom = &schema.Evolution{Id: id, Level: level, Condition: condition}
+	}
+	return pokemon
+}
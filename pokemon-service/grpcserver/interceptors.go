@@ -0,0 +1,130 @@
+package grpcserver
+
+import (
+	"context"
+	auth "pokemon-service/auth"
+	schema "pokemon-service/schema"
+	"runtime/debug"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// gRPC counterpart of the LoggingRequest and LoggingResponse middlewares: logs every call with its
+// request, then its response, status code and latency, and turns panics into Internal errors
+func LoggingUnary(l schema.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		start := time.Now()
+		l.InfoLogger.Println("Received gRPC request with:", info.FullMethod)
+		l.InfoLogger.Println("Request:", req)
+
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				l.InfoLogger.Println(
+					"err", recovered,
+					"trace", string(debug.Stack()),
+				)
+				err = status.Error(codes.Internal, "Internal server error")
+			}
+			l.InfoLogger.Println(
+				status.Code(err),
+				info.FullMethod,
+				"Latency:", time.Since(start).String(),
+				"Response:", resp,
+				"Error:", err,
+			)
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// Streaming variant of LoggingUnary, messages are not logged one by one
+func LoggingStream(l schema.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		start := time.Now()
+		l.InfoLogger.Println("Received gRPC stream with:", info.FullMethod)
+
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				l.InfoLogger.Println(
+					"err", recovered,
+					"trace", string(debug.Stack()),
+				)
+				err = status.Error(codes.Internal, "Internal server error")
+			}
+			l.InfoLogger.Println(
+				status.Code(err),
+				info.FullMethod,
+				"Duration:", time.Since(start).String(),
+				"Error:", err,
+			)
+		}()
+		return handler(srv, stream)
+	}
+}
+
+const (
+	apiKeyMetadata = "x-api-key"
+	tenantMetadata = "x-tenant"
+)
+
+// gRPC counterpart of the Identify middleware: attaches the actor of the x-api-key metadata to the context,
+// calls without a key stay anonymous and unknown keys are rejected with Unauthenticated. The tenant of the key
+// is attached too, naming another one in x-tenant metadata is rejected with PermissionDenied.
+func IdentifyUnary(keys *auth.Keys) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := identify(ctx, keys)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Streaming variant of IdentifyUnary
+func IdentifyStream(keys *auth.Keys) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := identify(stream.Context(), keys)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &identifiedStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+func identify(ctx context.Context, keys *auth.Keys) (context.Context, error) {
+	identity := auth.Identity{}
+	if provided := metadata.ValueFromIncomingContext(ctx, apiKeyMetadata); len(provided) > 0 && len(provided[0]) > 0 {
+		known, ok := keys.Lookup(provided[0])
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "Valid "+apiKeyMetadata+" metadata is expected")
+		}
+		identity = known
+	}
+	requested := ""
+	if provided := metadata.ValueFromIncomingContext(ctx, tenantMetadata); len(provided) > 0 {
+		requested = provided[0]
+	}
+	tenant, err := identity.Select(requested)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, "Rejected "+tenantMetadata+": "+err.Error())
+	}
+	ctx = auth.WithTenant(ctx, tenant)
+	if len(identity.Actor) > 0 {
+		ctx = auth.WithActor(ctx, identity.Actor)
+	}
+	return ctx, nil
+}
+
+// Server stream carrying the context with the actor attached
+type identifiedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *identifiedStream) Context() context.Context {
+	return stream.ctx
+}
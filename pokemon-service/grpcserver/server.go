@@ -0,0 +1,309 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	auth "pokemon-service/auth"
+	events "pokemon-service/events"
+	history "pokemon-service/history"
+	pb "pokemon-service/pokemonpb"
+	schema "pokemon-service/schema"
+	store "pokemon-service/store"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements the gRPC PokemonService on top of the store shared with the REST handlers
+type Server struct {
+	pb.UnimplementedPokemonServiceServer
+	Store  *store.Store
+	Stream *events.Stream
+	// Revisions GetByID reads pokemons as they were at an earlier time from, nil refuses such reads
+	History *history.Revisions
+	// API keys callers identify themselves with through x-api-key metadata, calls without one are anonymous
+	Keys *auth.Keys
+	// Servers of every tenant by name, calls are served by the one of the tenant they were identified for.
+	// Nil serves every call of the default tenant from this server.
+	Tenants map[string]*Server
+}
+
+// Creates a grpc.Server with logging and recovery interceptors and the pokemon service registered
+func New(server *Server, logger schema.Logger) *grpc.Server {
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(LoggingUnary(logger), IdentifyUnary(server.Keys)),
+		grpc.ChainStreamInterceptor(LoggingStream(logger), IdentifyStream(server.Keys)),
+	)
+	pb.RegisterPokemonServiceServer(grpcServer, server)
+	return grpcServer
+}
+
+func (server *Server) GetByID(ctx context.Context, req *pb.GetByIDRequest) (*pb.PokemonReply, error) {
+	scoped, err := server.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.GetId()) <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "Id is expected")
+	}
+	if len(req.GetAsOf()) > 0 {
+		return scoped.getAsOf(req.GetId(), req.GetAsOf())
+	}
+	pokemon, err := scoped.Store.Get(req.GetId())
+	if err != nil {
+		return nil, storeError(err, "Unable to get data from cache for Id:"+req.GetId())
+	}
+	return &pb.PokemonReply{Pokemon: toProto(pokemon), RequestId: uuid.New().String()}, nil
+}
+
+// Pokemon with the given ID as it was at rawAsOf
+func (server *Server) getAsOf(id string, rawAsOf string) (*pb.PokemonReply, error) {
+	if server.History == nil {
+		return nil, status.Error(codes.Unimplemented, "No history is kept, as_of is not supported")
+	}
+	asOf, err := time.Parse(time.RFC3339Nano, rawAsOf)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid as_of:"+rawAsOf+", an RFC 3339 timestamp is expected")
+	}
+	pokemon, err := server.History.Read(id, asOf, server.Store.Get)
+	if errors.Is(err, history.ErrNoRecord) || errors.Is(err, history.ErrExpired) {
+		return nil, status.Error(codes.NotFound, "Unable to get data for Id:"+id+" as of "+rawAsOf+": "+err.Error())
+	}
+	if err != nil {
+		return nil, storeError(err, "Unable to get data from cache for Id:"+id)
+	}
+	return &pb.PokemonReply{Pokemon: toProto(pokemon), RequestId: uuid.New().String()}, nil
+}
+
+func (server *Server) GetByName(ctx context.Context, req *pb.GetByNameRequest) (*pb.PokemonReply, error) {
+	scoped, err := server.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.GetName()) <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "Name is expected")
+	}
+	pokemon, err := scoped.Store.GetByName(req.GetName())
+	if err != nil {
+		return nil, storeError(err, "Unable to get data from cache for Name:"+req.GetName())
+	}
+	return &pb.PokemonReply{Pokemon: toProto(pokemon), RequestId: uuid.New().String()}, nil
+}
+
+func (server *Server) Add(ctx context.Context, req *pb.AddRequest) (*pb.PokemonReply, error) {
+	scoped, err := server.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetPokemon() == nil {
+		return nil, status.Error(codes.InvalidArgument, "Pokemon is expected")
+	}
+	requestId := requestIdOf(req.GetRequestId())
+	pokemon := fromProto(req.GetPokemon())
+	//Replacing a record is left to Update, a taken ID or name is reported as AlreadyExists
+	created, err := scoped.Store.Create(pokemon, originOf(ctx, requestId))
+	if err != nil {
+		return nil, storeError(err, "Unable to add data to cache")
+	}
+	return &pb.PokemonReply{Pokemon: toProto(created), RequestId: requestId}, nil
+}
+
+func (server *Server) Update(ctx context.Context, req *pb.UpdateRequest) (*pb.PokemonReply, error) {
+	scoped, err := server.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetPokemon() == nil || len(req.GetPokemon().GetId()) <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "Pokemon with Id is expected")
+	}
+	requestId := requestIdOf(req.GetRequestId())
+	pokemon := fromProto(req.GetPokemon())
+	if err := scoped.Store.Update(pokemon, originOf(ctx, requestId)); err != nil {
+		return nil, storeError(err, "Unable to get data from cache for Id to update:"+pokemon.Id)
+	}
+	return &pb.PokemonReply{Pokemon: toProto(pokemon), RequestId: requestId}, nil
+}
+
+func (server *Server) Delete(ctx context.Context, req *pb.DeleteRequest) (*pb.PokemonReply, error) {
+	scoped, err := server.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.GetId()) <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "Id is expected")
+	}
+	requestId := requestIdOf(req.GetRequestId())
+	pokemon, err := scoped.Store.Delete(req.GetId(), originOf(ctx, requestId))
+	if err != nil {
+		return nil, storeError(err, "Unable to get data from cache for Id to delete:"+req.GetId())
+	}
+	return &pb.PokemonReply{Pokemon: toProto(pokemon), RequestId: requestId}, nil
+}
+
+func (server *Server) List(ctx context.Context, req *pb.ListRequest) (*pb.ListReply, error) {
+	scoped, err := server.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var match func(schema.Pokemon) bool
+	if len(req.GetType()) > 0 {
+		match = func(pokemon schema.Pokemon) bool { return strings.EqualFold(pokemon.Type, req.GetType()) }
+	}
+	reply := &pb.ListReply{RequestId: uuid.New().String()}
+	for _, pokemon := range scoped.Store.List(match) {
+		reply.Pokemons = append(reply.Pokemons, toProto(pokemon))
+	}
+	return reply, nil
+}
+
+// Streams catalog changes from the same event log as the SSE endpoint
+func (server *Server) Watch(req *pb.WatchRequest, stream pb.PokemonService_WatchServer) error {
+	scoped, err := server.scope(stream.Context())
+	if err != nil {
+		return err
+	}
+	filter := events.Filter{}
+	if len(req.GetTypes()) > 0 {
+		filter.Types = map[string]bool{}
+		for _, eventType := range req.GetTypes() {
+			filter.Types[eventType] = true
+		}
+	}
+	if len(req.GetIds()) > 0 {
+		filter.PokemonIds = map[string]bool{}
+		for _, id := range req.GetIds() {
+			filter.PokemonIds[id] = true
+		}
+	}
+
+	subscription, backlog, complete := scoped.Stream.Subscribe(filter, req.GetLastSequence())
+	defer scoped.Stream.Unsubscribe(subscription)
+	if !complete {
+		return status.Error(codes.OutOfRange, "events after last_sequence are no longer retained")
+	}
+	for _, event := range backlog {
+		if err := stream.Send(eventToProto(event)); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case event, open := <-subscription.Events:
+			if !open {
+				if subscription.Overflowed() {
+					return status.Error(codes.ResourceExhausted, "client fell behind, watch again to resume")
+				}
+				return status.Error(codes.Unavailable, "server is shutting down")
+			}
+			if err := stream.Send(eventToProto(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Server of the tenant the call was identified for, NotFound for tenants that are not configured
+func (server *Server) scope(ctx context.Context) (*Server, error) {
+	tenant := auth.Tenant(ctx)
+	if server.Tenants == nil && tenant == auth.DefaultTenant {
+		return server, nil
+	}
+	scoped, ok := server.Tenants[tenant]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "Unknown tenant "+tenant)
+	}
+	return scoped, nil
+}
+
+// Maps store errors to gRPC status codes
+func storeError(err error, message string) error {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return status.Error(codes.NotFound, message)
+	case errors.Is(err, store.ErrMissingId):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, store.ErrExists):
+		return status.Error(codes.AlreadyExists, message+": "+err.Error())
+	case errors.Is(err, store.ErrInvalidEvolution):
+		return status.Error(codes.InvalidArgument, message+": "+err.Error())
+	case errors.Is(err, store.ErrHasEvolutions):
+		return status.Error(codes.FailedPrecondition, message+": "+err.Error())
+	case errors.Is(err, store.ErrQuotaExceeded):
+		return status.Error(codes.ResourceExhausted, message+": "+err.Error())
+	default:
+		return status.Error(codes.Internal, message+": "+err.Error())
+	}
+}
+
+// Setting new Request ID for every request using uuid library when reqId is not sent by user
+func requestIdOf(requestId string) string {
+	if len(requestId) > 0 {
+		return requestId
+	}
+	return uuid.New().String()
+}
+
+// Request ID and actor the changes of a call are recorded under
+func originOf(ctx context.Context, requestId string) schema.Origin {
+	return schema.Origin{RequestId: requestId, Actor: auth.Actor(ctx)}
+}
+
+func toProto(pokemon schema.Pokemon) *pb.Pokemon {
+	message := &pb.Pokemon{
+		Id:        pokemon.Id,
+		Name:      pokemon.Name,
+		Type:      pokemon.Type,
+		Height:    pokemon.Height,
+		Weight:    pokemon.Weight,
+		Abilities: pokemon.Abilities,
+	}
+	if evolution := pokemon.EvolvesFrom; evolution != nil {
+		message.EvolvesFrom = &pb.Evolution{
+			Id:        evolution.Id,
+			Level:     int32(evolution.Level),
+			Condition: evolution.Condition,
+		}
+	}
+	return message
+}
+
+func fromProto(pokemon *pb.Pokemon) schema.Pokemon {
+	decoded := schema.Pokemon{
+		Id:        pokemon.GetId(),
+		Name:      pokemon.GetName(),
+		Type:      pokemon.GetType(),
+		Height:    pokemon.GetHeight(),
+		Weight:    pokemon.GetWeight(),
+		Abilities: pokemon.GetAbilities(),
+	}
+	if evolution := pokemon.GetEvolvesFrom(); evolution != nil {
+		decoded.EvolvesFrom = &schema.Evolution{
+			Id:        evolution.GetId(),
+			Level:     int(evolution.GetLevel()),
+			Condition: evolution.GetCondition(),
+		}
+	}
+	return decoded
+}
+
+func eventToProto(event schema.PokemonEvent) *pb.PokemonEvent {
+	message := &pb.PokemonEvent{
+		EventId:    event.EventId,
+		Sequence:   event.Sequence,
+		Type:       event.Type,
+		PokemonId:  event.PokemonId,
+		RequestId:  event.RequestId,
+		OccurredAt: event.OccurredAt,
+	}
+	if event.Pokemon != nil {
+		message.Pokemon = toProto(*event.Pokemon)
+	}
+	return message
+}
@@ -0,0 +1,285 @@
+package grpcserver
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	auth "pokemon-service/auth"
+	events "pokemon-service/events"
+	history "pokemon-service/history"
+	pb "pokemon-service/pokemonpb"
+	"pokemon-service/schema"
+	store "pokemon-service/store"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/allegro/bigcache/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestUnaryCalls(t *testing.T) {
+	client, _ := loadServer(t)
+	ctx := context.Background()
+
+	inputs := []struct {
+		testName string
+		call     func() (interface{}, error)
+		code     codes.Code
+	}{
+		{testName: "TestGetByIDSuccess", code: codes.OK, call: func() (interface{}, error) {
+			return client.GetByID(ctx, &pb.GetByIDRequest{Id: "PK10001"})
+		}},
+		{testName: "TestGetByIDNotFound", code: codes.NotFound, call: func() (interface{}, error) {
+			return client.GetByID(ctx, &pb.GetByIDRequest{Id: "PK1000908"})
+		}},
+		{testName: "TestGetByIDEmpty", code: codes.InvalidArgument, call: func() (interface{}, error) {
+			return client.GetByID(ctx, &pb.GetByIDRequest{})
+		}},
+		{testName: "TestGetByNameSuccess", code: codes.OK, call: func() (interface{}, error) {
+			return client.GetByName(ctx, &pb.GetByNameRequest{Name: "Picachoo1"})
+		}},
+		{testName: "TestGetByNameNormalized", code: codes.OK, call: func() (interface{}, error) {
+			return client.GetByName(ctx, &pb.GetByNameRequest{Name: " PICACHOO1 "})
+		}},
+		{testName: "TestAddSuccess", code: codes.OK, call: func() (interface{}, error) {
+			return client.Add(ctx, &pb.AddRequest{Pokemon: &pb.Pokemon{Id: "PK10003", Name: "Picachoo3"}})
+		}},
+		{testName: "TestAddEmpty", code: codes.InvalidArgument, call: func() (interface{}, error) {
+			return client.Add(ctx, &pb.AddRequest{})
+		}},
+		{testName: "TestUpdateSuccess", code: codes.OK, call: func() (interface{}, error) {
+			return client.Update(ctx, &pb.UpdateRequest{Pokemon: &pb.Pokemon{Id: "PK10003", Name: "Raichoo3"}})
+		}},
+		{testName: "TestUpdateNotFound", code: codes.NotFound, call: func() (interface{}, error) {
+			return client.Update(ctx, &pb.UpdateRequest{Pokemon: &pb.Pokemon{Id: "PK1000908"}})
+		}},
+		{testName: "TestDeleteSuccess", code: codes.OK, call: func() (interface{}, error) {
+			return client.Delete(ctx, &pb.DeleteRequest{Id: "PK10002"})
+		}},
+		{testName: "TestDeleteNotFound", code: codes.NotFound, call: func() (interface{}, error) {
+			return client.Delete(ctx, &pb.DeleteRequest{Id: "PK10002"})
+		}},
+	}
+
+	for _, item := range inputs {
+		if _, err := item.call(); status.Code(err) != item.code {
+			t.Errorf("%v: unexpected status code: got %v want %v", item.testName, status.Code(err), item.code)
+		}
+	}
+
+	reply, err := client.List(ctx, &pb.ListRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reply.GetPokemons()) != 2 || reply.GetPokemons()[1].GetName() != "Raichoo3" {
+		t.Errorf("unexpected list: %v", reply.GetPokemons())
+	}
+	reply, err = client.List(ctx, &pb.ListRequest{Type: "tt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reply.GetPokemons()) != 1 || reply.GetPokemons()[0].GetId() != "PK10001" {
+		t.Errorf("unexpected list by type: %v", reply.GetPokemons())
+	}
+}
+
+func TestAddExisting(t *testing.T) {
+	client, _ := loadServer(t)
+	ctx := context.Background()
+
+	_, err := client.Add(ctx, &pb.AddRequest{Pokemon: &pb.Pokemon{Id: "PK10001", Name: "Raichoo1"}})
+	if status.Code(err) != codes.AlreadyExists || !strings.Contains(status.Convert(err).Message(), "PK10001") {
+		t.Errorf("unexpected error adding a taken ID: %v", err)
+	}
+	_, err = client.Add(ctx, &pb.AddRequest{Pokemon: &pb.Pokemon{Id: "PK10003", Name: "Picachoo2"}})
+	if status.Code(err) != codes.AlreadyExists || !strings.Contains(status.Convert(err).Message(), "PK10002") {
+		t.Errorf("unexpected error adding a taken name: %v", err)
+	}
+
+	reply, err := client.GetByID(ctx, &pb.GetByIDRequest{Id: "PK10001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.GetPokemon().GetName() != "Picachoo1" {
+		t.Errorf("duplicate add replaced the stored pokemon: %v", reply.GetPokemon())
+	}
+	if _, err := client.GetByID(ctx, &pb.GetByIDRequest{Id: "PK10003"}); status.Code(err) != codes.NotFound {
+		t.Errorf("pokemon with a taken name was stored: %v", err)
+	}
+}
+
+func TestGetByIDAsOf(t *testing.T) {
+	client, _ := loadServer(t)
+	ctx := context.Background()
+	beforeRename := time.Now().Format(time.RFC3339Nano)
+	time.Sleep(2 * time.Millisecond)
+	if _, err := client.Update(ctx, &pb.UpdateRequest{Pokemon: &pb.Pokemon{Id: "PK10001", Name: "Raichoo1"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	reply, err := client.GetByID(ctx, &pb.GetByIDRequest{Id: "PK10001", AsOf: beforeRename})
+	if err != nil || reply.GetPokemon().GetName() != "Picachoo1" {
+		t.Errorf("unexpected pokemon before the rename: %v %v", reply.GetPokemon(), err)
+	}
+	if _, err := client.GetByID(ctx, &pb.GetByIDRequest{Id: "PK1000908", AsOf: beforeRename}); status.Code(err) != codes.NotFound {
+		t.Errorf("unexpected status code for an unknown pokemon: %v", status.Code(err))
+	}
+	if _, err := client.GetByID(ctx, &pb.GetByIDRequest{Id: "PK10001", AsOf: "yesterday"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("unexpected status code for an invalid as_of: %v", status.Code(err))
+	}
+}
+
+func TestWatch(t *testing.T) {
+	client, server := loadServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	server.Store.Add(schema.Pokemon{Id: "PK10001", Name: "Picachoo1", Type: "EE"}, schema.Origin{})
+	watch, err := client.Watch(ctx, &pb.WatchRequest{Ids: []string{"PK10001"}, LastSequence: 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	//Subscription is registered once the server handler runs, wait for it before writing
+	for server.Stream.Subscribers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	server.Store.Add(schema.Pokemon{Id: "PK10002", Name: "Picachoo2", Type: "EE"}, schema.Origin{})
+	server.Store.Delete("PK10001", schema.Origin{RequestId: "req-1"})
+
+	event, err := watch.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.GetType() != schema.EventDeleted || event.GetPokemonId() != "PK10001" || event.GetRequestId() != "req-1" {
+		t.Errorf("unexpected event: %v", event)
+	}
+
+	//Resuming replays retained events after the given sequence
+	resumed, err := client.Watch(ctx, &pb.WatchRequest{LastSequence: event.GetSequence() - 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"PK10002", "PK10001"} {
+		event, err := resumed.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.GetPokemonId() != expected {
+			t.Errorf("unexpected resumed event: got %v want %v", event.GetPokemonId(), expected)
+		}
+	}
+
+	server.Stream.Close()
+	if _, err := watch.Recv(); status.Code(err) != codes.Unavailable && err != io.EOF {
+		t.Errorf("unexpected error after close: %v", err)
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	client, server := loadServer(t)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), apiKeyMetadata, "guess")
+	if _, err := client.Delete(ctx, &pb.DeleteRequest{Id: "PK10001"}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("unexpected status code for an unknown key: %v", status.Code(err))
+	}
+	ctx = metadata.AppendToOutgoingContext(context.Background(), apiKeyMetadata, "s3cret")
+	if _, err := client.Delete(ctx, &pb.DeleteRequest{Id: "PK10001"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Delete(context.Background(), &pb.DeleteRequest{Id: "PK10002"}); err != nil {
+		t.Fatal(err)
+	}
+
+	subscription, backlog, _ := server.Stream.Subscribe(events.Filter{Types: map[string]bool{schema.EventDeleted: true}}, 1)
+	server.Stream.Unsubscribe(subscription)
+	if len(backlog) != 2 || backlog[0].Actor != "ash" || backlog[1].Actor != auth.Anonymous {
+		t.Errorf("unexpected delete events: %+v", backlog)
+	}
+}
+
+func TestTenants(t *testing.T) {
+	_, shared := loadServer(t)
+	cache, _ := bigcache.NewBigCache(bigcache.DefaultConfig(24 * time.Hour))
+	johto := &Server{Store: store.New(cache, events.NewBus(), nil, nil), Stream: events.NewStream(10)}
+	keys, err := auth.ParseKeys("ash:s3cret,misty@johto:t0gepi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := serve(t, &Server{Store: shared.Store, Stream: shared.Stream, Keys: keys, Tenants: map[string]*Server{auth.DefaultTenant: shared, "johto": johto}})
+
+	misty := metadata.AppendToOutgoingContext(context.Background(), apiKeyMetadata, "t0gepi")
+	if _, err := client.Add(misty, &pb.AddRequest{Pokemon: &pb.Pokemon{Id: "PK10003", Name: "Togepi"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetByID(misty, &pb.GetByIDRequest{Id: "PK10003"}); err != nil {
+		t.Errorf("unexpected error getting a pokemon of the own tenant: %v", err)
+	}
+	if _, err := client.GetByID(misty, &pb.GetByIDRequest{Id: "PK10001"}); status.Code(err) != codes.NotFound {
+		t.Errorf("unexpected status code getting a pokemon of the default tenant: %v", status.Code(err))
+	}
+	if _, err := client.GetByID(context.Background(), &pb.GetByIDRequest{Id: "PK10003"}); status.Code(err) != codes.NotFound {
+		t.Errorf("pokemon added to johto is visible in the default tenant: %v", status.Code(err))
+	}
+
+	foreign := metadata.AppendToOutgoingContext(misty, tenantMetadata, auth.DefaultTenant)
+	if _, err := client.List(foreign, &pb.ListRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("unexpected status code acting in a foreign tenant: %v", status.Code(err))
+	}
+	unconfigured := &Server{Store: shared.Store, Stream: shared.Stream, Keys: keys, Tenants: map[string]*Server{auth.DefaultTenant: shared}}
+	if _, err := unconfigured.List(auth.WithTenant(context.Background(), "johto"), &pb.ListRequest{}); status.Code(err) != codes.NotFound {
+		t.Errorf("unexpected status code for a tenant that is not configured: %v", status.Code(err))
+	}
+}
+
+// Serves a store with two pokemons over an in-memory connection, ash can identify with the key s3cret.
+// Changes are kept in a history for reads at an earlier time.
+func loadServer(t *testing.T) (pb.PokemonServiceClient, *Server) {
+	cache, _ := bigcache.NewBigCache(bigcache.DefaultConfig(24 * time.Hour))
+	bus := events.NewBus()
+	keys, err := auth.ParseKeys("ash:s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := discardLogger()
+	server := &Server{Store: store.New(cache, bus, nil, nil), Stream: events.NewStream(10), History: history.New(history.Config{}, &logger), Keys: keys}
+	bus.Subscribe(server.Stream.Append)
+	bus.Subscribe(server.History.Handle)
+	server.Store.Add(schema.Pokemon{Id: "PK10001", Name: "Picachoo1", Type: "TT"}, schema.Origin{})
+	server.Store.Add(schema.Pokemon{Id: "PK10002", Name: "Picachoo2", Type: "PP"}, schema.Origin{})
+	return serve(t, server), server
+}
+
+// Serves server over an in-memory connection
+func serve(t *testing.T, server *Server) pb.PokemonServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := New(server, discardLogger())
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewPokemonServiceClient(conn)
+}
+
+func discardLogger() schema.Logger {
+	return schema.Logger{
+		InfoLogger:  log.New(io.Discard, "Info:", 0),
+		WarnLogger:  log.New(io.Discard, "Warn:", 0),
+		DebugLogger: log.New(io.Discard, "Debug:", 0),
+		ErrorLogger: log.New(io.Discard, "Error:", 0),
+		FatalLogger: log.New(io.Discard, "Fatal:", 0),
+	}
+}
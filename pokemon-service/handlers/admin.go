@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	schema "pokemon-service/schema"
+	utility "pokemon-service/utility"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Returns hit/miss counters, entry count, memory use and evictions of the cache
+func (service *Service) CacheStats(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var adminResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &adminResp, start, w)
+			return
+		}
+	}()
+	adminResp.RequestId = uuid.New().String()
+
+	cacheStats := service.Cache.Stats()
+	adminResp.Data = schema.CacheStats{
+		Entries:       service.Cache.Len(),
+		Hits:          cacheStats.Hits,
+		Misses:        cacheStats.Misses,
+		DelHits:       cacheStats.DelHits,
+		DelMisses:     cacheStats.DelMisses,
+		Collisions:    cacheStats.Collisions,
+		CapacityBytes: service.Cache.Capacity(),
+		Evictions:     service.Evictions.Snapshot(),
+	}
+	utility.FrameHttpDataResponse(200, "Success", &adminResp, start, w)
+}
+
+// Lists cache keys, filtered by the optional prefix and limit query params
+func (service *Service) CacheKeys(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var adminResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &adminResp, start, w)
+			return
+		}
+	}()
+	adminResp.RequestId = uuid.New().String()
+
+	prefix := req.URL.Query().Get("prefix")
+	limit := 0
+	if rawLimit := req.URL.Query().Get("limit"); len(rawLimit) > 0 {
+		parsed, err := strconv.Atoi(rawLimit)
+		if err != nil || parsed < 0 {
+			utility.FrameHttpDataResponse(422, fmt.Sprintf("Invalid limit:%v", rawLimit), &adminResp, start, w)
+			return
+		}
+		limit = parsed
+	}
+
+	keys := []string{}
+	iterator := service.Cache.Iterator()
+	for iterator.SetNext() {
+		entry, err := iterator.Value()
+		if err != nil {
+			//Entry was removed while iterating, skip it
+			continue
+		}
+		if strings.HasPrefix(entry.Key(), prefix) {
+			keys = append(keys, entry.Key())
+		}
+	}
+	sort.Strings(keys)
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+
+	adminResp.Data = schema.CacheKeys{Prefix: prefix, Count: len(keys), Keys: keys}
+	utility.FrameHttpDataResponse(200, "Success", &adminResp, start, w)
+}
+
+// Drops every entry from the cache
+func (service *Service) FlushCache(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var adminResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &adminResp, start, w)
+			return
+		}
+	}()
+	adminResp.RequestId = uuid.New().String()
+
+	removed, err := service.Store.Flush()
+	if err != nil {
+		utility.FrameHttpDataResponse(500, fmt.Sprintf("Unable to flush cache:%v", err), &adminResp, start, w)
+		return
+	}
+	service.Logger.WarnLogger.Println("Cache flushed through admin API, keys removed:", removed)
+
+	adminResp.Data = schema.InvalidateResult{Pokemons: []string{}, KeysRemoved: removed}
+	utility.FrameHttpDataResponse(200, "Success", &adminResp, start, w)
+}
+
+// Removes the listed IDs, names and every pokemon of the listed types from cache
+func (service *Service) InvalidateCache(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var adminResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &adminResp, start, w)
+			return
+		}
+	}()
+	adminResp.RequestId = uuid.New().String()
+
+	var invalidateReq schema.InvalidateRequest
+	if err := json.NewDecoder(req.Body).Decode(&invalidateReq); err != nil {
+		utility.FrameHttpDataResponse(400, "Invalid Json request", &adminResp, start, w)
+		return
+	}
+	if len(invalidateReq.IDs)+len(invalidateReq.Names)+len(invalidateReq.Types) == 0 {
+		utility.FrameHttpDataResponse(422, "At least one ID, Name or Type is expected", &adminResp, start, w)
+		return
+	}
+
+	//IDs and names resolve to the same record, so both keys of the record are dropped
+	targets := map[string]schema.Pokemon{}
+	for _, key := range append(invalidateReq.IDs, invalidateReq.Names...) {
+		if pokemon, err := service.Store.Get(key); err == nil {
+			targets[pokemon.Id] = pokemon
+		}
+	}
+	if len(invalidateReq.Types) > 0 {
+		matchesType := func(pokemon schema.Pokemon) bool {
+			for _, pokemonType := range invalidateReq.Types {
+				if strings.EqualFold(pokemon.Type, pokemonType) {
+					return true
+				}
+			}
+			return false
+		}
+		for _, pokemon := range service.Store.List(matchesType) {
+			targets[pokemon.Id] = pokemon
+		}
+	}
+
+	result := schema.InvalidateResult{Pokemons: []string{}}
+	for id, pokemon := range targets {
+		result.Pokemons = append(result.Pokemons, id)
+		result.KeysRemoved += service.Store.Evict(pokemon)
+	}
+	sort.Strings(result.Pokemons)
+	service.Logger.WarnLogger.Println("Cache invalidated through admin API, pokemons removed:", result.Pokemons)
+
+	adminResp.Data = result
+	utility.FrameHttpDataResponse(200, "Success", &adminResp, start, w)
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"pokemon-service/schema"
+	"testing"
+)
+
+func TestCacheStats(t *testing.T) {
+	service := loadAdminService()
+	service.Cache.Get("PK10001")
+	service.Cache.Get("PK1000908")
+
+	req, err := http.NewRequest("GET", "/admin/cache/stats", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(service.CacheStats).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	var stats schema.CacheStats
+	decodeData(t, rr, &stats)
+	if stats.Entries != 4 {
+		t.Errorf("unexpected entries: got %v want %v", stats.Entries, 4)
+	}
+	if stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("unexpected hits/misses: got %v/%v want 1/1", stats.Hits, stats.Misses)
+	}
+}
+
+func TestCacheKeys(t *testing.T) {
+	service := loadAdminService()
+
+	inputs := []struct {
+		query string
+		keys  []string
+	}{
+		{query: "", keys: []string{"PK10001", "PK10002", "Picachoo1", "Picachoo2"}},
+		{query: "?prefix=PK", keys: []string{"PK10001", "PK10002"}},
+		{query: "?prefix=Pica&limit=1", keys: []string{"Picachoo1"}},
+		{query: "?prefix=Missing", keys: []string{}},
+	}
+
+	for _, item := range inputs {
+		req, err := http.NewRequest("GET", "/admin/cache/keys"+item.query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(service.CacheKeys).ServeHTTP(rr, req)
+
+		var keys schema.CacheKeys
+		decodeData(t, rr, &keys)
+		if len(keys.Keys) != len(item.keys) {
+			t.Errorf("%v: unexpected keys: got %v want %v", item.query, keys.Keys, item.keys)
+			continue
+		}
+		for i := range item.keys {
+			if keys.Keys[i] != item.keys[i] {
+				t.Errorf("%v: unexpected keys: got %v want %v", item.query, keys.Keys, item.keys)
+				break
+			}
+		}
+	}
+}
+
+func TestFlushCache(t *testing.T) {
+	service := loadAdminService()
+
+	req, err := http.NewRequest("DELETE", "/admin/cache", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(service.FlushCache).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	if service.Cache.Len() != 0 {
+		t.Errorf("cache still holds %v entries after flush", service.Cache.Len())
+	}
+}
+
+func TestInvalidateCache(t *testing.T) {
+	inputs := []struct {
+		testName    string
+		req         schema.InvalidateRequest
+		status      int
+		keysRemoved int
+		remaining   []string
+	}{
+		{testName: "TestInvalidateByID", req: schema.InvalidateRequest{IDs: []string{"PK10001"}}, status: 200, keysRemoved: 2, remaining: []string{"PK10002", "Picachoo2"}},
+		{testName: "TestInvalidateByName", req: schema.InvalidateRequest{Names: []string{"Picachoo2"}}, status: 200, keysRemoved: 2, remaining: []string{"PK10001", "Picachoo1"}},
+		{testName: "TestInvalidateByType", req: schema.InvalidateRequest{Types: []string{"pp", "TT"}}, status: 200, keysRemoved: 4},
+		{testName: "TestInvalidateUnknown", req: schema.InvalidateRequest{IDs: []string{"PK1000908"}}, status: 200, keysRemoved: 0, remaining: []string{"PK10001", "PK10002"}},
+		{testName: "TestInvalidateEmpty", req: schema.InvalidateRequest{}, status: 422, remaining: []string{"PK10001", "PK10002"}},
+	}
+
+	for _, item := range inputs {
+		service := loadAdminService()
+		body, _ := json.Marshal(item.req)
+		req, err := http.NewRequest("POST", "/admin/cache/invalidate", bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(service.InvalidateCache).ServeHTTP(rr, req)
+
+		if rr.Code != item.status {
+			t.Errorf("%v: handler returned wrong status code: got %v want %v", item.testName, rr.Code, item.status)
+			continue
+		}
+		if item.status == http.StatusOK {
+			var result schema.InvalidateResult
+			decodeData(t, rr, &result)
+			if result.KeysRemoved != item.keysRemoved {
+				t.Errorf("%v: unexpected keys removed: got %v want %v", item.testName, result.KeysRemoved, item.keysRemoved)
+			}
+		}
+		for _, key := range item.remaining {
+			if _, err := service.Cache.Get(key); err != nil {
+				t.Errorf("%v: key %v should still be cached", item.testName, key)
+			}
+		}
+	}
+}
+
+func loadAdminService() *Service {
+	service := loadBigCache()
+	service.Logger = discardLogger()
+	return service
+}
+
+// Logger writing nowhere, so handlers can log without a logger file
+func discardLogger() *schema.Logger {
+	return &schema.Logger{
+		InfoLogger:  log.New(io.Discard, "Info:", 0),
+		WarnLogger:  log.New(io.Discard, "Warn:", 0),
+		DebugLogger: log.New(io.Discard, "Debug:", 0),
+		ErrorLogger: log.New(io.Discard, "Error:", 0),
+		FatalLogger: log.New(io.Discard, "Fatal:", 0),
+	}
+}
+
+// Decodes the Data field of a DataResponse into target
+func decodeData(t *testing.T, rr *httptest.ResponseRecorder, target interface{}) {
+	t.Helper()
+	var resp struct {
+		Data json.RawMessage `json:"Data"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(resp.Data, target); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	audit "pokemon-service/audit"
+	schema "pokemon-service/schema"
+	utility "pokemon-service/utility"
+	"runtime/debug"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	auditDefaultLimit = 100
+	auditMaxLimit     = 1000
+	ndjsonMediaType   = "application/x-ndjson"
+)
+
+// Lists audit log entries matching the filters in the query, the latest first
+func (service *Service) ListAudit(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var adminResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &adminResp, start, w)
+			return
+		}
+	}()
+	adminResp.RequestId = uuid.New().String()
+
+	filter, err := auditFilterOf(req)
+	if err != nil {
+		utility.FrameHttpDataResponse(422, err.Error(), &adminResp, start, w)
+		return
+	}
+	limit, ok := queryLimit(req, auditDefaultLimit, auditMaxLimit)
+	if !ok {
+		utility.FrameHttpDataResponse(422, fmt.Sprintf("limit between 1 and %v is expected", auditMaxLimit), &adminResp, start, w)
+		return
+	}
+
+	adminResp.Data = service.Audit.Query(filter, limit)
+	utility.FrameHttpDataResponse(200, "Success", &adminResp, start, w)
+}
+
+// Streams every audit log entry matching the filters in the query as NDJSON, oldest first
+func (service *Service) ExportAudit(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set(contentType, application)
+	var adminResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &adminResp, start, w)
+			return
+		}
+	}()
+	adminResp.RequestId = uuid.New().String()
+
+	filter, err := auditFilterOf(req)
+	if err != nil {
+		utility.FrameHttpDataResponse(422, err.Error(), &adminResp, start, w)
+		return
+	}
+
+	w.Header().Set(contentType, ndjsonMediaType)
+	w.Header().Set("Content-Disposition", `attachment; filename="audit.ndjson"`)
+	w.WriteHeader(http.StatusOK)
+	//The status is already sent, a failure can only cut the export short
+	if err := service.Audit.Export(w, filter); err != nil {
+		service.Logger.ErrorLogger.Println("Unable to export audit log for request", adminResp.RequestId, ":", err)
+	}
+}
+
+// Filter from the actor, action, pokemonId, requestId, since and until query parameters
+func auditFilterOf(req *http.Request) (audit.Filter, error) {
+	query := req.URL.Query()
+	filter := audit.Filter{
+		Actor:     query.Get("actor"),
+		Action:    query.Get("action"),
+		PokemonId: query.Get("pokemonId"),
+		RequestId: query.Get("requestId"),
+	}
+	bounds := []struct {
+		name  string
+		bound *time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}}
+	for _, item := range bounds {
+		raw := query.Get(item.name)
+		if len(raw) <= 0 {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			return audit.Filter{}, fmt.Errorf("Invalid %v:%v, an RFC 3339 timestamp is expected", item.name, raw)
+		}
+		*item.bound = parsed
+	}
+	return filter, nil
+}
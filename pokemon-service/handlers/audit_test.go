@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	audit "pokemon-service/audit"
+	auth "pokemon-service/auth"
+	events "pokemon-service/events"
+	"pokemon-service/schema"
+	"pokemon-service/store"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestAudit(t *testing.T) {
+	service := loadBigCache()
+	bus := events.NewBus()
+	auditLog, err := audit.NewLog(audit.Config{}, discardLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer auditLog.Close()
+	bus.Subscribe(auditLog.Handle)
+	service.Audit = auditLog
+	service.Store = store.New(service.Cache, bus, nil, nil)
+
+	//Changes made through the handlers are recorded under the actor the Identify middleware attached
+	changes := []struct {
+		method  string
+		id      string
+		body    string
+		handler http.HandlerFunc
+		actor   string
+	}{
+		{method: "POST", body: `{"ID":"PK10003","Name":"Picachoo3"}`, handler: service.CreatePokemon, actor: "ash"},
+		{method: "PUT", id: "PK10003", body: `{"ID":"PK10003","Name":"Raichoo3"}`, handler: service.ReplacePokemon, actor: "misty"},
+		{method: "DELETE", id: "PK10001", handler: service.DeletePokemon},
+	}
+	for _, change := range changes {
+		req, err := http.NewRequest(change.method, "/v2/pokemon", strings.NewReader(change.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(contentType, "application/json")
+		req = mux.SetURLVars(req, map[string]string{"id": change.id})
+		if len(change.actor) > 0 {
+			req = req.WithContext(auth.WithActor(req.Context(), change.actor))
+		}
+		rr := httptest.NewRecorder()
+		change.handler.ServeHTTP(rr, req)
+		if rr.Code >= 300 {
+			t.Fatalf("%v %v failed: %v %v", change.method, change.id, rr.Code, rr.Body.String())
+		}
+	}
+
+	inputs := []struct {
+		testName string
+		query    string
+		status   int
+		expected string
+	}{
+		{testName: "TestListAudit", status: 200, expected: "delete:PK10001:anonymous update:PK10003:misty create:PK10003:ash"},
+		{testName: "TestListAuditActor", query: "?actor=ash", status: 200, expected: "create:PK10003:ash"},
+		{testName: "TestListAuditPokemon", query: "?pokemonId=PK10003&action=update", status: 200, expected: "update:PK10003:misty"},
+		{testName: "TestListAuditLimit", query: "?limit=1", status: 200, expected: "delete:PK10001:anonymous"},
+		{testName: "TestListAuditUntil", query: "?until=2000-01-01T00:00:00Z", status: 200, expected: ""},
+		{testName: "TestListAuditInvalidSince", query: "?since=yesterday", status: 422},
+		{testName: "TestListAuditInvalidLimit", query: "?limit=1001", status: 422},
+	}
+	for _, item := range inputs {
+		req, err := http.NewRequest("GET", "/admin/audit"+item.query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(service.ListAudit).ServeHTTP(rr, req)
+
+		if rr.Code != item.status {
+			t.Errorf("%v: handler returned wrong status code: got %v want %v", item.testName, rr.Code, item.status)
+			continue
+		}
+		if item.status != 200 {
+			continue
+		}
+		var entries []schema.AuditEntry
+		decodeData(t, rr, &entries)
+		if listed := auditSummary(entries); listed != item.expected {
+			t.Errorf("%v: unexpected entries: got %v want %v", item.testName, listed, item.expected)
+		}
+	}
+
+	entries := auditLog.Query(audit.Filter{Action: schema.AuditUpdate}, 1)
+	if len(entries) != 1 || entries[0].Before == nil || entries[0].Before.Name != "Picachoo3" || entries[0].After.Name != "Raichoo3" || len(entries[0].RequestId) <= 0 {
+		t.Errorf("unexpected update entry: %+v", entries)
+	}
+
+	req, _ := http.NewRequest("GET", "/admin/audit/export?pokemonId=PK10003", nil)
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(service.ExportAudit).ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK || rr.Header().Get(contentType) != ndjsonMediaType {
+		t.Fatalf("unexpected export response: %v %v", rr.Code, rr.Header().Get(contentType))
+	}
+	var exported []schema.AuditEntry
+	decoder := json.NewDecoder(bytes.NewReader(rr.Body.Bytes()))
+	for decoder.More() {
+		var entry schema.AuditEntry
+		if err := decoder.Decode(&entry); err != nil {
+			t.Fatal(err)
+		}
+		exported = append(exported, entry)
+	}
+	if listed := auditSummary(exported); listed != "create:PK10003:ash update:PK10003:misty" {
+		t.Errorf("unexpected export: %v", listed)
+	}
+
+	req, _ = http.NewRequest("GET", "/admin/audit/export?until=never", nil)
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(service.ExportAudit).ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("unexpected status exporting with an invalid until: %v", rr.Code)
+	}
+}
+
+func auditSummary(entries []schema.AuditEntry) string {
+	summary := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		summary = append(summary, entry.Action+":"+entry.PokemonId+":"+entry.Actor)
+	}
+	return strings.Join(summary, " ")
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	codec "pokemon-service/codec"
+	schema "pokemon-service/schema"
+	store "pokemon-service/store"
+	utility "pokemon-service/utility"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+const (
+	// Most keys a single batch get may ask for, IDs and names together
+	batchGetMaxKeys = 500
+	// Lookups running at the same time for one batch get
+	batchGetWorkers = 8
+)
+
+// Requested key, names only match records carrying that name
+type batchKey struct {
+	key    string
+	byName bool
+}
+
+type batchOutcome struct {
+	pokemon schema.Pokemon
+	err     error
+}
+
+// Fetches many pokemons by ID and by name in one call, keys that cannot be fetched are reported per key
+func (service *Service) BatchGet(w http.ResponseWriter, req *http.Request) {
+	ctx, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var pokemonResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &pokemonResp, start, w)
+			return
+		}
+	}()
+	pokemonResp.RequestId = requestIdOf(req)
+
+	var batchReq schema.BatchGetRequest
+	if err := codec.DecodeRequest(req, &batchReq); err != nil {
+		utility.FrameHttpDataResponse(decodeStatus(err), "Invalid request body", &pokemonResp, start, w)
+		return
+	}
+	keys := batchKeys(batchReq)
+	if len(keys) <= 0 || len(keys) > batchGetMaxKeys {
+		utility.FrameHttpDataResponse(422, fmt.Sprintf("Between 1 and %v distinct IDs and Names are expected, got %v", batchGetMaxKeys, len(keys)), &pokemonResp, start, w)
+		return
+	}
+
+	outcomes := service.fetchBatch(ctx, keys)
+	result := schema.BatchGetResult{Pokemons: []schema.Pokemon{}, Errors: map[string]string{}}
+	listed := map[string]bool{}
+	for i, outcome := range outcomes {
+		if outcome.err != nil {
+			result.Errors[keys[i].key] = outcome.err.Error()
+			continue
+		}
+		if !listed[outcome.pokemon.Id] {
+			listed[outcome.pokemon.Id] = true
+			result.Pokemons = append(result.Pokemons, outcome.pokemon)
+			service.recordHit(outcome.pokemon)
+		}
+	}
+
+	pokemonResp.Data = result
+	utility.FrameHttpDataResponse(200, "Success", &pokemonResp, start, w)
+}
+
+// IDs followed by names, without repeated keys
+func batchKeys(batchReq schema.BatchGetRequest) []batchKey {
+	var keys []batchKey
+	seen := map[batchKey]bool{}
+	add := func(key string, byName bool) {
+		batch := batchKey{key: key, byName: byName}
+		if len(key) <= 0 || seen[batch] {
+			return
+		}
+		seen[batch] = true
+		keys = append(keys, batch)
+	}
+	for _, id := range batchReq.IDs {
+		add(id, false)
+	}
+	for _, name := range batchReq.Names {
+		add(name, true)
+	}
+	return keys
+}
+
+// Looks keys up on at most batchGetWorkers goroutines, outcomes line up with keys.
+// Keys not looked up before ctx ends fail with its error.
+func (service *Service) fetchBatch(ctx context.Context, keys []batchKey) []batchOutcome {
+	outcomes := make([]batchOutcome, len(keys))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < min(batchGetWorkers, len(keys)); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				outcomes[i] = service.fetchKey(ctx, keys[i])
+			}
+		}()
+	}
+	for i := range keys {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return outcomes
+}
+
+func (service *Service) fetchKey(ctx context.Context, key batchKey) batchOutcome {
+	if err := ctx.Err(); err != nil {
+		return batchOutcome{err: err}
+	}
+	if key.byName {
+		pokemon, err := service.Store.GetByName(key.key)
+		return batchOutcome{pokemon: pokemon, err: err}
+	}
+	pokemon, err := service.Store.Get(key.key)
+	//Every record is stored under its ID and its name, a name is not an ID
+	if err == nil && pokemon.Id != key.key {
+		err = store.ErrNotFound
+	}
+	return batchOutcome{pokemon: pokemon, err: err}
+}
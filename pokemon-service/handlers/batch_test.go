@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"pokemon-service/schema"
+	"testing"
+)
+
+func TestBatchGet(t *testing.T) {
+	tooMany := schema.BatchGetRequest{}
+	for i := 0; i <= batchGetMaxKeys; i++ {
+		tooMany.IDs = append(tooMany.IDs, fmt.Sprintf("PK%v", i))
+	}
+
+	inputs := []struct {
+		testName string
+		req      schema.BatchGetRequest
+		status   int
+		found    []string
+		errors   []string
+	}{
+		{testName: "TestBatchGetIdsAndNames", req: schema.BatchGetRequest{IDs: []string{"PK10001", "PK1000908"}, Names: []string{"Picachoo2", "Unknown"}},
+			status: 200, found: []string{"PK10001", "PK10002"}, errors: []string{"PK1000908", "Unknown"}},
+		{testName: "TestBatchGetSameRecordTwice", req: schema.BatchGetRequest{IDs: []string{"PK10001", "PK10001"}, Names: []string{"Picachoo1"}},
+			status: 200, found: []string{"PK10001"}},
+		{testName: "TestBatchGetNameIsNotId", req: schema.BatchGetRequest{IDs: []string{"Picachoo1"}, Names: []string{"PK10002"}},
+			status: 200, errors: []string{"Picachoo1", "PK10002"}},
+		{testName: "TestBatchGetNormalizedName", req: schema.BatchGetRequest{Names: []string{" PICACHOO2 "}},
+			status: 200, found: []string{"PK10002"}},
+		{testName: "TestBatchGetEmpty", req: schema.BatchGetRequest{}, status: 422},
+		{testName: "TestBatchGetTooMany", req: tooMany, status: 422},
+	}
+
+	for _, item := range inputs {
+		service := loadBigCache()
+		body, _ := json.Marshal(item.req)
+		req, err := http.NewRequest("POST", "/pokemon-service/batchGet", bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(service.BatchGet).ServeHTTP(rr, req)
+
+		if status := rr.Code; status != item.status {
+			t.Errorf("%v: handler returned wrong status code: got %v want %v", item.testName, status, item.status)
+		}
+		if item.status != http.StatusOK {
+			continue
+		}
+		var result schema.BatchGetResult
+		decodeData(t, rr, &result)
+		var found []string
+		for _, pokemon := range result.Pokemons {
+			found = append(found, pokemon.Id)
+		}
+		if fmt.Sprint(found) != fmt.Sprint(item.found) {
+			t.Errorf("%v: unexpected pokemons: got %v want %v", item.testName, found, item.found)
+		}
+		if len(result.Errors) != len(item.errors) {
+			t.Errorf("%v: unexpected errors: got %v want keys %v", item.testName, result.Errors, item.errors)
+		}
+		for _, key := range item.errors {
+			if _, ok := result.Errors[key]; !ok {
+				t.Errorf("%v: key %v missing from errors %v", item.testName, key, result.Errors)
+			}
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	schema "pokemon-service/schema"
+	utility "pokemon-service/utility"
+	"runtime/debug"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Serves the whole evolution chain of a pokemon as a tree starting at its first stage, whichever stage
+// the ID names
+func (service *Service) GetEvolutions(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var pokemonResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &pokemonResp, start, w)
+			return
+		}
+	}()
+	pokemonResp.RequestId = requestIdOf(req)
+
+	id := mux.Vars(req)["id"]
+	chain, err := service.Store.Evolutions(id)
+	if err != nil {
+		utility.FrameHttpDataResponse(storeStatus(err), fmt.Sprintf("Unable to get data from cache for Id:%v", id), &pokemonResp, start, w)
+		return
+	}
+
+	pokemonResp.Data = chain
+	utility.FrameHttpDataResponse(200, "Success", &pokemonResp, start, w)
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"pokemon-service/schema"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestGetEvolutions(t *testing.T) {
+	service := loadBigCache()
+	service.Store.Create(schema.Pokemon{Id: "PK10003", Name: "Raichoo", EvolvesFrom: &schema.Evolution{Id: "PK10001", Condition: "Thunder Stone"}}, schema.Origin{})
+
+	inputs := []struct {
+		testName string
+		id       string
+		status   int
+		first    string
+		later    int
+	}{
+		{testName: "TestGetEvolutionsFromFirstStage", id: "PK10001", status: 200, first: "PK10001", later: 1},
+		{testName: "TestGetEvolutionsFromLaterStage", id: "PK10003", status: 200, first: "PK10001", later: 1},
+		{testName: "TestGetEvolutionsWithoutChain", id: "PK10002", status: 200, first: "PK10002"},
+		{testName: "TestGetEvolutionsUnknown", id: "PK10009", status: 404},
+	}
+	for _, item := range inputs {
+		req, err := http.NewRequest("GET", "/v2/pokemon/{id}/evolutions", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"id": item.id})
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(service.GetEvolutions).ServeHTTP(rr, req)
+
+		if rr.Code != item.status {
+			t.Errorf("%v: handler returned wrong status code: got %v want %v", item.testName, rr.Code, item.status)
+		}
+		if item.status != 200 {
+			continue
+		}
+		var chain schema.EvolutionStage
+		decodeData(t, rr, &chain)
+		if chain.Pokemon.Id != item.first || len(chain.EvolvesTo) != item.later {
+			t.Errorf("%v: unexpected chain: %+v", item.testName, chain)
+		}
+	}
+}
+
+func TestEvolutionIntegrity(t *testing.T) {
+	service := loadBigCache()
+
+	inputs := []struct {
+		testName string
+		method   string
+		id       string
+		body     string
+		handler  func(s *Service) http.HandlerFunc
+		status   int
+	}{
+		{testName: "TestCreateEvolvesFromUnknown", method: "POST", body: `{"ID":"PK10003","Name":"Raichoo","EvolvesFrom":{"ID":"PK10009"}}`, handler: func(s *Service) http.HandlerFunc { return s.CreatePokemon }, status: 422},
+		{testName: "TestCreateEvolvesFrom", method: "POST", body: `{"ID":"PK10003","Name":"Raichoo","EvolvesFrom":{"ID":"PK10001","Level":22}}`, handler: func(s *Service) http.HandlerFunc { return s.CreatePokemon }, status: 201},
+		{testName: "TestPatchEvolvesFromLaterStage", method: "PATCH", id: "PK10001", body: `{"EvolvesFrom":{"ID":"PK10003"}}`, handler: func(s *Service) http.HandlerFunc { return s.PatchPokemon }, status: 422},
+		{testName: "TestDeleteEarlierStage", method: "DELETE", id: "PK10001", handler: func(s *Service) http.HandlerFunc { return s.DeletePokemon }, status: 409},
+		{testName: "TestDeleteEarlierStageV1", method: "DELETE", id: "PK10001", handler: func(s *Service) http.HandlerFunc { return s.DeleteByID }, status: 409},
+		{testName: "TestPatchEvolvesFromCleared", method: "PATCH", id: "PK10003", body: `{"EvolvesFrom":null}`, handler: func(s *Service) http.HandlerFunc { return s.PatchPokemon }, status: 200},
+		{testName: "TestDeleteFormerEarlierStage", method: "DELETE", id: "PK10001", handler: func(s *Service) http.HandlerFunc { return s.DeletePokemon }, status: 204},
+	}
+	for _, item := range inputs {
+		req, err := http.NewRequest(item.method, "/v2/pokemon", bytes.NewBufferString(item.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		//The v1 handler reads the capitalised variable
+		req = mux.SetURLVars(req, map[string]string{"id": item.id, "Id": item.id})
+		rr := httptest.NewRecorder()
+		item.handler(service).ServeHTTP(rr, req)
+
+		if rr.Code != item.status {
+			t.Errorf("%v: handler returned wrong status code: got %v want %v: %v", item.testName, rr.Code, item.status, rr.Body.String())
+		}
+	}
+}
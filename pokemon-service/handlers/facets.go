@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	schema "pokemon-service/schema"
+	store "pokemon-service/store"
+	utility "pokemon-service/utility"
+	"runtime/debug"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Lists the pokemons of a type ordered by ID, ?ability= narrows them down to the ones having that ability.
+// Types and abilities are compared regardless of case, accents and whitespace.
+func (service *Service) ListByType(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var pokemonResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &pokemonResp, start, w)
+			return
+		}
+	}()
+	pokemonResp.RequestId = requestIdOf(req)
+
+	pokemonType := mux.Vars(req)["type"]
+	pokemons := []schema.Pokemon{}
+	for _, id := range service.Facets.Members(pokemonType, req.URL.Query().Get("ability")) {
+		pokemon, err := service.Store.Get(id)
+		//Expired after the lookup, it is dropped from the index as soon as the eviction is handled
+		if errors.Is(err, store.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			utility.FrameHttpDataResponse(500, fmt.Sprintf("Unable to get data from cache for Id:%v", id), &pokemonResp, start, w)
+			return
+		}
+		pokemons = append(pokemons, pokemon)
+	}
+
+	pokemonResp.Data = pokemons
+	utility.FrameHttpDataResponse(200, "Success", &pokemonResp, start, w)
+}
+
+// Counts pokemons per type and per ability
+func (service *Service) FacetCounts(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var pokemonResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &pokemonResp, start, w)
+			return
+		}
+	}()
+	pokemonResp.RequestId = requestIdOf(req)
+
+	pokemonResp.Data = service.Facets.Counts()
+	utility.FrameHttpDataResponse(200, "Success", &pokemonResp, start, w)
+}
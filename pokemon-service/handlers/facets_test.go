@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"pokemon-service/schema"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestListByType(t *testing.T) {
+	service := loadBigCache()
+	service.Store.Create(schema.Pokemon{Id: "PK10003", Name: "Raichu", Type: "tt", Abilities: "Static"}, schema.Origin{})
+
+	inputs := []struct {
+		testName    string
+		pokemonType string
+		query       string
+		found       []string
+	}{
+		{testName: "TestListByType", pokemonType: "TT", found: []string{"PK10001", "PK10003"}},
+		{testName: "TestListByTypeAndAbility", pokemonType: "TT", query: "?ability=static", found: []string{"PK10003"}},
+		{testName: "TestListByUnknownType", pokemonType: "Water", found: []string{}},
+	}
+	for _, item := range inputs {
+		req, err := http.NewRequest("GET", "/pokemon-service/types/{type}"+item.query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"type": item.pokemonType})
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(service.ListByType).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("%v: handler returned wrong status code: got %v want %v", item.testName, rr.Code, http.StatusOK)
+		}
+		var pokemons []schema.Pokemon
+		decodeData(t, rr, &pokemons)
+		found := []string{}
+		for _, pokemon := range pokemons {
+			found = append(found, pokemon.Id)
+		}
+		if fmt.Sprint(found) != fmt.Sprint(item.found) {
+			t.Errorf("%v: unexpected pokemons: got %v want %v", item.testName, found, item.found)
+		}
+	}
+}
+
+func TestFacetCounts(t *testing.T) {
+	service := loadBigCache()
+	service.Store.Create(schema.Pokemon{Id: "PK10003", Name: "Raichu", Type: "TT", Abilities: "Static"}, schema.Origin{})
+	service.Store.Delete("PK10002", schema.Origin{})
+
+	req, err := http.NewRequest("GET", "/pokemon-service/facets", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(service.FacetCounts).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	var counts schema.FacetCounts
+	decodeData(t, rr, &counts)
+	if counts.Pokemons != 2 || fmt.Sprint(counts.Types) != "map[TT:2]" || fmt.Sprint(counts.Abilities) != "map[Eat:1 Sleep:1 Static:1]" {
+		t.Errorf("unexpected counts: %+v", counts)
+	}
+}
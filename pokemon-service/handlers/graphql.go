@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	graphqlapi "pokemon-service/graphqlapi"
+	schema "pokemon-service/schema"
+	"runtime/debug"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// Largest GraphQL request body accepted, in bytes
+const graphQLBodyLimit = 64 << 10
+
+// Executes GraphQL queries and mutations. The response is the standard GraphQL result rather than
+// the DataResponse envelope, so GraphQL clients can consume it; the request ID is sent in extensions.
+// Requests rejected before execution answer 400, errors raised by resolvers are reported with 200.
+func (service *Service) ExecuteGraphQL(w http.ResponseWriter, req *http.Request) {
+	ctx, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	requestId := uuid.New().String()
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			writeGraphQLResult(500, graphQLError(string(debug.Stack())), requestId, start, w)
+			return
+		}
+	}()
+
+	var graphQLReq schema.GraphQLRequest
+	readOnly := req.Method == http.MethodGet
+	if readOnly {
+		graphQLReq.Query = req.URL.Query().Get("query")
+		graphQLReq.OperationName = req.URL.Query().Get("operationName")
+		if variables := req.URL.Query().Get("variables"); len(variables) > 0 {
+			if err := json.Unmarshal([]byte(variables), &graphQLReq.Variables); err != nil {
+				writeGraphQLResult(400, graphQLError("Invalid Json variables"), requestId, start, w)
+				return
+			}
+		}
+	} else if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, graphQLBodyLimit)).Decode(&graphQLReq); err != nil {
+		writeGraphQLResult(400, graphQLError("Invalid Json request"), requestId, start, w)
+		return
+	}
+
+	result, executed := service.GraphQL.Execute(graphqlapi.WithRequestId(ctx, requestId), graphQLReq, readOnly)
+	status := 200
+	if !executed {
+		status = 400
+	}
+	writeGraphQLResult(status, result, requestId, start, w)
+}
+
+func writeGraphQLResult(status int, result *graphql.Result, requestId string, start time.Time, w http.ResponseWriter) {
+	if result.Extensions == nil {
+		result.Extensions = map[string]interface{}{}
+	}
+	result.Extensions["RequestID"] = requestId
+	result.Extensions["Latency"] = time.Since(start).String()
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
+
+func graphQLError(message string) *graphql.Result {
+	return &graphql.Result{Errors: []gqlerrors.FormattedError{gqlerrors.NewFormattedError(message)}}
+}
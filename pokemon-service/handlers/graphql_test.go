@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	graphqlapi "pokemon-service/graphqlapi"
+	"pokemon-service/schema"
+	"testing"
+)
+
+func TestExecuteGraphQL(t *testing.T) {
+	service := loadBigCache()
+	api, err := graphqlapi.New(service.Store, nil, graphqlapi.Limits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.GraphQL = api
+
+	inputs := []struct {
+		testName string
+		method   string
+		query    string
+		body     string
+		status   int
+		errors   bool
+	}{
+		{testName: "TestGraphQLPost", method: "POST", query: `{ pokemon(id: "PK10001") { name } }`, status: 200},
+		{testName: "TestGraphQLGet", method: "GET", query: `{ pokemon(name: "Picachoo2") { id } }`, status: 200},
+		{testName: "TestGraphQLMutationOverGet", method: "GET", query: `mutation { deletePokemon(id: "PK10001") { id } }`, status: 400, errors: true},
+		{testName: "TestGraphQLInvalidJson", method: "POST", body: "{", status: 400, errors: true},
+		{testName: "TestGraphQLResolverError", method: "POST", query: `mutation { deletePokemon(id: "PK1000908") { id } }`, status: 200, errors: true},
+	}
+
+	for _, item := range inputs {
+		var req *http.Request
+		if item.method == "GET" {
+			req, err = http.NewRequest("GET", "/graphql?query="+url.QueryEscape(item.query), nil)
+		} else {
+			body := []byte(item.body)
+			if len(body) == 0 {
+				body, _ = json.Marshal(schema.GraphQLRequest{Query: item.query})
+			}
+			req, err = http.NewRequest("POST", "/graphql", bytes.NewBuffer(body))
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(service.ExecuteGraphQL).ServeHTTP(rr, req)
+
+		if status := rr.Code; status != item.status {
+			t.Errorf("%v: handler returned wrong status code: got %v want %v", item.testName, status, item.status)
+		}
+		var result struct {
+			Data       map[string]interface{}
+			Errors     []map[string]interface{}
+			Extensions map[string]interface{}
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+			t.Fatal(err)
+		}
+		if result.Extensions["RequestID"] == nil {
+			t.Errorf("%v: request ID missing from extensions", item.testName)
+		}
+		if hasErrors := len(result.Errors) > 0; hasErrors != item.errors {
+			t.Errorf("%v: unexpected errors: %v", item.testName, result.Errors)
+		}
+	}
+}
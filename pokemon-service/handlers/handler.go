@@ -2,17 +2,31 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	_ "log"
 	"net/http"
+	"net/url"
+	audit "pokemon-service/audit"
+	codec "pokemon-service/codec"
+	events "pokemon-service/events"
+	eviction "pokemon-service/eviction"
+	graphqlapi "pokemon-service/graphqlapi"
+	history "pokemon-service/history"
+	index "pokemon-service/index"
+	matchup "pokemon-service/matchup"
 	schema "pokemon-service/schema"
+	store "pokemon-service/store"
+	trash "pokemon-service/trash"
 	utility "pokemon-service/utility"
+	watch "pokemon-service/watch"
+	webhooks "pokemon-service/webhooks"
 	"runtime/debug"
+	"strconv"
 	"time"
 
-	"bytes"
-	"github.com/allegro/bigcache"
+	"github.com/allegro/bigcache/v3"
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
@@ -24,11 +38,32 @@ const (
 
 // Received cache and logger from main file
 type Service struct {
-	Cache  *bigcache.BigCache
-	Logger *schema.Logger
+	Cache     *bigcache.BigCache
+	Store     *store.Store
+	Logger    *schema.Logger
+	Evictions *eviction.Recorder
+	Stream    *events.Stream
+	Webhooks  *webhooks.Dispatcher
+	Watch     *watch.Hub
+	GraphQL   *graphqlapi.API
+	OpenAPI   *openapi3.T
+	// Normalized names for search, the index Store looks names up in
+	Names *index.Names
+	// Name prefixes for suggestions ranked by how often the read handlers served each pokemon
+	Suggestions *index.Trie
+	// Inverted indexes on Type and Abilities
+	Facets *index.Facets
+	// Type effectiveness the matchups are computed from
+	Chart *matchup.Chart
+	// Deleted pokemons that can still be restored
+	Trash *trash.Bin
+	// Every change made to a pokemon, with who made it
+	Audit *audit.Log
+	// Last revisions of every pokemon for reads at an earlier time and reverts
+	History *history.Revisions
 }
 
-// Retrieves existing pokemon record from cache
+// Retrieves existing pokemon record from cache, ?asOf= serves it as it was at that time from its history
 func (service *Service) GetByID(w http.ResponseWriter, req *http.Request) {
 	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
 	defer cancelFunc()
@@ -56,16 +91,31 @@ func (service *Service) GetByID(w http.ResponseWriter, req *http.Request) {
 	xRequestID := uuid.New().String()
 	pokemonResp.RequestId = xRequestID
 
-	//Getting data from cache
-	pokemondById, _ := service.Cache.Get(id)
+	//Past records come from the history and do not count towards suggestions
+	asOf, err := asOfOf(req)
+	if err != nil {
+		utility.FrameHttpResponse(422, err.Error(), &pokemonResp, start, w)
+		return
+	}
+	if !asOf.IsZero() {
+		pokemon, status, err := service.pokemonAsOf(id, asOf)
+		if err != nil {
+			utility.FrameHttpResponse(status, fmt.Sprintf("Unable to get data for Id:%v as of %v: %v", id, asOf.Format(time.RFC3339Nano), err), &pokemonResp, start, w)
+			return
+		}
+		pokemonResp.Pokemon = pokemon
+		utility.FrameHttpResponse(200, "Success", &pokemonResp, start, w)
+		return
+	}
 
-	//Unmarshalling cache data to display in response
-	buf := bytes.NewBuffer(pokemondById)
-	err := json.NewDecoder(buf).Decode(&pokemonResp)
+	//Getting data from cache
+	pokemon, err := service.Store.Get(id)
 	if err != nil {
 		utility.FrameHttpResponse(404, fmt.Sprintf("Unable to get data from cache for Id:%v", id), &pokemonResp, start, w)
 		return
 	}
+	pokemonResp.Pokemon = pokemon
+	service.recordHit(pokemon)
 
 	utility.FrameHttpResponse(200, "Success", &pokemonResp, start, w)
 }
@@ -92,26 +142,36 @@ func (service *Service) GetByName(w http.ResponseWriter, req *http.Request) {
 		utility.FrameHttpResponse(422, "Name is expected in request param", &pokemonResp, start, w)
 		return
 	}
+	//Names change between revisions, so past records are only read by ID
+	if len(req.URL.Query().Get("asOf")) > 0 {
+		utility.FrameHttpResponse(400, "asOf is not supported by name, read the pokemon by ID instead", &pokemonResp, start, w)
+		return
+	}
 
 	//Setting new Request ID for every request using uuid library when reqId is not sent by user
 	xRequestID := uuid.New().String()
 	pokemonResp.RequestId = xRequestID
 
-	//Getting data from cache
-	pokemonByName, _ := service.Cache.Get(name)
-
-	//Unmarshalling cache data to display in response
-	buf := bytes.NewBuffer(pokemonByName)
-	err := json.NewDecoder(buf).Decode(&pokemonResp)
+	//Getting data from cache, names are also matched regardless of case, accents and whitespace
+	pokemon, err := service.Store.Get(name)
+	if errors.Is(err, store.ErrNotFound) {
+		pokemon, err = service.Store.GetByName(name)
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		utility.FrameHttpResponse(404, fmt.Sprintf("Unable to get data from cache for Name:%v", name), &pokemonResp, start, w)
+		return
+	}
 	if err != nil {
-		utility.FrameHttpResponse(400, fmt.Sprintf("Unable to get data from cache for Name:%v", name), &pokemonResp, start, w)
+		utility.FrameHttpResponse(500, fmt.Sprintf("Unable to get data from cache for Name:%v", name), &pokemonResp, start, w)
 		return
 	}
+	pokemonResp.Pokemon = pokemon
+	service.recordHit(pokemon)
 
 	utility.FrameHttpResponse(200, "Success", &pokemonResp, start, w)
 }
 
-// Deletes existing pokemon record from cache
+// Deletes existing pokemon record from cache, it stays restorable from the trash for the retention period
 func (service *Service) DeleteByID(w http.ResponseWriter, req *http.Request) {
 	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
 	defer cancelFunc()
@@ -139,25 +199,26 @@ func (service *Service) DeleteByID(w http.ResponseWriter, req *http.Request) {
 	pokemonResp.RequestId = xRequestID
 
 	//Deletes record only when its present, else not found error
-	pokemondById, _ := service.Cache.Get(id)
-	buf := bytes.NewBuffer(pokemondById)
-	err := json.NewDecoder(buf).Decode(&pokemonResp)
-	if err != nil {
+	pokemon, err := service.Store.Delete(id, originOf(req, pokemonResp.RequestId))
+	if errors.Is(err, store.ErrNotFound) {
 		utility.FrameHttpResponse(400, fmt.Sprintf("Unable to get data from cache for Id to delete:%v", id), &pokemonResp, start, w)
 		return
 	}
-
-	//If record found, deletes it from cache
-	err = service.Cache.Delete(id)
+	if errors.Is(err, store.ErrHasEvolutions) {
+		utility.FrameHttpResponse(409, fmt.Sprintf("Unable to delete pokemon with Id:%v: %v", id, err), &pokemonResp, start, w)
+		return
+	}
 	if err != nil {
 		utility.FrameHttpResponse(400, fmt.Sprintf("Unable to get data from cache for Id:%v", id), &pokemonResp, start, w)
 		return
 	}
+	pokemonResp.Pokemon = pokemon
 
 	utility.FrameHttpResponse(200, "Success", &pokemonResp, start, w)
 }
 
-// Adding new pokemon data into cache
+// Adding new pokemon data into cache, 409 when its ID or name is taken unless ?upsert=true replaces the record.
+// Pokemons sent without an ID get one allocated.
 func (service *Service) AddPokemon(w http.ResponseWriter, req *http.Request) {
 	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
 	defer cancelFunc()
@@ -175,7 +236,11 @@ func (service *Service) AddPokemon(w http.ResponseWriter, req *http.Request) {
 	}()
 
 	var pokemonReq schema.PokemonRequest
-	err := json.NewDecoder(req.Body).Decode(&pokemonReq)
+	err := codec.DecodeRequest(req, &pokemonReq)
+	if errors.Is(err, codec.ErrUnsupportedMediaType) {
+		utility.FrameHttpResponse(415, fmt.Sprintf("Unsupported Content-Type:%v", req.Header.Get(contentType)), &pokemonResp, start, w)
+		return
+	}
 	if err != nil {
 		utility.FrameHttpResponse(500, "Invalid Json request", &pokemonResp, start, w)
 		return
@@ -185,13 +250,38 @@ func (service *Service) AddPokemon(w http.ResponseWriter, req *http.Request) {
 		// Setting new Request ID for every request using uuid library when reqId is not sent by user
 		xRequestID := uuid.New().String()
 		pokemonResp.RequestId = xRequestID
+	} else {
+		pokemonResp.RequestId = pokemonReq.RequestId
 	}
 
-	resp, _ := json.Marshal(pokemonReq)
-
-	//Adds this new pokemon record into cache
-	service.Cache.Set(pokemonReq.Name, resp)
-	service.Cache.Set(pokemonReq.Id, resp)
+	//Adds this new pokemon record into cache, existing records are only overwritten when ?upsert=true is sent
+	//Pokemons sent without an ID get one allocated, so there is nothing to replace
+	upsert, _ := strconv.ParseBool(req.URL.Query().Get("upsert"))
+	created := true
+	if upsert && len(pokemonReq.Id) > 0 {
+		created, err = service.Store.Add(pokemonReq.Pokemon, originOf(req, pokemonResp.RequestId))
+	} else {
+		pokemonReq.Pokemon, err = service.Store.Create(pokemonReq.Pokemon, originOf(req, pokemonResp.RequestId))
+	}
+	var conflict *store.ConflictError
+	switch {
+	case errors.As(err, &conflict):
+		pokemonResp.Pokemon = conflict.Existing
+		utility.FrameHttpResponse(409, fmt.Sprintf("Key:%v is already taken by pokemon with Id:%v", conflict.Key, conflict.Existing.Id), &pokemonResp, start, w)
+		return
+	case errors.Is(err, store.ErrMissingId):
+		utility.FrameHttpResponse(422, "Pokemon Id is expected", &pokemonResp, start, w)
+		return
+	case errors.Is(err, store.ErrInvalidEvolution):
+		utility.FrameHttpResponse(422, fmt.Sprintf("Unable to add data to cache for Id:%v: %v", pokemonReq.Id, err), &pokemonResp, start, w)
+		return
+	case errors.Is(err, store.ErrQuotaExceeded):
+		utility.FrameHttpResponse(507, fmt.Sprintf("Unable to add data to cache for Id:%v: %v", pokemonReq.Id, err), &pokemonResp, start, w)
+		return
+	case err != nil:
+		utility.FrameHttpResponse(500, fmt.Sprintf("Unable to add data to cache for Id:%v: %v", pokemonReq.Id, err), &pokemonResp, start, w)
+		return
+	}
 
 	pokemonResp.Id = pokemonReq.Id
 	pokemonResp.Type = pokemonReq.Type
@@ -199,7 +289,13 @@ func (service *Service) AddPokemon(w http.ResponseWriter, req *http.Request) {
 	pokemonResp.Height = pokemonReq.Height
 	pokemonResp.Weight = pokemonReq.Weight
 	pokemonResp.Abilities = pokemonReq.Abilities
+	pokemonResp.EvolvesFrom = pokemonReq.EvolvesFrom
 
+	if created {
+		w.Header().Set("Location", "/pokemon-service/getByID/"+url.PathEscape(pokemonReq.Id))
+		utility.FrameHttpResponse(201, "Created", &pokemonResp, start, w)
+		return
+	}
 	utility.FrameHttpResponse(200, "Success", &pokemonResp, start, w)
 }
 
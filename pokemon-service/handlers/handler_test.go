@@ -4,11 +4,15 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"github.com/allegro/bigcache"
+	"github.com/allegro/bigcache/v3"
 	"github.com/gorilla/mux"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	index "pokemon-service/index"
+	matchup "pokemon-service/matchup"
 	"pokemon-service/schema"
+	"pokemon-service/store"
 	"testing"
 	"time"
 )
@@ -45,26 +49,33 @@ func TestHealthCheckHandler(t *testing.T) {
 func TestAddPokemon(t *testing.T) {
 
 	service := loadBigCache()
-	// We create a ResponseRecorder (which satisfies http.ResponseWriter) to record the response.
-	rr := httptest.NewRecorder()
 
 	inputs := []struct {
 		testName string
 		status   int
 		respMesg string
+		query    string
 		req      schema.PokemonRequest
 	}{
-		{testName: "TestAddPokemonSuccess1", status: 200, respMesg: "Success", req: schema.PokemonRequest{Pokemon: schema.Pokemon{Id: "111", Name: "100111"}}},
-		{testName: "TestAddPokemonSuccess2", status: 200, respMesg: "Success", req: schema.PokemonRequest{Pokemon: schema.Pokemon{Id: "222", Name: "2222"}}},
-		{testName: "TestAddPokemonFailure", status: 200, respMesg: "Success", req: schema.PokemonRequest{}},
+		{testName: "TestAddPokemonSuccess1", status: 201, respMesg: "Created", req: schema.PokemonRequest{Pokemon: schema.Pokemon{Id: "111", Name: "100111"}}},
+		{testName: "TestAddPokemonSuccess2", status: 201, respMesg: "Created", req: schema.PokemonRequest{Pokemon: schema.Pokemon{Id: "222", Name: "2222"}}},
+		{testName: "TestAddPokemonEscapedLocation", status: 201, respMesg: "Created", req: schema.PokemonRequest{Pokemon: schema.Pokemon{Id: "PK 10004/x", Name: "Picachoo4"}}},
+		{testName: "TestAddPokemonFailure", status: 422, respMesg: "Pokemon Id is expected", req: schema.PokemonRequest{}},
+		{testName: "TestAddPokemonIdTaken", status: 409, respMesg: "Key:PK10001 is already taken by pokemon with Id:PK10001", req: schema.PokemonRequest{Pokemon: schema.Pokemon{Id: "PK10001", Name: "Picachoo9"}}},
+		{testName: "TestAddPokemonNameTaken", status: 409, respMesg: "Key:Picachoo2 is already taken by pokemon with Id:PK10002", req: schema.PokemonRequest{Pokemon: schema.Pokemon{Id: "PK10009", Name: "Picachoo2"}}},
+		{testName: "TestAddPokemonUpsertReplaced", status: 200, respMesg: "Success", query: "?upsert=true", req: schema.PokemonRequest{Pokemon: schema.Pokemon{Id: "PK10001", Name: "Picachoo9"}}},
+		{testName: "TestAddPokemonUpsertCreated", status: 201, respMesg: "Created", query: "?upsert=true", req: schema.PokemonRequest{Pokemon: schema.Pokemon{Id: "PK10003", Name: "Picachoo3"}}},
+		{testName: "TestAddPokemonUpsertNameTaken", status: 409, respMesg: "Key:Picachoo2 is already taken by pokemon with Id:PK10002", query: "?upsert=true", req: schema.PokemonRequest{Pokemon: schema.Pokemon{Id: "PK10001", Name: "Picachoo2"}}},
 	}
 
 	for _, item := range inputs {
 		body, _ := json.Marshal(item.req)
-		req, err := http.NewRequest("POST", "/pokemon-service/add", bytes.NewBuffer(body))
+		req, err := http.NewRequest("POST", "/pokemon-service/add"+item.query, bytes.NewBuffer(body))
 		if err != nil {
 			t.Fatal(err)
 		}
+		// We create a ResponseRecorder (which satisfies http.ResponseWriter) to record the response.
+		rr := httptest.NewRecorder()
 		handler := http.HandlerFunc(service.AddPokemon)
 		// Our handlers satisfy http.Handler, so we can call their ServeHTTP method
 		// directly and pass in our Request and ResponseRecorder.
@@ -72,7 +83,18 @@ func TestAddPokemon(t *testing.T) {
 
 		// Check the response body is what we expect.
 		if rr.Code != item.status {
-			t.Errorf("handler returned unexpected body: got %v want %v", rr.Body.String(), item.respMesg)
+			t.Errorf("%v: handler returned wrong status code: got %v want %v", item.testName, rr.Code, item.status)
+		}
+		var resp schema.PokemonResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil || resp.RespMessage != item.respMesg {
+			t.Errorf("%v: handler returned unexpected message: got %v want %v", item.testName, resp.RespMessage, item.respMesg)
+		}
+		if location := rr.Header().Get("Location"); item.status == 201 && location != "/pokemon-service/getByID/"+url.PathEscape(item.req.Id) {
+			t.Errorf("%v: handler returned wrong Location: got %v", item.testName, location)
+		}
+		//Conflicts carry the record holding the key
+		if item.status == 409 && resp.Id == item.req.Id && resp.Name == item.req.Name {
+			t.Errorf("%v: handler returned the rejected record instead of the existing one: %+v", item.testName, resp.Pokemon)
 		}
 	}
 
@@ -121,17 +143,17 @@ func TestGetByID(t *testing.T) {
 func TestGetByName(t *testing.T) {
 
 	service := loadBigCache()
-	// We create a ResponseRecorder (which satisfies http.ResponseWriter) to record the response.
-	rr := httptest.NewRecorder()
 
 	inputs := []struct {
 		status   int
 		respMesg string
 		name     string
+		id       string
 	}{
-		{status: 200, respMesg: "Success", name: "Picachoo1"},
-		{status: 200, respMesg: "Success", name: "Picachoo1"},
-		{status: 200, respMesg: "Unable to get data from cache for Name:PK1000908", name: "PK1000908"},
+		{status: 200, respMesg: "Success", name: "Picachoo1", id: "PK10001"},
+		{status: 200, respMesg: "Success", name: " PICACHOO2 ", id: "PK10002"},
+		{status: 200, respMesg: "Success", name: "PK10001", id: "PK10001"},
+		{status: 404, respMesg: "Unable to get data from cache for Name:PK1000908", name: "PK1000908"},
 	}
 
 	for _, item := range inputs {
@@ -146,6 +168,8 @@ func TestGetByName(t *testing.T) {
 
 		req = mux.SetURLVars(req, vars)
 
+		// We create a ResponseRecorder (which satisfies http.ResponseWriter) to record the response.
+		rr := httptest.NewRecorder()
 		handler := http.HandlerFunc(service.GetByName)
 		// Our handlers satisfy http.Handler, so we can call their ServeHTTP method
 		// directly and pass in our Request and ResponseRecorder.
@@ -155,6 +179,11 @@ func TestGetByName(t *testing.T) {
 		if rr.Code != item.status {
 			t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, item.status)
 		}
+		var resp schema.PokemonResponse
+		json.NewDecoder(rr.Body).Decode(&resp)
+		if resp.RespMessage != item.respMesg || resp.Id != item.id {
+			t.Errorf("handler returned unexpected body: got %v %v want %v %v", resp.RespMessage, resp.Id, item.respMesg, item.id)
+		}
 	}
 
 }
@@ -199,7 +228,10 @@ func TestDeleteByID(t *testing.T) {
 
 }
 func loadBigCache() *Service {
-	cache, _ := bigcache.NewBigCache(bigcache.DefaultConfig(24 * time.Hour))
+	config := bigcache.DefaultConfig(24 * time.Hour)
+	// The default preallocates hundreds of MB per cache and tests create many, the cache grows when it needs to
+	config.MaxEntriesInWindow = 1024
+	cache, _ := bigcache.NewBigCache(config)
 	ps := []schema.Pokemon{
 		{Id: fmt.Sprintf("PK%v", 10001), Name: "Picachoo1", Type: "TT", Height: "20.9", Weight: "30.9", Abilities: "Eat&Sleep"},
 		{Id: fmt.Sprintf("PK%v", 10002), Name: "Picachoo2", Type: "PP", Height: "10.9", Weight: "31.1", Abilities: "Eat&Sleep"},
@@ -209,5 +241,9 @@ func loadBigCache() *Service {
 		cache.Set(val.Name, resp)
 		cache.Set(val.Id, resp)
 	}
-	return &Service{Cache: cache}
+	service := &Service{Cache: cache, Store: store.New(cache, nil, nil, nil), Suggestions: index.NewTrie(), Facets: index.NewFacets(), Chart: matchup.DefaultChart()}
+	service.Names = service.Store.Names()
+	service.Store.AddIndex(service.Suggestions)
+	service.Store.AddIndex(service.Facets)
+	return service
 }
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	codec "pokemon-service/codec"
+	history "pokemon-service/history"
+	schema "pokemon-service/schema"
+	utility "pokemon-service/utility"
+	"runtime/debug"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Lists the revisions kept for a pokemon, the latest first. Pokemons unchanged since the service started
+// have none, 404 when the ID is neither in the history nor in cache.
+func (service *Service) GetHistory(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var pokemonResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &pokemonResp, start, w)
+			return
+		}
+	}()
+	pokemonResp.RequestId = requestIdOf(req)
+
+	id := mux.Vars(req)["id"]
+	revisions, err := service.History.List(id)
+	if errors.Is(err, history.ErrNotRecorded) {
+		if _, err := service.Store.Get(id); err != nil {
+			utility.FrameHttpDataResponse(storeStatus(err), fmt.Sprintf("Unable to get history for Id:%v", id), &pokemonResp, start, w)
+			return
+		}
+		revisions = []schema.PokemonRevision{}
+	}
+
+	pokemonResp.Data = revisions
+	utility.FrameHttpDataResponse(200, "Success", &pokemonResp, start, w)
+}
+
+// Writes a revision of a pokemon back through the same checks as replacing it, which adds a new revision.
+// 201 when the pokemon was deleted since, 404 for revisions not kept and 422 for revisions that deleted it.
+func (service *Service) RevertPokemon(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var pokemonResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &pokemonResp, start, w)
+			return
+		}
+	}()
+	pokemonResp.RequestId = requestIdOf(req)
+
+	id := mux.Vars(req)["id"]
+	var revertReq schema.RevertRequest
+	if err := codec.DecodeRequest(req, &revertReq); err != nil {
+		utility.FrameHttpDataResponse(decodeStatus(err), "Invalid request body", &pokemonResp, start, w)
+		return
+	}
+	revision, err := service.History.Get(id, revertReq.Revision)
+	if err != nil {
+		utility.FrameHttpDataResponse(404, fmt.Sprintf("Unable to get revision %v of Id:%v: %v", revertReq.Revision, id, err), &pokemonResp, start, w)
+		return
+	}
+	if revision.Pokemon == nil {
+		utility.FrameHttpDataResponse(422, fmt.Sprintf("Revision %v of Id:%v removed the pokemon, there is no record to revert to", revision.Revision, id), &pokemonResp, start, w)
+		return
+	}
+	created, err := service.Store.Add(*revision.Pokemon, originOf(req, pokemonResp.RequestId))
+	if err != nil {
+		pokemonResp.Data = conflictOf(err)
+		utility.FrameHttpDataResponse(storeStatus(err), fmt.Sprintf("Unable to revert pokemon with Id:%v: %v", id, err), &pokemonResp, start, w)
+		return
+	}
+
+	pokemonResp.Data = *revision.Pokemon
+	if created {
+		w.Header().Set("Location", pokemonCollection+"/"+url.PathEscape(id))
+		utility.FrameHttpDataResponse(201, "Created", &pokemonResp, start, w)
+		return
+	}
+	utility.FrameHttpDataResponse(200, "Success", &pokemonResp, start, w)
+}
+
+// Pokemon as it was at asOf. Pokemons without recorded changes are served as they are now.
+func (service *Service) pokemonAsOf(id string, asOf time.Time) (schema.Pokemon, int, error) {
+	pokemon, err := service.History.Read(id, asOf, service.Store.Get)
+	switch {
+	case errors.Is(err, history.ErrNoRecord), errors.Is(err, history.ErrExpired):
+		return pokemon, 404, err
+	case err != nil:
+		return pokemon, storeStatus(err), err
+	}
+	return pokemon, 200, nil
+}
+
+// Time of the ?asOf= query parameter, zero when it is left out
+func asOfOf(req *http.Request) (time.Time, error) {
+	rawAsOf := req.URL.Query().Get("asOf")
+	if len(rawAsOf) <= 0 {
+		return time.Time{}, nil
+	}
+	asOf, err := time.Parse(time.RFC3339Nano, rawAsOf)
+	if err != nil {
+		return asOf, fmt.Errorf("Invalid asOf:%v, an RFC 3339 timestamp is expected", rawAsOf)
+	}
+	return asOf, nil
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	events "pokemon-service/events"
+	history "pokemon-service/history"
+	"pokemon-service/schema"
+	"pokemon-service/store"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHistory(t *testing.T) {
+	service := loadBigCache()
+	bus := events.NewBus()
+	service.History = history.New(history.Config{}, discardLogger())
+	bus.Subscribe(service.History.Handle)
+	service.Store = store.New(service.Cache, bus, nil, nil)
+
+	//PK10002 stays unchanged, PK10003 is created and deleted
+	beforeChanges := time.Now()
+	pause()
+	service.Store.Update(schema.Pokemon{Id: "PK10001", Name: "Raichoo1", Type: "TT"}, schema.Origin{})
+	pause()
+	renamed := time.Now()
+	pause()
+	service.Store.Update(schema.Pokemon{Id: "PK10001", Name: "Raichoo1", Type: "EE"}, schema.Origin{})
+	service.Store.Create(schema.Pokemon{Id: "PK10003", Name: "Picachoo3"}, schema.Origin{})
+	service.Store.Delete("PK10003", schema.Origin{})
+
+	historyInputs := []struct {
+		testName string
+		id       string
+		status   int
+		count    int
+	}{
+		{testName: "TestGetHistory", id: "PK10001", status: 200, count: 2},
+		{testName: "TestGetHistoryDeleted", id: "PK10003", status: 200, count: 2},
+		{testName: "TestGetHistoryUnchanged", id: "PK10002", status: 200, count: 0},
+		{testName: "TestGetHistoryUnknown", id: "PK10009", status: 404},
+	}
+	for _, item := range historyInputs {
+		req, _ := http.NewRequest("GET", "/v2/pokemon/{id}/history", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": item.id})
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(service.GetHistory).ServeHTTP(rr, req)
+		if rr.Code != item.status {
+			t.Errorf("%v: handler returned wrong status code: got %v want %v", item.testName, rr.Code, item.status)
+			continue
+		}
+		if item.status != 200 {
+			continue
+		}
+		var revisions []schema.PokemonRevision
+		decodeData(t, rr, &revisions)
+		if len(revisions) != item.count {
+			t.Errorf("%v: unexpected revisions: %+v", item.testName, revisions)
+		}
+	}
+
+	asOfInputs := []struct {
+		testName string
+		id       string
+		asOf     string
+		status   int
+		name     string
+		pokeType string
+	}{
+		{testName: "TestAsOfBeforeChanges", id: "PK10001", asOf: beforeChanges.Format(time.RFC3339Nano), status: 200, name: "Picachoo1", pokeType: "TT"},
+		{testName: "TestAsOfRenamed", id: "PK10001", asOf: renamed.Format(time.RFC3339Nano), status: 200, name: "Raichoo1", pokeType: "TT"},
+		{testName: "TestAsOfNow", id: "PK10001", asOf: time.Now().Format(time.RFC3339Nano), status: 200, name: "Raichoo1", pokeType: "EE"},
+		{testName: "TestAsOfUnchanged", id: "PK10002", asOf: beforeChanges.Format(time.RFC3339Nano), status: 200, name: "Picachoo2", pokeType: "PP"},
+		{testName: "TestAsOfBeforeCreated", id: "PK10003", asOf: renamed.Format(time.RFC3339Nano), status: 404},
+		{testName: "TestAsOfDeleted", id: "PK10003", asOf: time.Now().Format(time.RFC3339Nano), status: 404},
+		{testName: "TestAsOfInvalid", id: "PK10001", asOf: "yesterday", status: 422},
+	}
+	for _, item := range asOfInputs {
+		req, _ := http.NewRequest("GET", "/v2/pokemon/{id}?asOf="+url.QueryEscape(item.asOf), nil)
+		req = mux.SetURLVars(req, map[string]string{"id": item.id})
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(service.GetPokemon).ServeHTTP(rr, req)
+		if rr.Code != item.status {
+			t.Errorf("%v: handler returned wrong status code: got %v want %v", item.testName, rr.Code, item.status)
+			continue
+		}
+		if item.status != 200 {
+			continue
+		}
+		var pokemon schema.Pokemon
+		decodeData(t, rr, &pokemon)
+		if pokemon.Name != item.name || pokemon.Type != item.pokeType {
+			t.Errorf("%v: unexpected pokemon: %+v", item.testName, pokemon)
+		}
+	}
+
+	//The legacy routes read past records by ID only
+	req, _ := http.NewRequest("GET", "/pokemon-service/getByID/{Id}?asOf="+url.QueryEscape(renamed.Format(time.RFC3339Nano)), nil)
+	req = mux.SetURLVars(req, map[string]string{"Id": "PK10001"})
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(service.GetByID).ServeHTTP(rr, req)
+	var legacyResp schema.PokemonResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &legacyResp); err != nil || rr.Code != 200 || legacyResp.Pokemon.Type != "TT" {
+		t.Errorf("unexpected legacy response as of the rename: %v %v", rr.Code, rr.Body.String())
+	}
+	req, _ = http.NewRequest("GET", "/pokemon-service/getByName/{Name}?asOf="+url.QueryEscape(renamed.Format(time.RFC3339Nano)), nil)
+	req = mux.SetURLVars(req, map[string]string{"Name": "Raichoo1"})
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(service.GetByName).ServeHTTP(rr, req)
+	if rr.Code != 400 {
+		t.Errorf("unexpected status code reading by name as of a time: got %v want 400", rr.Code)
+	}
+
+	revertInputs := []struct {
+		testName string
+		id       string
+		body     string
+		status   int
+	}{
+		{testName: "TestRevertPokemon", id: "PK10001", body: `{"Revision":1}`, status: 200},
+		{testName: "TestRevertPokemonToDeletion", id: "PK10003", body: `{"Revision":2}`, status: 422},
+		{testName: "TestRevertPokemonDeleted", id: "PK10003", body: `{"Revision":1}`, status: 201},
+		{testName: "TestRevertPokemonUnknownRevision", id: "PK10001", body: `{"Revision":9}`, status: 404},
+		{testName: "TestRevertPokemonUnchanged", id: "PK10002", body: `{"Revision":1}`, status: 404},
+	}
+	for _, item := range revertInputs {
+		req, _ := http.NewRequest("POST", "/v2/pokemon/{id}/revert", strings.NewReader(item.body))
+		req.Header.Set(contentType, "application/json")
+		req = mux.SetURLVars(req, map[string]string{"id": item.id})
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(service.RevertPokemon).ServeHTTP(rr, req)
+		if rr.Code != item.status {
+			t.Errorf("%v: handler returned wrong status code: got %v want %v: %v", item.testName, rr.Code, item.status, rr.Body.String())
+		}
+	}
+
+	//Reverting goes through the store, so it is a change of its own
+	if pokemon, err := service.Store.Get("PK10001"); err != nil || pokemon.Type != "TT" {
+		t.Errorf("pokemon was not reverted: %+v %v", pokemon, err)
+	}
+	if revisions, _ := service.History.List("PK10001"); len(revisions) != 3 || revisions[0].Event != schema.EventUpdated {
+		t.Errorf("revert did not add a revision: %+v", revisions)
+	}
+	if _, err := service.Store.Get("Picachoo3"); err != nil {
+		t.Errorf("reverted pokemon is not reachable by name: %v", err)
+	}
+}
+
+// Separates changes so they are recorded at distinct times
+func pause() {
+	time.Sleep(2 * time.Millisecond)
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	codec "pokemon-service/codec"
+	matchup "pokemon-service/matchup"
+	schema "pokemon-service/schema"
+	utility "pokemon-service/utility"
+	"runtime/debug"
+	"time"
+)
+
+// Highest level and base power a matchup estimate accepts, as in the games
+const (
+	matchupMaxLevel = 100
+	matchupMaxPower = 250
+)
+
+// Matches two pokemons up on the type chart, both ways, with a damage estimate for the best move of each
+func (service *Service) MatchPokemon(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var pokemonResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &pokemonResp, start, w)
+			return
+		}
+	}()
+	pokemonResp.RequestId = requestIdOf(req)
+
+	var matchupReq schema.MatchupRequest
+	if err := codec.DecodeRequest(req, &matchupReq); err != nil {
+		utility.FrameHttpDataResponse(decodeStatus(err), "Invalid request body", &pokemonResp, start, w)
+		return
+	}
+	if len(matchupReq.Attacker) <= 0 || len(matchupReq.Defender) <= 0 {
+		utility.FrameHttpDataResponse(422, "Attacker and Defender are expected", &pokemonResp, start, w)
+		return
+	}
+	if matchupReq.Level == 0 {
+		matchupReq.Level = matchup.DefaultLevel
+	}
+	if matchupReq.Power == 0 {
+		matchupReq.Power = matchup.DefaultPower
+	}
+	if matchupReq.Level < 1 || matchupReq.Level > matchupMaxLevel || matchupReq.Power < 1 || matchupReq.Power > matchupMaxPower {
+		utility.FrameHttpDataResponse(422, fmt.Sprintf("Level between 1 and %v and Power between 1 and %v are expected", matchupMaxLevel, matchupMaxPower), &pokemonResp, start, w)
+		return
+	}
+
+	attacker, err := service.Store.Get(matchupReq.Attacker)
+	if err != nil {
+		utility.FrameHttpDataResponse(storeStatus(err), fmt.Sprintf("Unable to get data from cache for Id:%v", matchupReq.Attacker), &pokemonResp, start, w)
+		return
+	}
+	defender, err := service.Store.Get(matchupReq.Defender)
+	if err != nil {
+		utility.FrameHttpDataResponse(storeStatus(err), fmt.Sprintf("Unable to get data from cache for Id:%v", matchupReq.Defender), &pokemonResp, start, w)
+		return
+	}
+
+	pokemonResp.Data = service.Chart.Matchup(attacker, defender, matchupReq.Level, matchupReq.Power)
+	utility.FrameHttpDataResponse(200, "Success", &pokemonResp, start, w)
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pokemon-service/schema"
+	"testing"
+)
+
+func TestMatchPokemon(t *testing.T) {
+	service := loadBigCache()
+	service.Store.Create(schema.Pokemon{Id: "PK10003", Name: "Pikachu", Type: "Electric"}, schema.Origin{})
+	service.Store.Create(schema.Pokemon{Id: "PK10004", Name: "Gyarados", Type: "Water/Flying"}, schema.Origin{})
+
+	inputs := []struct {
+		testName   string
+		req        schema.MatchupRequest
+		status     int
+		multiplier float64
+		counter    float64
+	}{
+		{testName: "TestMatchPokemon", req: schema.MatchupRequest{Attacker: "PK10003", Defender: "PK10004"}, status: 200, multiplier: 4, counter: 1},
+		{testName: "TestMatchPokemonReversed", req: schema.MatchupRequest{Attacker: "Gyarados", Defender: "Pikachu", Level: 100, Power: 40}, status: 200, multiplier: 1, counter: 4},
+		{testName: "TestMatchPokemonUnknownTypes", req: schema.MatchupRequest{Attacker: "PK10001", Defender: "PK10002"}, status: 200, multiplier: 1, counter: 1},
+		{testName: "TestMatchPokemonUnknown", req: schema.MatchupRequest{Attacker: "PK10001", Defender: "PK10009"}, status: 404},
+		{testName: "TestMatchPokemonMissingDefender", req: schema.MatchupRequest{Attacker: "PK10001"}, status: 422},
+		{testName: "TestMatchPokemonLevel", req: schema.MatchupRequest{Attacker: "PK10001", Defender: "PK10002", Level: 101}, status: 422},
+	}
+	for _, item := range inputs {
+		body, _ := json.Marshal(item.req)
+		req, err := http.NewRequest("POST", "/pokemon-service/matchup", bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(service.MatchPokemon).ServeHTTP(rr, req)
+
+		if rr.Code != item.status {
+			t.Errorf("%v: handler returned wrong status code: got %v want %v", item.testName, rr.Code, item.status)
+		}
+		if item.status != 200 {
+			continue
+		}
+		var matchup schema.Matchup
+		decodeData(t, rr, &matchup)
+		if matchup.AttackerToDefender.Multiplier != item.multiplier || matchup.DefenderToAttacker.Multiplier != item.counter {
+			t.Errorf("%v: unexpected matchup: %+v", item.testName, matchup)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	openapi "pokemon-service/openapi"
+)
+
+// Serves the OpenAPI 3 document describing every route
+func (service *Service) OpenAPISpec(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set(contentType, application)
+	json.NewEncoder(w).Encode(service.OpenAPI)
+}
+
+// Serves the documentation page, it renders the document from /openapi.json in the browser
+func (service *Service) OpenAPIDocs(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set(contentType, "text/html; charset=utf-8")
+	w.Write(openapi.DocsPage)
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	index "pokemon-service/index"
+	schema "pokemon-service/schema"
+	store "pokemon-service/store"
+	utility "pokemon-service/utility"
+	"runtime/debug"
+	"strconv"
+	"time"
+)
+
+const (
+	searchDefaultLimit  = 10
+	searchMaxLimit      = 100
+	suggestDefaultLimit = 10
+	suggestMaxLimit     = 50
+)
+
+// Ranked fuzzy search on names: exact matches first, then names starting with q, then names a few typos away.
+// Names are compared normalized, so case, accents and extra whitespace do not matter.
+func (service *Service) SearchPokemon(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var pokemonResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &pokemonResp, start, w)
+			return
+		}
+	}()
+	pokemonResp.RequestId = requestIdOf(req)
+
+	query := req.URL.Query().Get("q")
+	if len(index.Normalize(query)) <= 0 {
+		utility.FrameHttpDataResponse(422, "Query q is expected", &pokemonResp, start, w)
+		return
+	}
+	limit, ok := queryLimit(req, searchDefaultLimit, searchMaxLimit)
+	if !ok {
+		utility.FrameHttpDataResponse(422, fmt.Sprintf("Invalid limit:%v, between 1 and %v is expected", req.URL.Query().Get("limit"), searchMaxLimit), &pokemonResp, start, w)
+		return
+	}
+
+	pokemons := []schema.Pokemon{}
+	for _, match := range service.Names.Search(query, limit) {
+		pokemon, err := service.Store.Get(match.Id)
+		//Expired after the search, it is dropped from the index as soon as the eviction is handled
+		if errors.Is(err, store.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			utility.FrameHttpDataResponse(500, fmt.Sprintf("Unable to get data from cache for Id:%v", match.Id), &pokemonResp, start, w)
+			return
+		}
+		pokemons = append(pokemons, pokemon)
+	}
+
+	pokemonResp.Data = pokemons
+	utility.FrameHttpDataResponse(200, "Success", &pokemonResp, start, w)
+}
+
+// Names starting with prefix for typeahead, the pokemons looked up most often first.
+// Prefixes are compared normalized like names in search.
+func (service *Service) SuggestPokemon(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var pokemonResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &pokemonResp, start, w)
+			return
+		}
+	}()
+	pokemonResp.RequestId = requestIdOf(req)
+
+	limit, ok := queryLimit(req, suggestDefaultLimit, suggestMaxLimit)
+	if !ok {
+		utility.FrameHttpDataResponse(422, fmt.Sprintf("Invalid limit:%v, between 1 and %v is expected", req.URL.Query().Get("limit"), suggestMaxLimit), &pokemonResp, start, w)
+		return
+	}
+
+	pokemonResp.Data = service.Suggestions.Suggest(req.URL.Query().Get("prefix"), limit)
+	utility.FrameHttpDataResponse(200, "Success", &pokemonResp, start, w)
+}
+
+// Counts pokemon as served towards its popularity in suggestions
+func (service *Service) recordHit(pokemon schema.Pokemon) {
+	if service.Suggestions != nil {
+		service.Suggestions.Hit(pokemon.Id)
+	}
+}
+
+// limit query param, fallback when it is absent. False when it is not a number between 1 and max.
+func queryLimit(req *http.Request, fallback int, max int) (int, bool) {
+	rawLimit := req.URL.Query().Get("limit")
+	if len(rawLimit) <= 0 {
+		return fallback, true
+	}
+	limit, err := strconv.Atoi(rawLimit)
+	if err != nil || limit < 1 || limit > max {
+		return 0, false
+	}
+	return limit, true
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"pokemon-service/schema"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestSearchPokemon(t *testing.T) {
+	service := loadBigCache()
+	service.Store.Create(schema.Pokemon{Id: "PK10003", Name: "Raichu"}, schema.Origin{})
+
+	inputs := []struct {
+		testName string
+		query    string
+		status   int
+		found    []string
+	}{
+		{testName: "TestSearchPrefix", query: "q=picach", status: 200, found: []string{"PK10001", "PK10002"}},
+		{testName: "TestSearchExactFirst", query: "q=PICACHOO2", status: 200, found: []string{"PK10002", "PK10001"}},
+		{testName: "TestSearchTypo", query: "q=" + url.QueryEscape("Raichuu"), status: 200, found: []string{"PK10003"}},
+		{testName: "TestSearchLimit", query: "q=picachoo&limit=1", status: 200, found: []string{"PK10001"}},
+		{testName: "TestSearchNoMatch", query: "q=Bulbasaur", status: 200, found: []string{}},
+		{testName: "TestSearchBlank", query: "q=%20", status: 422},
+		{testName: "TestSearchInvalidLimit", query: "q=pica&limit=1000", status: 422},
+	}
+	for _, item := range inputs {
+		req, err := http.NewRequest("GET", "/pokemon-service/search?"+item.query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(service.SearchPokemon).ServeHTTP(rr, req)
+
+		if rr.Code != item.status {
+			t.Errorf("%v: handler returned wrong status code: got %v want %v", item.testName, rr.Code, item.status)
+		}
+		if item.status != http.StatusOK {
+			continue
+		}
+		var pokemons []schema.Pokemon
+		decodeData(t, rr, &pokemons)
+		found := []string{}
+		for _, pokemon := range pokemons {
+			found = append(found, pokemon.Id)
+		}
+		if fmt.Sprint(found) != fmt.Sprint(item.found) {
+			t.Errorf("%v: unexpected pokemons: got %v want %v", item.testName, found, item.found)
+		}
+	}
+
+	//Deleted records leave the index with them
+	service.Store.Delete("PK10003", schema.Origin{})
+	if matches := service.Names.Search("raichu", 10); len(matches) != 0 {
+		t.Errorf("deleted pokemon is still indexed: %+v", matches)
+	}
+}
+
+func TestSuggestPokemon(t *testing.T) {
+	service := loadBigCache()
+	service.Store.Create(schema.Pokemon{Id: "PK10003", Name: "Raichu"}, schema.Origin{})
+	//Lookups served by the read handlers make a pokemon popular
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest("GET", "/pokemon-service/getByID/{Id}", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"Id": "PK10002"})
+		http.HandlerFunc(service.GetByID).ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	inputs := []struct {
+		testName string
+		query    string
+		status   int
+		found    []string
+	}{
+		{testName: "TestSuggestPopularFirst", query: "prefix=pica", status: 200, found: []string{"PK10002", "PK10001"}},
+		{testName: "TestSuggestNormalizedPrefix", query: "prefix=RAI", status: 200, found: []string{"PK10003"}},
+		{testName: "TestSuggestWithoutPrefix", query: "limit=1", status: 200, found: []string{"PK10002"}},
+		{testName: "TestSuggestNoMatch", query: "prefix=zz", status: 200, found: []string{}},
+		{testName: "TestSuggestInvalidLimit", query: "prefix=pica&limit=0", status: 422},
+	}
+	for _, item := range inputs {
+		req, err := http.NewRequest("GET", "/pokemon-service/suggest?"+item.query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(service.SuggestPokemon).ServeHTTP(rr, req)
+
+		if rr.Code != item.status {
+			t.Errorf("%v: handler returned wrong status code: got %v want %v", item.testName, rr.Code, item.status)
+		}
+		if item.status != http.StatusOK {
+			continue
+		}
+		var suggestions []schema.Suggestion
+		decodeData(t, rr, &suggestions)
+		found := []string{}
+		for _, suggestion := range suggestions {
+			found = append(found, suggestion.Id)
+		}
+		if fmt.Sprint(found) != fmt.Sprint(item.found) {
+			t.Errorf("%v: unexpected suggestions: got %v want %v", item.testName, found, item.found)
+		}
+	}
+}
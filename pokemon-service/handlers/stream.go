@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	events "pokemon-service/events"
+	schema "pokemon-service/schema"
+	utility "pokemon-service/utility"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Interval of SSE comments keeping idle connections and proxies alive
+var streamHeartbeat = 15 * time.Second
+
+// Streams create/update/delete/evict events as Server-Sent Events.
+// Clients resume with the Last-Event-ID header and narrow the feed with the types and ids query params.
+func (service *Service) StreamEvents(w http.ResponseWriter, req *http.Request) {
+	var streamResp schema.DataResponse
+	start := time.Now()
+	streamResp.RequestId = uuid.New().String()
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.Header().Set(contentType, application)
+		utility.FrameHttpDataResponse(500, "Streaming is not supported by the connection", &streamResp, start, w)
+		return
+	}
+
+	var lastSequence uint64
+	if lastEventId := req.Header.Get("Last-Event-ID"); len(lastEventId) > 0 {
+		parsed, err := strconv.ParseUint(lastEventId, 10, 64)
+		if err != nil {
+			w.Header().Set(contentType, application)
+			utility.FrameHttpDataResponse(400, fmt.Sprintf("Invalid Last-Event-ID:%v", lastEventId), &streamResp, start, w)
+			return
+		}
+		lastSequence = parsed
+	}
+	filter := events.Filter{
+		Types:      queryList(req, "types"),
+		PokemonIds: queryList(req, "ids"),
+	}
+	//Short type names like "created" are accepted next to "pokemon.created"
+	for eventType := range filter.Types {
+		if !strings.Contains(eventType, ".") {
+			filter.Types["pokemon."+eventType] = true
+		}
+	}
+
+	subscription, backlog, complete := service.Stream.Subscribe(filter, lastSequence)
+	defer service.Stream.Unsubscribe(subscription)
+
+	//The server's write timeout would otherwise cut every stream off after a few seconds
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	w.Header().Set(contentType, "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if !complete {
+		fmt.Fprint(w, "event: reset\ndata: events after Last-Event-ID are no longer retained\n\n")
+	}
+	for _, event := range backlog {
+		writeServerSentEvent(w, event)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case event, open := <-subscription.Events:
+			if !open {
+				//Client reconnects with its Last-Event-ID and catches up from the log
+				if subscription.Overflowed() {
+					fmt.Fprint(w, "event: overflow\ndata: client fell behind, reconnect to resume\n\n")
+					flusher.Flush()
+				}
+				return
+			}
+			writeServerSentEvent(w, event)
+			flusher.Flush()
+		}
+	}
+}
+
+func writeServerSentEvent(w http.ResponseWriter, event schema.PokemonEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Sequence, event.Type, data)
+}
+
+// Comma separated query param values as a set, nil when the param is absent
+func queryList(req *http.Request, name string) map[string]bool {
+	raw := req.URL.Query().Get(name)
+	if len(raw) <= 0 {
+		return nil
+	}
+	values := map[string]bool{}
+	for _, value := range strings.Split(raw, ",") {
+		if value = strings.TrimSpace(value); len(value) > 0 {
+			values[value] = true
+		}
+	}
+	return values
+}
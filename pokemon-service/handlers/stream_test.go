@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	events "pokemon-service/events"
+	"pokemon-service/schema"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamEvents(t *testing.T) {
+	service := loadBigCache()
+	bus := events.NewBus()
+	service.Stream = events.NewStream(10)
+	bus.Subscribe(service.Stream.Append)
+	server := httptest.NewServer(http.HandlerFunc(service.StreamEvents))
+	defer server.Close()
+
+	//Published before the client connects, only reachable through Last-Event-ID
+	bus.Publish(schema.PokemonEvent{Type: schema.EventCreated, PokemonId: "PK20001"})
+	bus.Publish(schema.PokemonEvent{Type: schema.EventCreated, PokemonId: "PK20002"})
+
+	req, err := http.NewRequest("GET", server.URL+"?types=deleted,created&ids=PK20002,PK20003", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if contentType := resp.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Fatalf("unexpected content type: %v", contentType)
+	}
+
+	lines := make(chan string, 32)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if strings.HasPrefix(scanner.Text(), "id:") {
+				lines <- scanner.Text()
+			}
+		}
+	}()
+
+	expected := []string{"id: 2"}
+	if !readEventIds(t, lines, expected) {
+		return
+	}
+
+	bus.Publish(schema.PokemonEvent{Type: schema.EventUpdated, PokemonId: "PK20002"})
+	bus.Publish(schema.PokemonEvent{Type: schema.EventDeleted, PokemonId: "PK20001"})
+	bus.Publish(schema.PokemonEvent{Type: schema.EventDeleted, PokemonId: "PK20003"})
+	readEventIds(t, lines, []string{"id: 5"})
+}
+
+func TestStreamEventsInvalidLastEventID(t *testing.T) {
+	service := loadBigCache()
+	service.Stream = events.NewStream(10)
+
+	req, err := http.NewRequest("GET", "/pokemon-service/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Last-Event-ID", "abc")
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(service.StreamEvents).ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func readEventIds(t *testing.T, lines chan string, expected []string) bool {
+	t.Helper()
+	for _, want := range expected {
+		select {
+		case line := <-lines:
+			if line != want {
+				t.Errorf("unexpected event: got %v want %v", line, want)
+				return false
+			}
+		case <-time.After(2 * time.Second):
+			t.Errorf("timed out waiting for %v", want)
+			return false
+		}
+	}
+	return true
+}
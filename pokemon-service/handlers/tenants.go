@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	auth "pokemon-service/auth"
+	schema "pokemon-service/schema"
+	utility "pokemon-service/utility"
+	"runtime/debug"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tenants holds the service of every tenant sharing the deployment, each with a store, indexes and background
+// components of its own, so one tenant's pokemons are never visible to another
+type Tenants struct {
+	tenants map[string]*tenant
+}
+
+type tenant struct {
+	service      *Service
+	requests     atomic.Uint64
+	clientErrors atomic.Uint64
+	serverErrors atomic.Uint64
+}
+
+// Wraps the service of every tenant by name
+func NewTenants(services map[string]*Service) *Tenants {
+	tenants := &Tenants{tenants: map[string]*tenant{}}
+	for name, service := range services {
+		tenants.tenants[name] = &tenant{service: service}
+	}
+	return tenants
+}
+
+// Serves requests with handler called on the service of the tenant they were identified for, 404 for tenants
+// that are not configured. The response status is counted towards the tenant's metrics.
+func (tenants *Tenants) Serve(handler func(*Service, http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		name := auth.Tenant(req.Context())
+		scoped, ok := tenants.tenants[name]
+		if !ok {
+			start := time.Now()
+			var tenantResp schema.DataResponse
+			w.Header().Set(contentType, application)
+			tenantResp.RequestId = requestIdOf(req)
+			utility.FrameHttpDataResponse(404, fmt.Sprintf("Unknown tenant %v", name), &tenantResp, start, w)
+			return
+		}
+		recorder := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		defer scoped.count(recorder)
+		handler(scoped.service, recorder, req)
+	}
+}
+
+// Lists every tenant with the usage of its catalog and the requests it served
+func (tenants *Tenants) ListTenants(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var adminResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &adminResp, start, w)
+			return
+		}
+	}()
+	adminResp.RequestId = uuid.New().String()
+
+	stats := make([]schema.TenantStats, 0, len(tenants.tenants))
+	for name, scoped := range tenants.tenants {
+		stats = append(stats, scoped.stats(name))
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Tenant < stats[j].Tenant })
+	adminResp.Data = stats
+	utility.FrameHttpDataResponse(200, "Success", &adminResp, start, w)
+}
+
+func (scoped *tenant) count(recorder *statusWriter) {
+	scoped.requests.Add(1)
+	switch {
+	case recorder.status >= http.StatusInternalServerError:
+		scoped.serverErrors.Add(1)
+	case recorder.status >= http.StatusBadRequest:
+		scoped.clientErrors.Add(1)
+	}
+}
+
+func (scoped *tenant) stats(name string) schema.TenantStats {
+	cacheStats := scoped.service.Cache.Stats()
+	return schema.TenantStats{
+		Tenant:       name,
+		Pokemons:     scoped.service.Store.Count(),
+		Quota:        scoped.service.Store.Quota(),
+		Requests:     scoped.requests.Load(),
+		ClientErrors: scoped.clientErrors.Load(),
+		ServerErrors: scoped.serverErrors.Load(),
+		CacheHits:    cacheStats.Hits,
+		CacheMisses:  cacheStats.Misses,
+	}
+}
+
+// Passes the response on while keeping its status. Event streams flush through it and websockets hijack it.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (sw *statusWriter) WriteHeader(statusCode int) {
+	if !sw.wroteHeader {
+		sw.status, sw.wroteHeader = statusCode, true
+	}
+	sw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (sw *statusWriter) Write(data []byte) (int, error) {
+	sw.wroteHeader = true
+	return sw.ResponseWriter.Write(data)
+}
+
+func (sw *statusWriter) Flush() {
+	if flusher, ok := sw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (sw *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := sw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	sw.status, sw.wroteHeader = http.StatusSwitchingProtocols, true
+	return hijacker.Hijack()
+}
+
+// Unwrap returns the wrapped http.ResponseWriter, so wrappers further out can still be reached
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
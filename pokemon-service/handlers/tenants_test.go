@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	auth "pokemon-service/auth"
+	middlewares "pokemon-service/middlewares"
+	"pokemon-service/schema"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestTenants(t *testing.T) {
+	johto := loadBigCache()
+	johto.Store.Flush()
+	johto.Store.SetQuota(1)
+	tenants := NewTenants(map[string]*Service{auth.DefaultTenant: loadBigCache(), "johto": johto})
+
+	inputs := []struct {
+		testName string
+		tenant   string
+		method   string
+		handler  func(*Service, http.ResponseWriter, *http.Request)
+		id       string
+		body     string
+		status   int
+	}{
+		{testName: "TestTenantsAdd", tenant: "johto", method: "POST", handler: (*Service).AddPokemon, body: `{"ID":"PK20001","Name":"Togepi"}`, status: 201},
+		{testName: "TestTenantsGetOwn", tenant: "johto", method: "GET", handler: (*Service).GetPokemon, id: "PK20001", status: 200},
+		{testName: "TestTenantsGetOther", tenant: auth.DefaultTenant, method: "GET", handler: (*Service).GetPokemon, id: "PK20001", status: 404},
+		{testName: "TestTenantsGetSeeded", tenant: "johto", method: "GET", handler: (*Service).GetPokemon, id: "PK10001", status: 404},
+		{testName: "TestTenantsQuota", tenant: "johto", method: "POST", handler: (*Service).AddPokemon, body: `{"ID":"PK20002","Name":"Togetic"}`, status: 507},
+		{testName: "TestTenantsUnknown", tenant: "kanto", method: "GET", handler: (*Service).GetPokemon, id: "PK10001", status: 404},
+	}
+	for _, item := range inputs {
+		req, _ := http.NewRequest(item.method, "/", strings.NewReader(item.body))
+		req.Header.Set(contentType, "application/json")
+		req = mux.SetURLVars(req, map[string]string{"id": item.id})
+		req = req.WithContext(auth.WithTenant(req.Context(), item.tenant))
+		rr := httptest.NewRecorder()
+		tenants.Serve(item.handler).ServeHTTP(rr, req)
+		if rr.Code != item.status {
+			t.Errorf("%v: handler returned wrong status code: got %v want %v: %v", item.testName, rr.Code, item.status, rr.Body.String())
+		}
+	}
+
+	req, _ := http.NewRequest("GET", "/admin/tenants", nil)
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(tenants.ListTenants).ServeHTTP(rr, req)
+	var stats []schema.TenantStats
+	decodeData(t, rr, &stats)
+	expected := []schema.TenantStats{
+		{Tenant: auth.DefaultTenant, Pokemons: 2, Requests: 1, ClientErrors: 1},
+		{Tenant: "johto", Pokemons: 1, Quota: 1, Requests: 4, ClientErrors: 1, ServerErrors: 1},
+	}
+	if len(stats) != len(expected) {
+		t.Fatalf("unexpected tenants: %+v", stats)
+	}
+	for i, tenant := range stats {
+		//Cache counters depend on how the handlers read, only the catalog and request counts are compared
+		tenant.CacheHits, tenant.CacheMisses = 0, 0
+		if tenant != expected[i] {
+			t.Errorf("unexpected stats: got %+v want %+v", tenant, expected[i])
+		}
+	}
+}
+
+func TestTenantsLocation(t *testing.T) {
+	johto := loadBigCache()
+	johto.Store.Flush()
+	tenants := NewTenants(map[string]*Service{auth.DefaultTenant: loadBigCache(), "johto": johto})
+	router := mux.NewRouter()
+	router.HandleFunc("/v2/pokemon", middlewares.SelectTenant(tenants.Serve((*Service).CreatePokemon), *discardLogger())).Methods("POST")
+
+	req, _ := http.NewRequest("POST", "/tenants/johto/v2/pokemon", strings.NewReader(`{"ID":"PK20001","Name":"Togepi"}`))
+	req.Header.Set(contentType, "application/json")
+	rr := httptest.NewRecorder()
+	middlewares.TenantPrefix(router).ServeHTTP(rr, req)
+	if rr.Code != 201 {
+		t.Fatalf("handler returned wrong status code: got %v want 201: %v", rr.Code, rr.Body.String())
+	}
+	if location := rr.Header().Get("Location"); location != "/tenants/johto/v2/pokemon/PK20001" {
+		t.Errorf("handler returned wrong location: got %v want /tenants/johto/v2/pokemon/PK20001", location)
+	}
+	if _, err := johto.Store.Get("PK20001"); err != nil {
+		t.Errorf("pokemon was not created for the tenant in the path: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	schema "pokemon-service/schema"
+	utility "pokemon-service/utility"
+	"runtime/debug"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Lists the deleted pokemons that can still be restored, the latest deleted first
+func (service *Service) ListTrash(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var pokemonResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &pokemonResp, start, w)
+			return
+		}
+	}()
+	pokemonResp.RequestId = requestIdOf(req)
+
+	pokemonResp.Data = service.Trash.List()
+	utility.FrameHttpDataResponse(200, "Success", &pokemonResp, start, w)
+}
+
+// Brings a deleted pokemon back from the trash through the same checks as creating it, so it cannot take
+// over the ID or name of a pokemon added since. It stays in the trash when restoring fails.
+func (service *Service) RestorePokemon(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var pokemonResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &pokemonResp, start, w)
+			return
+		}
+	}()
+	pokemonResp.RequestId = requestIdOf(req)
+
+	id := mux.Vars(req)["Id"]
+	trashed, ok := service.Trash.Take(id)
+	if !ok {
+		utility.FrameHttpDataResponse(404, fmt.Sprintf("Unable to get data from trash for Id:%v", id), &pokemonResp, start, w)
+		return
+	}
+	pokemon, err := service.Store.Create(trashed.Pokemon, originOf(req, pokemonResp.RequestId))
+	if err != nil {
+		service.Trash.Return(trashed)
+		pokemonResp.Data = conflictOf(err)
+		utility.FrameHttpDataResponse(storeStatus(err), fmt.Sprintf("Unable to restore pokemon with Id:%v: %v", id, err), &pokemonResp, start, w)
+		return
+	}
+
+	w.Header().Set("Location", pokemonCollection+"/"+url.PathEscape(pokemon.Id))
+	pokemonResp.Data = pokemon
+	utility.FrameHttpDataResponse(200, "Success", &pokemonResp, start, w)
+}
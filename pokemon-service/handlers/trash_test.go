@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	events "pokemon-service/events"
+	"pokemon-service/schema"
+	"pokemon-service/store"
+	trash "pokemon-service/trash"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestRestorePokemon(t *testing.T) {
+	service := loadBigCache()
+	bus := events.NewBus()
+	service.Trash = trash.NewBin(trash.Config{}, discardLogger())
+	defer service.Trash.Close()
+	bus.Subscribe(service.Trash.Handle)
+	service.Store = store.New(service.Cache, bus, nil, nil)
+
+	service.Store.Delete("PK10001", schema.Origin{})
+	service.Store.Delete("PK10002", schema.Origin{})
+	//Name of a deleted pokemon taken by a new one
+	service.Store.Create(schema.Pokemon{Id: "PK10003", Name: "Picachoo2"}, schema.Origin{})
+
+	req, _ := http.NewRequest("GET", "/pokemon-service/trash", nil)
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(service.ListTrash).ServeHTTP(rr, req)
+	var trashed []schema.TrashedPokemon
+	decodeData(t, rr, &trashed)
+	if rr.Code != http.StatusOK || len(trashed) != 2 {
+		t.Errorf("unexpected trash: %v %+v", rr.Code, trashed)
+	}
+
+	inputs := []struct {
+		testName string
+		id       string
+		status   int
+	}{
+		{testName: "TestRestorePokemon", id: "PK10001", status: 200},
+		{testName: "TestRestorePokemonTwice", id: "PK10001", status: 404},
+		{testName: "TestRestorePokemonNameTaken", id: "PK10002", status: 409},
+		{testName: "TestRestorePokemonUnknown", id: "PK10009", status: 404},
+	}
+	for _, item := range inputs {
+		req, err := http.NewRequest("POST", "/pokemon-service/{Id}/restore", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"Id": item.id})
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(service.RestorePokemon).ServeHTTP(rr, req)
+
+		if rr.Code != item.status {
+			t.Errorf("%v: handler returned wrong status code: got %v want %v", item.testName, rr.Code, item.status)
+		}
+	}
+
+	if _, err := service.Store.Get("PK10001"); err != nil {
+		t.Errorf("restored pokemon is not stored: %v", err)
+	}
+	//Failed restores leave the pokemon in the trash
+	if trashed := service.Trash.List(); len(trashed) != 1 || trashed[0].Pokemon.Id != "PK10002" {
+		t.Errorf("unexpected trash after restoring: %+v", trashed)
+	}
+}
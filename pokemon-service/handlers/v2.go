@@ -0,0 +1,364 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	auth "pokemon-service/auth"
+	codec "pokemon-service/codec"
+	schema "pokemon-service/schema"
+	store "pokemon-service/store"
+	utility "pokemon-service/utility"
+	"runtime/debug"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// Collection path of the v2 resource API, a single record lives at pokemonCollection + "/" + ID
+	pokemonCollection = "/v2/pokemon"
+	requestIdHeader   = "X-Request-ID"
+)
+
+// Retrieves a single pokemon, 404 when the ID is unknown. ?asOf= serves the pokemon as it was at that time
+// from its history, which only reads past changes, so it does not count towards suggestions.
+func (service *Service) GetPokemon(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var pokemonResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &pokemonResp, start, w)
+			return
+		}
+	}()
+	pokemonResp.RequestId = requestIdOf(req)
+
+	id := mux.Vars(req)["id"]
+	asOf, err := asOfOf(req)
+	if err != nil {
+		utility.FrameHttpDataResponse(422, err.Error(), &pokemonResp, start, w)
+		return
+	}
+	if !asOf.IsZero() {
+		pokemon, status, err := service.pokemonAsOf(id, asOf)
+		if err != nil {
+			utility.FrameHttpDataResponse(status, fmt.Sprintf("Unable to get data for Id:%v as of %v: %v", id, asOf.Format(time.RFC3339Nano), err), &pokemonResp, start, w)
+			return
+		}
+		pokemonResp.Data = pokemon
+		utility.FrameHttpDataResponse(200, "Success", &pokemonResp, start, w)
+		return
+	}
+	pokemon, err := service.Store.Get(id)
+	if err != nil {
+		utility.FrameHttpDataResponse(storeStatus(err), fmt.Sprintf("Unable to get data from cache for Id:%v", id), &pokemonResp, start, w)
+		return
+	}
+
+	pokemonResp.Data = pokemon
+	service.recordHit(pokemon)
+	utility.FrameHttpDataResponse(200, "Success", &pokemonResp, start, w)
+}
+
+// Lists pokemons ordered by ID, ?name= narrows the list down to the pokemon with that name.
+// Names are compared regardless of case, accents and whitespace.
+func (service *Service) ListPokemon(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var pokemonResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &pokemonResp, start, w)
+			return
+		}
+	}()
+	pokemonResp.RequestId = requestIdOf(req)
+
+	pokemons := []schema.Pokemon{}
+	if name, ok := req.URL.Query()["name"]; ok {
+		pokemon, err := service.Store.GetByName(name[0])
+		if err == nil {
+			pokemons = append(pokemons, pokemon)
+			service.recordHit(pokemon)
+		} else if !errors.Is(err, store.ErrNotFound) {
+			utility.FrameHttpDataResponse(500, fmt.Sprintf("Unable to get data from cache for Name:%v", name[0]), &pokemonResp, start, w)
+			return
+		}
+	} else {
+		pokemons = service.Store.List(nil)
+	}
+
+	pokemonResp.Data = pokemons
+	utility.FrameHttpDataResponse(200, "Success", &pokemonResp, start, w)
+}
+
+// Creates a pokemon, 201 with its Location or 409 with the record already holding its ID or name.
+// Pokemons sent without an ID get one allocated.
+func (service *Service) CreatePokemon(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var pokemonResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &pokemonResp, start, w)
+			return
+		}
+	}()
+	pokemonResp.RequestId = requestIdOf(req)
+
+	var pokemon schema.Pokemon
+	if err := codec.DecodeRequest(req, &pokemon); err != nil {
+		utility.FrameHttpDataResponse(decodeStatus(err), "Invalid request body", &pokemonResp, start, w)
+		return
+	}
+	pokemon, err := service.Store.Create(pokemon, originOf(req, pokemonResp.RequestId))
+	if err != nil {
+		pokemonResp.Data = conflictOf(err)
+		utility.FrameHttpDataResponse(storeStatus(err), fmt.Sprintf("Unable to create pokemon with Id:%v: %v", pokemon.Id, err), &pokemonResp, start, w)
+		return
+	}
+
+	w.Header().Set("Location", pokemonCollection+"/"+url.PathEscape(pokemon.Id))
+	pokemonResp.Data = pokemon
+	utility.FrameHttpDataResponse(201, "Created", &pokemonResp, start, w)
+}
+
+// Stores the full record under the ID in the path, 201 when it did not exist and 200 when it was replaced.
+// The body may leave out the ID but not name a different one.
+func (service *Service) ReplacePokemon(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var pokemonResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &pokemonResp, start, w)
+			return
+		}
+	}()
+	pokemonResp.RequestId = requestIdOf(req)
+
+	id := mux.Vars(req)["id"]
+	var pokemon schema.Pokemon
+	if err := codec.DecodeRequest(req, &pokemon); err != nil {
+		utility.FrameHttpDataResponse(decodeStatus(err), "Invalid request body", &pokemonResp, start, w)
+		return
+	}
+	if len(pokemon.Id) <= 0 {
+		pokemon.Id = id
+	}
+	if pokemon.Id != id {
+		utility.FrameHttpDataResponse(422, fmt.Sprintf("Body Id:%v does not match Id:%v in endpoint", pokemon.Id, id), &pokemonResp, start, w)
+		return
+	}
+	created, err := service.Store.Add(pokemon, originOf(req, pokemonResp.RequestId))
+	if err != nil {
+		pokemonResp.Data = conflictOf(err)
+		utility.FrameHttpDataResponse(storeStatus(err), fmt.Sprintf("Unable to add data to cache for Id:%v: %v", id, err), &pokemonResp, start, w)
+		return
+	}
+
+	pokemonResp.Data = pokemon
+	if created {
+		w.Header().Set("Location", pokemonCollection+"/"+url.PathEscape(pokemon.Id))
+		utility.FrameHttpDataResponse(201, "Created", &pokemonResp, start, w)
+		return
+	}
+	utility.FrameHttpDataResponse(200, "Success", &pokemonResp, start, w)
+}
+
+// Partially updates a pokemon with a JSON merge patch, absent fields are kept and null clears a field
+func (service *Service) PatchPokemon(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var pokemonResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &pokemonResp, start, w)
+			return
+		}
+	}()
+	pokemonResp.RequestId = requestIdOf(req)
+
+	id := mux.Vars(req)["id"]
+	var patch map[string]json.RawMessage
+	if err := json.NewDecoder(req.Body).Decode(&patch); err != nil {
+		utility.FrameHttpDataResponse(400, "Invalid Json request", &pokemonResp, start, w)
+		return
+	}
+	pokemon, err := service.Store.Modify(id, func(pokemon schema.Pokemon) (schema.Pokemon, error) {
+		return applyPatch(pokemon, patch)
+	}, originOf(req, pokemonResp.RequestId))
+	if err != nil {
+		pokemonResp.Data = conflictOf(err)
+		utility.FrameHttpDataResponse(storeStatus(err), fmt.Sprintf("Unable to patch pokemon with Id:%v: %v", id, err), &pokemonResp, start, w)
+		return
+	}
+
+	pokemonResp.Data = pokemon
+	utility.FrameHttpDataResponse(200, "Success", &pokemonResp, start, w)
+}
+
+// Moves a pokemon to the trash, 204 without a body, 404 when the ID is unknown or 409 while other pokemons
+// evolve from it
+func (service *Service) DeletePokemon(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var pokemonResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &pokemonResp, start, w)
+			return
+		}
+	}()
+	pokemonResp.RequestId = requestIdOf(req)
+
+	id := mux.Vars(req)["id"]
+	if _, err := service.Store.Delete(id, originOf(req, pokemonResp.RequestId)); errors.Is(err, store.ErrHasEvolutions) {
+		utility.FrameHttpDataResponse(storeStatus(err), fmt.Sprintf("Unable to delete pokemon with Id:%v: %v", id, err), &pokemonResp, start, w)
+		return
+	} else if err != nil {
+		utility.FrameHttpDataResponse(storeStatus(err), fmt.Sprintf("Unable to get data from cache for Id to delete:%v", id), &pokemonResp, start, w)
+		return
+	}
+
+	w.Header().Del(contentType)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Request ID sent by the caller through X-Request-ID, a new one otherwise
+func requestIdOf(req *http.Request) string {
+	if requestId := req.Header.Get(requestIdHeader); len(requestId) > 0 {
+		return requestId
+	}
+	return uuid.New().String()
+}
+
+// Request ID and actor of the changes a request makes, the actor is attached by the Identify middleware
+func originOf(req *http.Request, requestId string) schema.Origin {
+	return schema.Origin{RequestId: requestId, Actor: auth.Actor(req.Context())}
+}
+
+// Maps store errors to status codes
+func storeStatus(err error) int {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, store.ErrExists), errors.Is(err, store.ErrHasEvolutions):
+		return http.StatusConflict
+	case errors.Is(err, store.ErrMissingId), errors.Is(err, store.ErrIdChanged), errors.Is(err, errInvalidPatch),
+		errors.Is(err, store.ErrInvalidEvolution):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, store.ErrQuotaExceeded):
+		return http.StatusInsufficientStorage
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Record holding the key a write conflicted on, nil for other errors
+func conflictOf(err error) interface{} {
+	var conflict *store.ConflictError
+	if errors.As(err, &conflict) {
+		return conflict.Existing
+	}
+	return nil
+}
+
+// 415 for bodies in a media type that cannot be decoded, 400 for bodies that do not decode
+func decodeStatus(err error) int {
+	if errors.Is(err, codec.ErrUnsupportedMediaType) {
+		return http.StatusUnsupportedMediaType
+	}
+	return http.StatusBadRequest
+}
+
+var errInvalidPatch = errors.New("invalid patch")
+
+// Merges patch into pokemon following JSON merge patch, null clears a string field and makes the pokemon
+// a first stage when sent for EvolvesFrom
+func applyPatch(pokemon schema.Pokemon, patch map[string]json.RawMessage) (schema.Pokemon, error) {
+	fields := map[string]*string{
+		"ID":        &pokemon.Id,
+		"Name":      &pokemon.Name,
+		"Type":      &pokemon.Type,
+		"Height":    &pokemon.Height,
+		"Weight":    &pokemon.Weight,
+		"Abilities": &pokemon.Abilities,
+	}
+	for name, raw := range patch {
+		if name == "EvolvesFrom" {
+			evolution, err := patchEvolution(pokemon.EvolvesFrom, raw)
+			if err != nil {
+				return pokemon, err
+			}
+			pokemon.EvolvesFrom = evolution
+			continue
+		}
+		field, ok := fields[name]
+		if !ok {
+			return pokemon, fmt.Errorf("%w: unknown field %v", errInvalidPatch, name)
+		}
+		var value *string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return pokemon, fmt.Errorf("%w: %v must be a string or null", errInvalidPatch, name)
+		}
+		if value == nil {
+			*field = ""
+			continue
+		}
+		*field = *value
+	}
+	if len(pokemon.Name) <= 0 {
+		return pokemon, fmt.Errorf("%w: Name cannot be cleared", errInvalidPatch)
+	}
+	return pokemon, nil
+}
+
+// Merges an EvolvesFrom patch into evolution, members absent from the patch are kept
+func patchEvolution(evolution *schema.Evolution, raw json.RawMessage) (*schema.Evolution, error) {
+	var merged *schema.Evolution
+	if evolution != nil {
+		copied := *evolution
+		merged = &copied
+	}
+	if err := json.Unmarshal(raw, &merged); err != nil {
+		return evolution, fmt.Errorf("%w: EvolvesFrom must be an object or null", errInvalidPatch)
+	}
+	return merged, nil
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pokemon-service/schema"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestPokemonV2(t *testing.T) {
+	inputs := []struct {
+		testName    string
+		method      string
+		id          string
+		query       string
+		contentType string
+		body        string
+		handler     func(*Service) http.HandlerFunc
+		status      int
+		location    string
+	}{
+		{testName: "TestGetPokemon", method: "GET", id: "PK10001", handler: func(s *Service) http.HandlerFunc { return s.GetPokemon }, status: 200},
+		{testName: "TestGetPokemonUnknown", method: "GET", id: "PK1000908", handler: func(s *Service) http.HandlerFunc { return s.GetPokemon }, status: 404},
+		{testName: "TestListPokemon", method: "GET", handler: func(s *Service) http.HandlerFunc { return s.ListPokemon }, status: 200},
+		{testName: "TestListPokemonByName", method: "GET", query: "?name=Picachoo2", handler: func(s *Service) http.HandlerFunc { return s.ListPokemon }, status: 200},
+		{testName: "TestCreatePokemon", method: "POST", body: `{"ID":"PK10003","Name":"Picachoo3"}`, handler: func(s *Service) http.HandlerFunc { return s.CreatePokemon }, status: 201, location: "/v2/pokemon/PK10003"},
+		{testName: "TestCreatePokemonEscapedLocation", method: "POST", body: `{"ID":"PK 10003?","Name":"Picachoo3"}`, handler: func(s *Service) http.HandlerFunc { return s.CreatePokemon }, status: 201, location: "/v2/pokemon/PK%2010003%3F"},
+		{testName: "TestCreatePokemonExists", method: "POST", body: `{"ID":"PK10001","Name":"Picachoo1"}`, handler: func(s *Service) http.HandlerFunc { return s.CreatePokemon }, status: 409},
+		{testName: "TestCreatePokemonMessagePack", method: "POST", contentType: "application/msgpack", body: "\x82\xa2ID\xa7PK10003\xa4Name\xa9Picachoo3", handler: func(s *Service) http.HandlerFunc { return s.CreatePokemon }, status: 201, location: "/v2/pokemon/PK10003"},
+		{testName: "TestCreatePokemonProtobuf", method: "POST", contentType: "application/x-protobuf", body: "\x0a\x07PK10003\x12\x09Picachoo3", handler: func(s *Service) http.HandlerFunc { return s.CreatePokemon }, status: 201, location: "/v2/pokemon/PK10003"},
+		{testName: "TestCreatePokemonUnsupportedBody", method: "POST", contentType: "text/csv", body: "PK10003,Picachoo3", handler: func(s *Service) http.HandlerFunc { return s.CreatePokemon }, status: 415},
+		{testName: "TestCreatePokemonWithoutId", method: "POST", body: `{"Name":"Picachoo3"}`, handler: func(s *Service) http.HandlerFunc { return s.CreatePokemon }, status: 422},
+		{testName: "TestReplacePokemonCreates", method: "PUT", id: "PK10003", body: `{"Name":"Picachoo3"}`, handler: func(s *Service) http.HandlerFunc { return s.ReplacePokemon }, status: 201, location: "/v2/pokemon/PK10003"},
+		{testName: "TestReplacePokemonEscapedLocation", method: "PUT", id: "PK 10003/1", body: `{"Name":"Picachoo3"}`, handler: func(s *Service) http.HandlerFunc { return s.ReplacePokemon }, status: 201, location: "/v2/pokemon/PK%2010003%2F1"},
+		{testName: "TestReplacePokemon", method: "PUT", id: "PK10001", body: `{"ID":"PK10001","Name":"Raichoo1"}`, handler: func(s *Service) http.HandlerFunc { return s.ReplacePokemon }, status: 200},
+		{testName: "TestReplacePokemonIdMismatch", method: "PUT", id: "PK10001", body: `{"ID":"PK10002","Name":"Raichoo1"}`, handler: func(s *Service) http.HandlerFunc { return s.ReplacePokemon }, status: 422},
+		{testName: "TestPatchPokemon", method: "PATCH", id: "PK10001", body: `{"Type":"EE","Abilities":null}`, handler: func(s *Service) http.HandlerFunc { return s.PatchPokemon }, status: 200},
+		{testName: "TestPatchPokemonId", method: "PATCH", id: "PK10001", body: `{"ID":"PK10009"}`, handler: func(s *Service) http.HandlerFunc { return s.PatchPokemon }, status: 422},
+		{testName: "TestPatchPokemonClearName", method: "PATCH", id: "PK10001", body: `{"Name":null}`, handler: func(s *Service) http.HandlerFunc { return s.PatchPokemon }, status: 422},
+		{testName: "TestPatchPokemonUnknownField", method: "PATCH", id: "PK10001", body: `{"Colour":"Yellow"}`, handler: func(s *Service) http.HandlerFunc { return s.PatchPokemon }, status: 422},
+		{testName: "TestPatchPokemonUnknown", method: "PATCH", id: "PK1000908", body: `{"Type":"EE"}`, handler: func(s *Service) http.HandlerFunc { return s.PatchPokemon }, status: 404},
+		{testName: "TestDeletePokemon", method: "DELETE", id: "PK10001", handler: func(s *Service) http.HandlerFunc { return s.DeletePokemon }, status: 204},
+		{testName: "TestDeletePokemonUnknown", method: "DELETE", id: "PK1000908", handler: func(s *Service) http.HandlerFunc { return s.DeletePokemon }, status: 404},
+	}
+
+	for _, item := range inputs {
+		service := loadBigCache()
+		req, err := http.NewRequest(item.method, "/v2/pokemon"+item.query, bytes.NewBufferString(item.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", item.contentType)
+		if len(item.id) > 0 {
+			req = mux.SetURLVars(req, map[string]string{"id": item.id})
+		}
+		rr := httptest.NewRecorder()
+		item.handler(service).ServeHTTP(rr, req)
+
+		if status := rr.Code; status != item.status {
+			t.Errorf("%v: handler returned wrong status code: got %v want %v", item.testName, status, item.status)
+		}
+		if location := rr.Header().Get("Location"); location != item.location {
+			t.Errorf("%v: handler returned wrong location: got %v want %v", item.testName, location, item.location)
+		}
+		if item.status == http.StatusNoContent && rr.Body.Len() > 0 {
+			t.Errorf("%v: unexpected body %v", item.testName, rr.Body.String())
+		}
+	}
+}
+
+func TestPokemonV2Data(t *testing.T) {
+	service := loadBigCache()
+
+	req, _ := http.NewRequest("PATCH", "/v2/pokemon/PK10001", bytes.NewBufferString(`{"Type":"EE","Abilities":null}`))
+	req = mux.SetURLVars(req, map[string]string{"id": "PK10001"})
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(service.PatchPokemon).ServeHTTP(rr, req)
+	pokemon, err := service.Store.Get("PK10001")
+	if err != nil || pokemon.Type != "EE" || pokemon.Abilities != "" || pokemon.Height != "20.9" {
+		t.Errorf("unexpected patched pokemon: %+v %v", pokemon, err)
+	}
+
+	req, _ = http.NewRequest("GET", "/v2/pokemon?name=PK10002", nil)
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(service.ListPokemon).ServeHTTP(rr, req)
+	var list []schema.Pokemon
+	decodeData(t, rr, &list)
+	if len(list) != 0 {
+		t.Errorf("ID matched as a name: %+v", list)
+	}
+
+	req, _ = http.NewRequest("GET", "/v2/pokemon/PK10002", nil)
+	req.Header.Set("X-Request-ID", "req-v2")
+	req = mux.SetURLVars(req, map[string]string{"id": "PK10002"})
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(service.GetPokemon).ServeHTTP(rr, req)
+	var getResp schema.DataResponse
+	if err := json.NewDecoder(rr.Body).Decode(&getResp); err != nil {
+		t.Fatal(err)
+	}
+	if getResp.RequestId != "req-v2" {
+		t.Errorf("caller request ID not echoed: got %v", getResp.RequestId)
+	}
+}
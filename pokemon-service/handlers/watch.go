@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+	schema "pokemon-service/schema"
+	utility "pokemon-service/utility"
+	watch "pokemon-service/watch"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Upgrades to a websocket where clients subscribe to IDs or names and receive every change to them
+func (service *Service) WatchPokemon(w http.ResponseWriter, req *http.Request) {
+	var watchResp schema.DataResponse
+	start := time.Now()
+	watchResp.RequestId = uuid.New().String()
+
+	//The server's deadlines would otherwise cut the websocket off, heartbeats take over instead
+	controller := http.NewResponseController(w)
+	controller.SetReadDeadline(time.Time{})
+	controller.SetWriteDeadline(time.Time{})
+
+	err := service.Watch.Serve(w, req, service.Store.Get)
+	if err == watch.ErrHubClosed {
+		w.Header().Set(contentType, application)
+		utility.FrameHttpDataResponse(503, "Server is shutting down", &watchResp, start, w)
+		return
+	}
+	if err != nil {
+		service.Logger.WarnLogger.Println("Unable to open watch websocket:", err)
+	}
+}
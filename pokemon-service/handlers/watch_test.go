@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"pokemon-service/schema"
+	watch "pokemon-service/watch"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestWatchPokemon(t *testing.T) {
+	service := loadAdminService()
+	service.Watch = watch.NewHub(watch.Config{}, service.Logger)
+	server := httptest.NewServer(http.HandlerFunc(service.WatchPokemon))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	//Snapshots are read from the same cache the REST handlers use
+	conn.WriteJSON(schema.WatchRequest{Action: schema.WatchSubscribe, Names: []string{"Picachoo1"}})
+	var message schema.WatchMessage
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if err := conn.ReadJSON(&message); err != nil {
+		t.Fatal(err)
+	}
+	if message.Type != schema.WatchSnapshot || message.Pokemon == nil || message.Pokemon.Id != "PK10001" {
+		t.Errorf("unexpected snapshot: %+v", message)
+	}
+
+	service.Watch.Close()
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/pokemon-service/watch", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	http.HandlerFunc(service.WatchPokemon).ServeHTTP(rr, req)
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusServiceUnavailable)
+	}
+}
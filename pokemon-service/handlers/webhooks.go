@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	schema "pokemon-service/schema"
+	utility "pokemon-service/utility"
+	"runtime/debug"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// Registers a callback URL for the listed event types, the signing secret is only returned here
+func (service *Service) RegisterWebhook(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var webhookResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &webhookResp, start, w)
+			return
+		}
+	}()
+	webhookResp.RequestId = uuid.New().String()
+
+	var subscription schema.WebhookSubscription
+	if err := json.NewDecoder(req.Body).Decode(&subscription); err != nil {
+		utility.FrameHttpDataResponse(400, "Invalid Json request", &webhookResp, start, w)
+		return
+	}
+	subscription, err := service.Webhooks.Register(subscription)
+	if err != nil {
+		utility.FrameHttpDataResponse(422, err.Error(), &webhookResp, start, w)
+		return
+	}
+
+	webhookResp.Data = subscription
+	utility.FrameHttpDataResponse(201, "Success", &webhookResp, start, w)
+}
+
+// Lists registered webhooks without their secrets
+func (service *Service) ListWebhooks(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var webhookResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &webhookResp, start, w)
+			return
+		}
+	}()
+	webhookResp.RequestId = uuid.New().String()
+
+	webhookResp.Data = service.Webhooks.Subscriptions()
+	utility.FrameHttpDataResponse(200, "Success", &webhookResp, start, w)
+}
+
+// Removes a registered webhook
+func (service *Service) DeleteWebhook(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var webhookResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &webhookResp, start, w)
+			return
+		}
+	}()
+	webhookResp.RequestId = uuid.New().String()
+
+	id := mux.Vars(req)["Id"]
+	if len(id) <= 0 {
+		utility.FrameHttpDataResponse(422, "Id is expected in endpoint", &webhookResp, start, w)
+		return
+	}
+	if err := service.Webhooks.Unregister(id); err != nil {
+		utility.FrameHttpDataResponse(404, fmt.Sprintf("Unable to find webhook for Id:%v", id), &webhookResp, start, w)
+		return
+	}
+
+	utility.FrameHttpDataResponse(200, "Success", &webhookResp, start, w)
+}
+
+// Lists deliveries that failed after every retry
+func (service *Service) ListDeadLetters(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var webhookResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &webhookResp, start, w)
+			return
+		}
+	}()
+	webhookResp.RequestId = uuid.New().String()
+
+	webhookResp.Data = service.Webhooks.DeadLetters()
+	utility.FrameHttpDataResponse(200, "Success", &webhookResp, start, w)
+}
+
+// Delivers the listed dead letters again, or all of them when no ID is given. Those of deleted subscriptions are reported as skipped
+func (service *Service) ReplayDeadLetters(w http.ResponseWriter, req *http.Request) {
+	_, cancelFunc := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancelFunc()
+
+	w.Header().Set(contentType, application)
+	var webhookResp schema.DataResponse
+	start := time.Now()
+
+	//In case of any panic errors, gracefully recovers and prints stack to response
+	defer func() {
+		if err := recover(); err != nil {
+			utility.FrameHttpDataResponse(500, string(debug.Stack()), &webhookResp, start, w)
+			return
+		}
+	}()
+	webhookResp.RequestId = uuid.New().String()
+
+	var replayReq schema.WebhookReplayRequest
+	//An empty body replays every dead letter
+	if req.ContentLength != 0 {
+		if err := json.NewDecoder(req.Body).Decode(&replayReq); err != nil {
+			utility.FrameHttpDataResponse(400, "Invalid Json request", &webhookResp, start, w)
+			return
+		}
+	}
+
+	webhookResp.Data = service.Webhooks.Replay(replayReq.IDs)
+	utility.FrameHttpDataResponse(202, "Accepted", &webhookResp, start, w)
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	events "pokemon-service/events"
+	"pokemon-service/schema"
+	store "pokemon-service/store"
+	webhooks "pokemon-service/webhooks"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestWebhookRegistration(t *testing.T) {
+	service := loadAdminService()
+	service.Webhooks = webhooks.NewDispatcher(webhooks.Config{}, service.Logger)
+	defer service.Webhooks.Close()
+
+	inputs := []struct {
+		testName     string
+		subscription schema.WebhookSubscription
+		status       int
+	}{
+		{testName: "TestRegisterWebhookSuccess", subscription: schema.WebhookSubscription{URL: "https://example.com/hook", Events: []string{schema.EventCreated}}, status: 201},
+		{testName: "TestRegisterWebhookInvalid", subscription: schema.WebhookSubscription{URL: "not a url", Events: []string{schema.EventCreated}}, status: 422},
+	}
+	for _, item := range inputs {
+		body, _ := json.Marshal(item.subscription)
+		req, err := http.NewRequest("POST", "/admin/webhooks", bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(service.RegisterWebhook).ServeHTTP(rr, req)
+		if rr.Code != item.status {
+			t.Errorf("%v: handler returned wrong status code: got %v want %v", item.testName, rr.Code, item.status)
+		}
+	}
+
+	subscriptions := service.Webhooks.Subscriptions()
+	if len(subscriptions) != 1 {
+		t.Fatalf("unexpected subscriptions: got %v want 1", len(subscriptions))
+	}
+
+	for _, status := range []int{200, 404} {
+		req, err := http.NewRequest("DELETE", "/admin/webhooks/{Id}", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"Id": subscriptions[0].Id})
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(service.DeleteWebhook).ServeHTTP(rr, req)
+		if rr.Code != status {
+			t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, status)
+		}
+	}
+}
+
+func TestWritePathsPublishEvents(t *testing.T) {
+	service := loadBigCache()
+	bus := events.NewBus()
+	service.Store = store.New(service.Cache, bus, nil, nil)
+	var published []schema.PokemonEvent
+	bus.Subscribe(func(event schema.PokemonEvent) { published = append(published, event) })
+
+	for _, pokemon := range []schema.Pokemon{{Id: "PK20001", Name: "Bulbasaur"}, {Id: "PK20001", Name: "Bulbasaur", Type: "Grass"}} {
+		body, _ := json.Marshal(schema.PokemonRequest{Pokemon: pokemon})
+		req, err := http.NewRequest("POST", "/pokemon-service/Add?upsert=true", bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		http.HandlerFunc(service.AddPokemon).ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	req, err := http.NewRequest("DELETE", "/pokemon-service/{Id}", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = mux.SetURLVars(req, map[string]string{"Id": "PK20001"})
+	http.HandlerFunc(service.DeleteByID).ServeHTTP(httptest.NewRecorder(), req)
+
+	expected := []string{schema.EventCreated, schema.EventUpdated, schema.EventDeleted}
+	if len(published) != len(expected) {
+		t.Fatalf("unexpected events: got %+v want %v", published, expected)
+	}
+	for i, eventType := range expected {
+		if published[i].Type != eventType || published[i].PokemonId != "PK20001" {
+			t.Errorf("unexpected event %v: got %+v want %v", i, published[i], eventType)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package history
+
+import (
+	"errors"
+	schema "pokemon-service/schema"
+	"sync"
+	"time"
+)
+
+var (
+	// No change of the pokemon was recorded, it is unknown or unchanged since the service started
+	ErrNotRecorded = errors.New("no change recorded")
+	// The pokemon did not exist at the time asked for
+	ErrNoRecord = errors.New("pokemon did not exist")
+	// Revisions from the time asked for were already dropped
+	ErrExpired = errors.New("history does not go back that far")
+)
+
+// Tunes the history, zero values fall back to defaults
+type Config struct {
+	// Revisions kept per pokemon, the oldest are dropped first
+	MaxRevisions int
+	// Pokemons a history is kept for, the one changed least recently is dropped to make room
+	MaxPokemons int
+}
+
+func (config Config) withDefaults() Config {
+	if config.MaxRevisions <= 0 {
+		config.MaxRevisions = 10
+	}
+	if config.MaxPokemons <= 0 {
+		config.MaxPokemons = 100000
+	}
+	return config
+}
+
+type revision struct {
+	schema schema.PokemonRevision
+	// Record before the change, nil when it did not exist
+	before *schema.Pokemon
+	at     time.Time
+}
+
+type record struct {
+	// Oldest first
+	revisions []revision
+	// Number of the latest revision, also counting the dropped ones
+	latest uint64
+}
+
+// Revisions keeps the last revisions of every pokemon, so it can be read as it was at an earlier time and
+// reverted to an earlier version. It fills up from the events of the store.
+type Revisions struct {
+	config  Config
+	logger  *schema.Logger
+	mutex   sync.Mutex
+	records map[string]*record
+}
+
+func New(config Config, logger *schema.Logger) *Revisions {
+	return &Revisions{config: config.withDefaults(), logger: logger, records: map[string]*record{}}
+}
+
+// Event bus subscriber adding a revision for every change
+func (revisions *Revisions) Handle(event schema.PokemonEvent) {
+	if event.Pokemon == nil {
+		return
+	}
+	at, err := time.Parse(time.RFC3339Nano, event.OccurredAt)
+	if err != nil {
+		at = time.Now()
+	}
+	added := revision{
+		schema: schema.PokemonRevision{
+			Event:      event.Type,
+			Actor:      event.Actor,
+			RequestId:  event.RequestId,
+			RecordedAt: at.UTC().Format(time.RFC3339Nano),
+		},
+		at: at,
+	}
+	switch event.Type {
+	case schema.EventCreated:
+		added.schema.Pokemon = event.Pokemon
+	case schema.EventUpdated:
+		added.schema.Pokemon, added.before = event.Pokemon, event.Previous
+	case schema.EventDeleted, schema.EventEvicted:
+		added.before = event.Pokemon
+	default:
+		return
+	}
+
+	revisions.mutex.Lock()
+	defer revisions.mutex.Unlock()
+	changed, ok := revisions.records[event.PokemonId]
+	if !ok {
+		if len(revisions.records) >= revisions.config.MaxPokemons {
+			revisions.evict()
+		}
+		changed = &record{}
+		revisions.records[event.PokemonId] = changed
+	}
+	changed.latest++
+	added.schema.Revision = changed.latest
+	changed.revisions = append(changed.revisions, added)
+	if len(changed.revisions) > revisions.config.MaxRevisions {
+		changed.revisions = append([]revision{}, changed.revisions[len(changed.revisions)-revisions.config.MaxRevisions:]...)
+	}
+}
+
+// Revisions kept for the pokemon with the given ID, the latest first
+func (revisions *Revisions) List(id string) ([]schema.PokemonRevision, error) {
+	revisions.mutex.Lock()
+	defer revisions.mutex.Unlock()
+	changed, ok := revisions.records[id]
+	if !ok {
+		return nil, ErrNotRecorded
+	}
+	listed := make([]schema.PokemonRevision, 0, len(changed.revisions))
+	for i := len(changed.revisions) - 1; i >= 0; i-- {
+		listed = append(listed, changed.revisions[i].schema)
+	}
+	return listed, nil
+}
+
+// Revision with the given number of the pokemon with the given ID
+func (revisions *Revisions) Get(id string, number uint64) (schema.PokemonRevision, error) {
+	revisions.mutex.Lock()
+	defer revisions.mutex.Unlock()
+	changed, ok := revisions.records[id]
+	if !ok || number <= 0 || number > changed.latest {
+		return schema.PokemonRevision{}, ErrNotRecorded
+	}
+	oldest := changed.revisions[0].schema.Revision
+	if number < oldest {
+		return schema.PokemonRevision{}, ErrExpired
+	}
+	return changed.revisions[number-oldest].schema, nil
+}
+
+// Record of the pokemon with the given ID as it was at the given time
+func (revisions *Revisions) AsOf(id string, at time.Time) (schema.Pokemon, error) {
+	revisions.mutex.Lock()
+	defer revisions.mutex.Unlock()
+	changed, ok := revisions.records[id]
+	if !ok {
+		return schema.Pokemon{}, ErrNotRecorded
+	}
+	for i := len(changed.revisions) - 1; i >= 0; i-- {
+		if changed.revisions[i].at.After(at) {
+			continue
+		}
+		if changed.revisions[i].schema.Pokemon == nil {
+			return schema.Pokemon{}, ErrNoRecord
+		}
+		return *changed.revisions[i].schema.Pokemon, nil
+	}
+	//Before every kept revision, the oldest one tells what the record was before it unless older ones were dropped
+	oldest := changed.revisions[0]
+	if oldest.schema.Revision > 1 {
+		return schema.Pokemon{}, ErrExpired
+	}
+	if oldest.before == nil {
+		return schema.Pokemon{}, ErrNoRecord
+	}
+	return *oldest.before, nil
+}
+
+// Like AsOf, but a pokemon without recorded changes is read with current, as it has not changed since
+func (revisions *Revisions) Read(id string, at time.Time, current func(string) (schema.Pokemon, error)) (schema.Pokemon, error) {
+	pokemon, err := revisions.AsOf(id, at)
+	if errors.Is(err, ErrNotRecorded) {
+		return current(id)
+	}
+	return pokemon, err
+}
+
+// Drops the history of the pokemon changed least recently. Called with the mutex held.
+func (revisions *Revisions) evict() {
+	var evictedId string
+	var evictedAt time.Time
+	for id, changed := range revisions.records {
+		latest := changed.revisions[len(changed.revisions)-1].at
+		if len(evictedId) <= 0 || latest.Before(evictedAt) {
+			evictedId, evictedAt = id, latest
+		}
+	}
+	if len(evictedId) > 0 {
+		delete(revisions.records, evictedId)
+		revisions.logger.WarnLogger.Println("Revision history is full, dropped the history of pokemon", evictedId)
+	}
+}
@@ -0,0 +1,126 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	schema "pokemon-service/schema"
+	"testing"
+	"time"
+)
+
+var base = time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+
+func TestRevisions(t *testing.T) {
+	revisions := New(Config{MaxRevisions: 3}, discardLogger())
+	bulbasaur := schema.Pokemon{Id: "PK1", Name: "Bulbasaur"}
+	ivysaur := schema.Pokemon{Id: "PK1", Name: "Ivysaur"}
+	venusaur := schema.Pokemon{Id: "PK1", Name: "Venusaur"}
+	revisions.Handle(change(schema.EventCreated, &bulbasaur, nil, 0))
+	revisions.Handle(change(schema.EventUpdated, &ivysaur, &bulbasaur, 1))
+	revisions.Handle(change(schema.EventDeleted, &ivysaur, nil, 2))
+
+	listed, err := revisions.List("PK1")
+	if err != nil || describe(listed) != "[3:<nil> 2:Ivysaur 1:Bulbasaur]" {
+		t.Errorf("unexpected history: %v %v", describe(listed), err)
+	}
+	if _, err := revisions.List("PK2"); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("unexpected error listing an unknown pokemon: %v", err)
+	}
+
+	inputs := []struct {
+		testName string
+		at       time.Time
+		name     string
+		err      error
+	}{
+		{testName: "TestAsOfBeforeCreate", at: base.Add(-time.Minute), err: ErrNoRecord},
+		{testName: "TestAsOfCreate", at: base, name: "Bulbasaur"},
+		{testName: "TestAsOfBetween", at: base.Add(90 * time.Second), name: "Ivysaur"},
+		{testName: "TestAsOfDeleted", at: base.Add(5 * time.Minute), err: ErrNoRecord},
+	}
+	for _, item := range inputs {
+		pokemon, err := revisions.AsOf("PK1", item.at)
+		if !errors.Is(err, item.err) || pokemon.Name != item.name {
+			t.Errorf("%v: unexpected record: %+v %v", item.testName, pokemon, err)
+		}
+	}
+
+	//Dropping the oldest revision hides the times before the kept ones
+	revisions.Handle(change(schema.EventCreated, &venusaur, nil, 3))
+	if listed, _ := revisions.List("PK1"); describe(listed) != "[4:Venusaur 3:<nil> 2:Ivysaur]" {
+		t.Errorf("unexpected history after dropping a revision: %v", describe(listed))
+	}
+	if _, err := revisions.AsOf("PK1", base); !errors.Is(err, ErrExpired) {
+		t.Errorf("unexpected error reading before the kept revisions: %v", err)
+	}
+	if _, err := revisions.Get("PK1", 1); !errors.Is(err, ErrExpired) {
+		t.Errorf("unexpected error getting a dropped revision: %v", err)
+	}
+	if _, err := revisions.Get("PK1", 5); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("unexpected error getting a future revision: %v", err)
+	}
+	if revision, err := revisions.Get("PK1", 2); err != nil || revision.Pokemon.Name != "Ivysaur" || revision.Actor != "ash" {
+		t.Errorf("unexpected revision: %+v %v", revision, err)
+	}
+}
+
+func TestRevisionsBeforeFirstChange(t *testing.T) {
+	revisions := New(Config{MaxPokemons: 1}, discardLogger())
+	//Pokemons loaded before the history started only show up with their first change
+	revisions.Handle(change(schema.EventUpdated, &schema.Pokemon{Id: "PK1", Name: "Ivysaur"}, &schema.Pokemon{Id: "PK1", Name: "Bulbasaur"}, 0))
+	if pokemon, err := revisions.AsOf("PK1", base.Add(-time.Hour)); err != nil || pokemon.Name != "Bulbasaur" {
+		t.Errorf("unexpected record before the first change: %+v %v", pokemon, err)
+	}
+	current := func(id string) (schema.Pokemon, error) { return schema.Pokemon{Id: id, Name: "Current"}, nil }
+	if pokemon, err := revisions.Read("PK1", base.Add(-time.Hour), current); err != nil || pokemon.Name != "Bulbasaur" {
+		t.Errorf("unexpected record read before the first change: %+v %v", pokemon, err)
+	}
+	if pokemon, err := revisions.Read("PK3", base, current); err != nil || pokemon.Name != "Current" {
+		t.Errorf("unexpected record read without recorded changes: %+v %v", pokemon, err)
+	}
+
+	//A full history drops the pokemon changed least recently
+	revisions.Handle(change(schema.EventCreated, &schema.Pokemon{Id: "PK2", Name: "Squirtle"}, nil, 1))
+	if _, err := revisions.List("PK1"); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("history of the pokemon changed least recently was kept: %v", err)
+	}
+	if _, err := revisions.List("PK2"); err != nil {
+		t.Errorf("unexpected error listing the latest pokemon: %v", err)
+	}
+}
+
+// Change made by ash the given minutes after base
+func change(eventType string, pokemon *schema.Pokemon, previous *schema.Pokemon, minutes int) schema.PokemonEvent {
+	return schema.PokemonEvent{
+		Type:       eventType,
+		PokemonId:  pokemon.Id,
+		Pokemon:    pokemon,
+		Previous:   previous,
+		Actor:      "ash",
+		OccurredAt: base.Add(time.Duration(minutes) * time.Minute).Format(time.RFC3339Nano),
+	}
+}
+
+func describe(revisions []schema.PokemonRevision) string {
+	described := make([]string, 0, len(revisions))
+	for _, revision := range revisions {
+		name := "<nil>"
+		if revision.Pokemon != nil {
+			name = revision.Pokemon.Name
+		}
+		described = append(described, fmt.Sprintf("%v:%v", revision.Revision, name))
+	}
+	return fmt.Sprint(described)
+}
+
+func discardLogger() *schema.Logger {
+	return &schema.Logger{
+		InfoLogger:  log.New(io.Discard, "Info:", 0),
+		WarnLogger:  log.New(io.Discard, "Warn:", 0),
+		DebugLogger: log.New(io.Discard, "Debug:", 0),
+		ErrorLogger: log.New(io.Discard, "Error:", 0),
+		FatalLogger: log.New(io.Discard, "Fatal:", 0),
+	}
+}
@@ -0,0 +1,149 @@
+package idempotency
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInProgress = errors.New("a request with this idempotency key is still in progress")
+	ErrMismatch   = errors.New("idempotency key was used for a different request")
+)
+
+// Tunes the store, zero values fall back to defaults
+type Config struct {
+	// How long a key is remembered after its request completed
+	TTL time.Duration
+	// Keys kept at most, the ones expiring first make room for new ones
+	MaxEntries int
+	// Interval between removals of expired keys
+	SweepInterval time.Duration
+}
+
+func (config Config) withDefaults() Config {
+	if config.TTL <= 0 {
+		config.TTL = 24 * time.Hour
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = 10000
+	}
+	if config.SweepInterval <= 0 {
+		config.SweepInterval = time.Minute
+	}
+	return config
+}
+
+// Response recorded for a key and replayed to retries of the same request
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+type entry struct {
+	fingerprint string
+	// Nil while the request is in progress
+	response *Response
+	expires  time.Time
+}
+
+// Store remembers idempotency keys together with a fingerprint of the request that used them and its response
+type Store struct {
+	config  Config
+	mutex   sync.Mutex
+	entries map[string]*entry
+	done    chan struct{}
+	once    sync.Once
+}
+
+// Creates a store and starts sweeping expired keys, Close stops it
+func NewStore(config Config) *Store {
+	store := &Store{config: config.withDefaults(), entries: map[string]*entry{}, done: make(chan struct{})}
+	go store.sweep()
+	return store
+}
+
+// Claims key for the request with the given fingerprint. Returns the recorded response when the same request
+// completed before, ErrInProgress while it is still running and ErrMismatch when key belongs to another request.
+// Nil for both means the caller owns the key until it calls Complete or Release.
+func (store *Store) Begin(key string, fingerprint string) (*Response, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	now := time.Now()
+	if existing, ok := store.entries[key]; ok && now.Before(existing.expires) {
+		switch {
+		case existing.fingerprint != fingerprint:
+			return nil, ErrMismatch
+		case existing.response == nil:
+			return nil, ErrInProgress
+		default:
+			return existing.response, nil
+		}
+	}
+	if len(store.entries) >= store.config.MaxEntries {
+		store.evict(now)
+	}
+	//Keys in progress expire too, so a request that never finishes cannot hold its key forever
+	store.entries[key] = &entry{fingerprint: fingerprint, expires: now.Add(store.config.TTL)}
+	return nil, nil
+}
+
+// Records the response of the request owning key, retries get it replayed until the key expires
+func (store *Store) Complete(key string, response Response) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	if existing, ok := store.entries[key]; ok {
+		existing.response = &response
+		existing.expires = time.Now().Add(store.config.TTL)
+	}
+}
+
+// Forgets key, so the request can be retried as if it was never sent
+func (store *Store) Release(key string) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	delete(store.entries, key)
+}
+
+func (store *Store) Close() {
+	store.once.Do(func() { close(store.done) })
+}
+
+// Drops expired keys, or the one expiring first when none expired. Called with the mutex held.
+func (store *Store) evict(now time.Time) {
+	var first string
+	for key, existing := range store.entries {
+		if !now.Before(existing.expires) {
+			delete(store.entries, key)
+			continue
+		}
+		if len(first) <= 0 || existing.expires.Before(store.entries[first].expires) {
+			first = key
+		}
+	}
+	if len(store.entries) >= store.config.MaxEntries {
+		delete(store.entries, first)
+	}
+}
+
+func (store *Store) sweep() {
+	ticker := time.NewTicker(store.config.SweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-store.done:
+			return
+		case now := <-ticker.C:
+			store.mutex.Lock()
+			for key, existing := range store.entries {
+				if !now.Before(existing.expires) {
+					delete(store.entries, key)
+				}
+			}
+			store.mutex.Unlock()
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package idempotency
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBegin(t *testing.T) {
+	store := NewStore(Config{})
+	defer store.Close()
+
+	if response, err := store.Begin("key-1", "request-a"); response != nil || err != nil {
+		t.Fatalf("first request did not own the key: %v %v", response, err)
+	}
+	if _, err := store.Begin("key-1", "request-a"); !errors.Is(err, ErrInProgress) {
+		t.Errorf("unexpected error while in progress: %v", err)
+	}
+	if _, err := store.Begin("key-1", "request-b"); !errors.Is(err, ErrMismatch) {
+		t.Errorf("unexpected error for a different request: %v", err)
+	}
+
+	store.Complete("key-1", Response{Status: 201, Body: []byte("created")})
+	response, err := store.Begin("key-1", "request-a")
+	if err != nil || response == nil || response.Status != 201 || string(response.Body) != "created" {
+		t.Errorf("recorded response was not replayed: %+v %v", response, err)
+	}
+
+	store.Release("key-1")
+	if response, err := store.Begin("key-1", "request-b"); response != nil || err != nil {
+		t.Errorf("released key was not free: %v %v", response, err)
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	store := NewStore(Config{TTL: 20 * time.Millisecond, MaxEntries: 2, SweepInterval: 10 * time.Millisecond})
+	defer store.Close()
+
+	store.Begin("key-1", "request-a")
+	store.Complete("key-1", Response{Status: 200})
+	time.Sleep(50 * time.Millisecond)
+	if response, err := store.Begin("key-1", "request-b"); response != nil || err != nil {
+		t.Errorf("expired key was not free: %v %v", response, err)
+	}
+
+	//Full store makes room by dropping the key expiring first
+	store.Begin("key-2", "request-c")
+	store.Begin("key-3", "request-d")
+	store.mutex.Lock()
+	_, kept := store.entries["key-1"]
+	size := len(store.entries)
+	store.mutex.Unlock()
+	if kept || size != 2 {
+		t.Errorf("unexpected entries after eviction: key-1 kept %v, %v entries", kept, size)
+	}
+}
+
+func TestConcurrentBegin(t *testing.T) {
+	store := NewStore(Config{})
+	defer store.Close()
+
+	owners := make(chan error, 20)
+	for i := 0; i < cap(owners); i++ {
+		go func() {
+			_, err := store.Begin("key-1", "request-a")
+			owners <- err
+		}()
+	}
+	owned := 0
+	for i := 0; i < cap(owners); i++ {
+		if err := <-owners; err == nil {
+			owned++
+		} else if !errors.Is(err, ErrInProgress) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if owned != 1 {
+		t.Errorf("key owned by %v requests", owned)
+	}
+}
@@ -0,0 +1,54 @@
+package idgen
+
+import (
+	"fmt"
+)
+
+// Allocator hands out IDs for pokemons created without one. Implementations are safe for concurrent use
+// and never hand out the same ID twice.
+type Allocator interface {
+	Name() string
+	Next() (string, error)
+}
+
+// Tunes the allocators, zero values fall back to defaults
+type Config struct {
+	// File the sequence keeps its reserved numbers in, so numbering continues after a restart
+	StateFile string
+	// Put in front of sequence numbers
+	Prefix string
+	// First sequence number handed out when the state file does not exist yet
+	Start uint64
+	// Numbers reserved per write of the state file, a restart skips the unused rest of a block
+	BlockSize uint64
+}
+
+func (config Config) withDefaults() Config {
+	if len(config.StateFile) <= 0 {
+		config.StateFile = "id.sequence"
+	}
+	if len(config.Prefix) <= 0 {
+		config.Prefix = "PK"
+	}
+	if config.Start <= 0 {
+		config.Start = 10001
+	}
+	if config.BlockSize <= 0 {
+		config.BlockSize = 100
+	}
+	return config
+}
+
+// Allocator configured by name, the sequence when name is empty
+func ByName(name string, config Config) (Allocator, error) {
+	switch name {
+	case "", "sequence":
+		return NewSequence(config)
+	case "ulid":
+		return NewULID(), nil
+	case "uuidv7":
+		return NewUUIDv7(), nil
+	default:
+		return nil, fmt.Errorf("unknown ID allocator %q, expected sequence, ulid or uuidv7", name)
+	}
+}
@@ -0,0 +1,72 @@
+package idgen
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAllocatorsUniqueUnderConcurrency(t *testing.T) {
+	for _, name := range []string{"sequence", "ulid", "uuidv7"} {
+		allocator, err := ByName(name, Config{StateFile: filepath.Join(t.TempDir(), "id.sequence"), BlockSize: 100})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if allocator.Name() != name {
+			t.Errorf("unexpected allocator: got %v want %v", allocator.Name(), name)
+		}
+
+		var mutex sync.Mutex
+		var wg sync.WaitGroup
+		seen := map[string]bool{}
+		for worker := 0; worker < 8; worker++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 250; i++ {
+					id, err := allocator.Next()
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					mutex.Lock()
+					if seen[id] {
+						t.Errorf("%v: allocated %v twice", name, id)
+					}
+					seen[id] = true
+					mutex.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+func TestSequenceSurvivesRestart(t *testing.T) {
+	config := Config{StateFile: filepath.Join(t.TempDir(), "id.sequence"), Start: 1, BlockSize: 10}
+	first, err := NewSequence(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"PK1", "PK2", "PK3"} {
+		if id, err := first.Next(); err != nil || id != expected {
+			t.Fatalf("unexpected id: got %v %v want %v", id, err, expected)
+		}
+	}
+
+	//Restarting skips the rest of the reserved block instead of handing out its numbers again
+	restarted, err := NewSequence(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, err := restarted.Next(); err != nil || id != "PK11" {
+		t.Errorf("unexpected id after restart: got %v %v want PK11", id, err)
+	}
+}
+
+func TestByNameUnknown(t *testing.T) {
+	if _, err := ByName("random", Config{}); err == nil || !strings.Contains(err.Error(), "random") {
+		t.Errorf("unexpected error for unknown allocator: %v", err)
+	}
+}
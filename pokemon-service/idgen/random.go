@@ -0,0 +1,50 @@
+package idgen
+
+import (
+	"crypto/rand"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/oklog/ulid/v2"
+)
+
+// ULIDs sort by creation time, IDs created within the same millisecond increase monotonically
+type ULID struct {
+	mutex   sync.Mutex
+	entropy *ulid.MonotonicEntropy
+}
+
+func NewULID() *ULID {
+	return &ULID{entropy: ulid.Monotonic(rand.Reader, 0)}
+}
+
+func (allocator *ULID) Name() string { return "ulid" }
+
+func (allocator *ULID) Next() (string, error) {
+	//Monotonic entropy is not safe for concurrent use
+	allocator.mutex.Lock()
+	defer allocator.mutex.Unlock()
+	id, err := ulid.New(ulid.Timestamp(time.Now()), allocator.entropy)
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
+
+// Version 7 UUIDs sort by creation time like ULIDs, in the usual UUID format
+type UUIDv7 struct{}
+
+func NewUUIDv7() UUIDv7 {
+	return UUIDv7{}
+}
+
+func (UUIDv7) Name() string { return "uuidv7" }
+
+func (UUIDv7) Next() (string, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
@@ -0,0 +1,82 @@
+package idgen
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Sequence numbers IDs as Prefix followed by a counter. The highest reserved number is kept in a file before
+// any number of its block is handed out, so after a restart, even a crash, numbering continues past it.
+type Sequence struct {
+	config Config
+	mutex  sync.Mutex
+	// Next number handed out and the last one reserved in the state file
+	next     uint64
+	reserved uint64
+}
+
+// Creates a sequence continuing after the numbers reserved in the state file
+func NewSequence(config Config) (*Sequence, error) {
+	config = config.withDefaults()
+	sequence := &Sequence{config: config, next: config.Start}
+	data, err := os.ReadFile(config.StateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return sequence, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	reserved, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID sequence state in %v: %w", config.StateFile, err)
+	}
+	if reserved >= sequence.next {
+		sequence.next = reserved + 1
+	}
+	sequence.reserved = sequence.next - 1
+	return sequence, nil
+}
+
+func (sequence *Sequence) Name() string { return "sequence" }
+
+func (sequence *Sequence) Next() (string, error) {
+	sequence.mutex.Lock()
+	defer sequence.mutex.Unlock()
+
+	if sequence.next > sequence.reserved {
+		reserved := sequence.next + sequence.config.BlockSize - 1
+		if err := sequence.save(reserved); err != nil {
+			return "", err
+		}
+		sequence.reserved = reserved
+	}
+	id := sequence.config.Prefix + strconv.FormatUint(sequence.next, 10)
+	sequence.next++
+	return id, nil
+}
+
+// Replaces the state file through a rename, so a crash leaves either the old or the new state
+func (sequence *Sequence) save(reserved uint64) error {
+	temp, err := os.CreateTemp(filepath.Dir(sequence.config.StateFile), filepath.Base(sequence.config.StateFile)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.WriteString(strconv.FormatUint(reserved, 10) + "\n"); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), sequence.config.StateFile)
+}
@@ -0,0 +1,68 @@
+package index
+
+import (
+	schema "pokemon-service/schema"
+	"sync"
+)
+
+// Evolutions is the reverse of EvolvesFrom, it finds the pokemons evolving from a pokemon without
+// scanning the cache
+type Evolutions struct {
+	mutex sync.RWMutex
+	// Earlier stage of every indexed pokemon evolving from one
+	from map[string]string
+	// IDs of the pokemons evolving from each pokemon
+	to map[string]map[string]bool
+}
+
+func NewEvolutions() *Evolutions {
+	return &Evolutions{from: map[string]string{}, to: map[string]map[string]bool{}}
+}
+
+func (evolutions *Evolutions) Put(pokemon schema.Pokemon) {
+	evolutions.mutex.Lock()
+	defer evolutions.mutex.Unlock()
+	evolutions.remove(pokemon.Id)
+	if pokemon.EvolvesFrom == nil || len(pokemon.EvolvesFrom.Id) <= 0 {
+		return
+	}
+	from := pokemon.EvolvesFrom.Id
+	evolutions.from[pokemon.Id] = from
+	if evolutions.to[from] == nil {
+		evolutions.to[from] = map[string]bool{}
+	}
+	evolutions.to[from][pokemon.Id] = true
+}
+
+func (evolutions *Evolutions) Remove(pokemon schema.Pokemon) {
+	evolutions.mutex.Lock()
+	defer evolutions.mutex.Unlock()
+	evolutions.remove(pokemon.Id)
+}
+
+func (evolutions *Evolutions) Reset() {
+	evolutions.mutex.Lock()
+	defer evolutions.mutex.Unlock()
+	evolutions.from = map[string]string{}
+	evolutions.to = map[string]map[string]bool{}
+}
+
+// IDs of the pokemons evolving from the pokemon with the given ID, sorted
+func (evolutions *Evolutions) EvolvesTo(id string) []string {
+	evolutions.mutex.RLock()
+	defer evolutions.mutex.RUnlock()
+	return sortedIds(evolutions.to[id])
+}
+
+// Called with the mutex held
+func (evolutions *Evolutions) remove(id string) {
+	from, ok := evolutions.from[id]
+	if !ok {
+		return
+	}
+	delete(evolutions.from, id)
+	delete(evolutions.to[from], id)
+	if len(evolutions.to[from]) <= 0 {
+		delete(evolutions.to, from)
+	}
+}
@@ -0,0 +1,31 @@
+package index
+
+import (
+	"fmt"
+	schema "pokemon-service/schema"
+	"testing"
+)
+
+func TestEvolutions(t *testing.T) {
+	evolutions := NewEvolutions()
+	evolutions.Put(schema.Pokemon{Id: "PK1", Name: "Eevee"})
+	evolutions.Put(schema.Pokemon{Id: "PK3", Name: "Jolteon", EvolvesFrom: &schema.Evolution{Id: "PK1"}})
+	evolutions.Put(schema.Pokemon{Id: "PK2", Name: "Vaporeon", EvolvesFrom: &schema.Evolution{Id: "PK1"}})
+	if later := evolutions.EvolvesTo("PK1"); fmt.Sprint(later) != "[PK2 PK3]" {
+		t.Errorf("unexpected later stages: %v", later)
+	}
+
+	//Relinking moves the pokemon to its new earlier stage
+	evolutions.Put(schema.Pokemon{Id: "PK3", Name: "Jolteon", EvolvesFrom: &schema.Evolution{Id: "PK2"}})
+	evolutions.Remove(schema.Pokemon{Id: "PK2"})
+	if later := evolutions.EvolvesTo("PK1"); len(later) != 0 {
+		t.Errorf("unexpected later stages after removal: %v", later)
+	}
+	if later := evolutions.EvolvesTo("PK2"); fmt.Sprint(later) != "[PK3]" {
+		t.Errorf("unexpected later stages after relinking: %v", later)
+	}
+	evolutions.Reset()
+	if later := evolutions.EvolvesTo("PK2"); len(later) != 0 {
+		t.Errorf("unexpected later stages after reset: %v", later)
+	}
+}
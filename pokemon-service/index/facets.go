@@ -0,0 +1,144 @@
+package index
+
+import (
+	schema "pokemon-service/schema"
+	"strings"
+	"sync"
+)
+
+// Types of a pokemon, dual types are written like "Grass/Poison"
+func Types(pokemon schema.Pokemon) []string {
+	return values(pokemon.Type, "/,")
+}
+
+// Abilities of a pokemon, several are written like "Eat&Sleep"
+func Abilities(pokemon schema.Pokemon) []string {
+	return values(pokemon.Abilities, "&,")
+}
+
+// Inverted index from the normalized values of one field to the pokemons having them
+type inverted struct {
+	ids map[string]map[string]bool
+	// Spelling the value was last indexed with, reported in counts
+	labels map[string]string
+}
+
+func newInverted() inverted {
+	return inverted{ids: map[string]map[string]bool{}, labels: map[string]string{}}
+}
+
+func (inverted inverted) add(id string, values []string) {
+	for _, value := range values {
+		key := Normalize(value)
+		if inverted.ids[key] == nil {
+			inverted.ids[key] = map[string]bool{}
+		}
+		inverted.ids[key][id] = true
+		inverted.labels[key] = value
+	}
+}
+
+func (inverted inverted) remove(id string, values []string) {
+	for _, value := range values {
+		key := Normalize(value)
+		delete(inverted.ids[key], id)
+		if len(inverted.ids[key]) <= 0 {
+			delete(inverted.ids, key)
+			delete(inverted.labels, key)
+		}
+	}
+}
+
+func (inverted inverted) counts() map[string]int {
+	counts := make(map[string]int, len(inverted.ids))
+	for key, ids := range inverted.ids {
+		counts[inverted.labels[key]] = len(ids)
+	}
+	return counts
+}
+
+// Facets keeps inverted indexes on Type and Abilities, so pokemons of a type or with an ability are found
+// without scanning the cache. Values are compared normalized like names.
+type Facets struct {
+	mutex     sync.RWMutex
+	indexed   map[string]schema.Pokemon
+	types     inverted
+	abilities inverted
+}
+
+func NewFacets() *Facets {
+	return &Facets{indexed: map[string]schema.Pokemon{}, types: newInverted(), abilities: newInverted()}
+}
+
+func (facets *Facets) Put(pokemon schema.Pokemon) {
+	facets.mutex.Lock()
+	defer facets.mutex.Unlock()
+	facets.remove(pokemon.Id)
+	facets.indexed[pokemon.Id] = pokemon
+	facets.types.add(pokemon.Id, Types(pokemon))
+	facets.abilities.add(pokemon.Id, Abilities(pokemon))
+}
+
+func (facets *Facets) Remove(pokemon schema.Pokemon) {
+	facets.mutex.Lock()
+	defer facets.mutex.Unlock()
+	facets.remove(pokemon.Id)
+}
+
+func (facets *Facets) Reset() {
+	facets.mutex.Lock()
+	defer facets.mutex.Unlock()
+	facets.indexed = map[string]schema.Pokemon{}
+	facets.types = newInverted()
+	facets.abilities = newInverted()
+}
+
+// IDs of the pokemons of pokemonType having ability, sorted. An empty ability does not narrow the result.
+func (facets *Facets) Members(pokemonType string, ability string) []string {
+	facets.mutex.RLock()
+	defer facets.mutex.RUnlock()
+	members := facets.types.ids[Normalize(pokemonType)]
+	if len(ability) <= 0 {
+		return sortedIds(members)
+	}
+	withAbility := facets.abilities.ids[Normalize(ability)]
+	both := map[string]bool{}
+	for id := range members {
+		if withAbility[id] {
+			both[id] = true
+		}
+	}
+	return sortedIds(both)
+}
+
+// Pokemons per type and per ability
+func (facets *Facets) Counts() schema.FacetCounts {
+	facets.mutex.RLock()
+	defer facets.mutex.RUnlock()
+	return schema.FacetCounts{Pokemons: len(facets.indexed), Types: facets.types.counts(), Abilities: facets.abilities.counts()}
+}
+
+// Called with the mutex held
+func (facets *Facets) remove(id string) {
+	previous, ok := facets.indexed[id]
+	if !ok {
+		return
+	}
+	delete(facets.indexed, id)
+	facets.types.remove(id, Types(previous))
+	facets.abilities.remove(id, Abilities(previous))
+}
+
+// Non blank values of field separated by any of separators, each listed once
+func values(field string, separators string) []string {
+	var found []string
+	seen := map[string]bool{}
+	for _, value := range strings.FieldsFunc(field, func(r rune) bool { return strings.ContainsRune(separators, r) }) {
+		value = strings.TrimSpace(value)
+		if key := Normalize(value); len(key) > 0 && !seen[key] {
+			seen[key] = true
+			found = append(found, value)
+		}
+	}
+	return found
+}
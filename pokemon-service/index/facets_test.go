@@ -0,0 +1,42 @@
+package index
+
+import (
+	"fmt"
+	schema "pokemon-service/schema"
+	"testing"
+)
+
+func TestFacets(t *testing.T) {
+	facets := NewFacets()
+	facets.Put(schema.Pokemon{Id: "PK1", Name: "Bulbasaur", Type: "Grass/Poison", Abilities: "Overgrow&Chlorophyll"})
+	facets.Put(schema.Pokemon{Id: "PK2", Name: "Oddish", Type: "grass / poison", Abilities: "Chlorophyll"})
+	facets.Put(schema.Pokemon{Id: "PK3", Name: "Charmander", Type: "Fire", Abilities: "Blaze"})
+
+	inputs := []struct {
+		testName    string
+		pokemonType string
+		ability     string
+		expected    []string
+	}{
+		{testName: "TestMembersDualType", pokemonType: "POISON", expected: []string{"PK1", "PK2"}},
+		{testName: "TestMembersWithAbility", pokemonType: "Grass", ability: "overgrow", expected: []string{"PK1"}},
+		{testName: "TestMembersUnknownType", pokemonType: "Water", expected: []string{}},
+	}
+	for _, item := range inputs {
+		if members := facets.Members(item.pokemonType, item.ability); fmt.Sprint(members) != fmt.Sprint(item.expected) {
+			t.Errorf("%v: unexpected members: got %v want %v", item.testName, members, item.expected)
+		}
+	}
+
+	//Updates move the pokemon between types
+	facets.Put(schema.Pokemon{Id: "PK2", Name: "Oddish", Type: "Fire", Abilities: "Blaze"})
+	facets.Remove(schema.Pokemon{Id: "PK1"})
+	counts := facets.Counts()
+	if counts.Pokemons != 2 || len(counts.Types) != 1 || counts.Types["Fire"] != 2 || len(counts.Abilities) != 1 || counts.Abilities["Blaze"] != 2 {
+		t.Errorf("unexpected counts: %+v", counts)
+	}
+	facets.Reset()
+	if counts := facets.Counts(); counts.Pokemons != 0 || len(counts.Types) != 0 {
+		t.Errorf("unexpected counts after reset: %+v", counts)
+	}
+}
@@ -0,0 +1,41 @@
+package index
+
+import (
+	schema "pokemon-service/schema"
+	"sync"
+)
+
+// Members is the set of IDs of the stored pokemons, it counts the records without scanning the cache
+type Members struct {
+	mutex sync.RWMutex
+	ids   map[string]bool
+}
+
+func NewMembers() *Members {
+	return &Members{ids: map[string]bool{}}
+}
+
+func (members *Members) Put(pokemon schema.Pokemon) {
+	members.mutex.Lock()
+	defer members.mutex.Unlock()
+	members.ids[pokemon.Id] = true
+}
+
+func (members *Members) Remove(pokemon schema.Pokemon) {
+	members.mutex.Lock()
+	defer members.mutex.Unlock()
+	delete(members.ids, pokemon.Id)
+}
+
+func (members *Members) Reset() {
+	members.mutex.Lock()
+	defer members.mutex.Unlock()
+	members.ids = map[string]bool{}
+}
+
+// Number of indexed pokemons
+func (members *Members) Len() int {
+	members.mutex.RLock()
+	defer members.mutex.RUnlock()
+	return len(members.ids)
+}
@@ -0,0 +1,190 @@
+package index
+
+import (
+	schema "pokemon-service/schema"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Match of a name search, ranked exact matches first, then names the query is a prefix of, then names
+// within a few edits of the query
+type Match struct {
+	Id string
+	// Edits turning the query into the name, 0 for exact and prefix matches
+	Distance int
+	Prefix   bool
+}
+
+// Names indexes pokemons by their normalized name for lookups that ignore case, accents and whitespace
+// and for fuzzy search
+type Names struct {
+	mutex sync.RWMutex
+	// Normalized name of every indexed pokemon by ID
+	byId map[string]string
+	// IDs of the pokemons carrying each normalized name, different names can normalize to the same one
+	byName map[string]map[string]bool
+}
+
+func NewNames() *Names {
+	return &Names{byId: map[string]string{}, byName: map[string]map[string]bool{}}
+}
+
+func (names *Names) Put(pokemon schema.Pokemon) {
+	names.mutex.Lock()
+	defer names.mutex.Unlock()
+	names.remove(pokemon.Id)
+	name := Normalize(pokemon.Name)
+	if len(name) <= 0 {
+		return
+	}
+	names.byId[pokemon.Id] = name
+	if names.byName[name] == nil {
+		names.byName[name] = map[string]bool{}
+	}
+	names.byName[name][pokemon.Id] = true
+}
+
+func (names *Names) Remove(pokemon schema.Pokemon) {
+	names.mutex.Lock()
+	defer names.mutex.Unlock()
+	names.remove(pokemon.Id)
+}
+
+func (names *Names) Reset() {
+	names.mutex.Lock()
+	defer names.mutex.Unlock()
+	names.byId = map[string]string{}
+	names.byName = map[string]map[string]bool{}
+}
+
+// IDs of the pokemons whose name normalizes to the same as name, sorted
+func (names *Names) Lookup(name string) []string {
+	names.mutex.RLock()
+	defer names.mutex.RUnlock()
+	return sortedIds(names.byName[Normalize(name)])
+}
+
+// Best matches for query, at most limit of them
+func (names *Names) Search(query string, limit int) []Match {
+	query = Normalize(query)
+	if len(query) <= 0 || limit <= 0 {
+		return nil
+	}
+	maxDistance := fuzziness(query)
+
+	type ranked struct {
+		Match
+		name string
+	}
+	var candidates []ranked
+	names.mutex.RLock()
+	for name, ids := range names.byName {
+		match := Match{Prefix: strings.HasPrefix(name, query)}
+		if !match.Prefix {
+			//Lengths alone tell the distance exceeds the limit, spares computing it for most names
+			if abs(utf8.RuneCountInString(name)-utf8.RuneCountInString(query)) > maxDistance {
+				continue
+			}
+			match.Distance = distance(query, name)
+			if match.Distance > maxDistance {
+				continue
+			}
+		}
+		for id := range ids {
+			match.Id = id
+			candidates = append(candidates, ranked{Match: match, name: name})
+		}
+	}
+	names.mutex.RUnlock()
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if exactA, exactB := a.name == query, b.name == query; exactA != exactB {
+			return exactA
+		}
+		if a.Prefix != b.Prefix {
+			return a.Prefix
+		}
+		if a.Distance != b.Distance {
+			return a.Distance < b.Distance
+		}
+		//Among prefix matches the shortest name is the closest completion
+		if len(a.name) != len(b.name) {
+			return len(a.name) < len(b.name)
+		}
+		if a.name != b.name {
+			return a.name < b.name
+		}
+		return a.Id < b.Id
+	})
+	matches := make([]Match, 0, min(limit, len(candidates)))
+	for _, candidate := range candidates[:min(limit, len(candidates))] {
+		matches = append(matches, candidate.Match)
+	}
+	return matches
+}
+
+// Called with the mutex held
+func (names *Names) remove(id string) {
+	name, ok := names.byId[id]
+	if !ok {
+		return
+	}
+	delete(names.byId, id)
+	delete(names.byName[name], id)
+	if len(names.byName[name]) <= 0 {
+		delete(names.byName, name)
+	}
+}
+
+// Edits a query may be away from a name and still match it, short queries have to be spelt right
+func fuzziness(query string) int {
+	switch length := utf8.RuneCountInString(query); {
+	case length <= 2:
+		return 0
+	case length <= 5:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Levenshtein distance between a and b counted in runes
+func distance(a string, b string) int {
+	source, target := []rune(a), []rune(b)
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(source); i++ {
+		current[0] = i
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(target)]
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+func sortedIds(set map[string]bool) []string {
+	ids := make([]string, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,85 @@
+package index
+
+import (
+	schema "pokemon-service/schema"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	inputs := []struct {
+		name     string
+		expected string
+	}{
+		{name: "Chespin", expected: "chespin"},
+		{name: "  Mr.   Mime ", expected: "mr. mime"},
+		{name: "Flabébé", expected: "flabebe"},
+		{name: "ＰＩＫＡＣＨＵ", expected: "pikachu"},
+		{name: "Straße", expected: "strasse"},
+	}
+	for _, item := range inputs {
+		if normalized := Normalize(item.name); normalized != item.expected {
+			t.Errorf("unexpected normalized name for %q: got %q want %q", item.name, normalized, item.expected)
+		}
+	}
+}
+
+func TestNamesSearch(t *testing.T) {
+	names := NewNames()
+	for _, pokemon := range []schema.Pokemon{
+		{Id: "PK1", Name: "Chespin"},
+		{Id: "PK2", Name: "Chesnaught"},
+		{Id: "PK3", Name: "Charmander"},
+		{Id: "PK4", Name: "Ches"},
+		{Id: "PK5", Name: "Mew"},
+	} {
+		names.Put(pokemon)
+	}
+
+	inputs := []struct {
+		testName string
+		query    string
+		limit    int
+		expected []string
+	}{
+		{testName: "TestSearchExactThenPrefix", query: "ches", limit: 10, expected: []string{"PK4", "PK1", "PK2"}},
+		{testName: "TestSearchTypo", query: "Chespn", limit: 10, expected: []string{"PK1", "PK4"}},
+		{testName: "TestSearchLimit", query: "CHES", limit: 2, expected: []string{"PK4", "PK1"}},
+		{testName: "TestSearchShortQueryExact", query: "me", limit: 10, expected: []string{"PK5"}},
+		{testName: "TestSearchNoMatch", query: "Bulbasaur", limit: 10, expected: []string{}},
+	}
+	for _, item := range inputs {
+		matches := names.Search(item.query, item.limit)
+		if len(matches) != len(item.expected) {
+			t.Errorf("%v: unexpected matches: got %+v want %v", item.testName, matches, item.expected)
+			continue
+		}
+		for i, id := range item.expected {
+			if matches[i].Id != id {
+				t.Errorf("%v: unexpected match %v: got %+v want %v", item.testName, i, matches[i], id)
+			}
+		}
+	}
+}
+
+func TestNamesFollowWrites(t *testing.T) {
+	names := NewNames()
+	names.Put(schema.Pokemon{Id: "PK1", Name: "Chespin"})
+	names.Put(schema.Pokemon{Id: "PK2", Name: "chespin"})
+	if ids := names.Lookup(" CHESPIN "); len(ids) != 2 || ids[0] != "PK1" {
+		t.Errorf("unexpected lookup: %v", ids)
+	}
+
+	//Renaming moves the record to its new name
+	names.Put(schema.Pokemon{Id: "PK1", Name: "Quilladin"})
+	if ids := names.Lookup("chespin"); len(ids) != 1 || ids[0] != "PK2" {
+		t.Errorf("unexpected lookup after rename: %v", ids)
+	}
+	names.Remove(schema.Pokemon{Id: "PK2"})
+	if ids := names.Lookup("chespin"); len(ids) != 0 {
+		t.Errorf("unexpected lookup after remove: %v", ids)
+	}
+	names.Reset()
+	if ids := names.Lookup("quilladin"); len(ids) != 0 {
+		t.Errorf("unexpected lookup after reset: %v", ids)
+	}
+}
@@ -0,0 +1,22 @@
+package index
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Form names are compared in: compatibility characters and accents are taken apart and the accents dropped,
+// case is folded and runs of whitespace become a single space. "  Flabébé " and "FLABEBE" compare equal.
+func Normalize(name string) string {
+	var builder strings.Builder
+	for _, r := range norm.NFKD.String(name) {
+		if !unicode.Is(unicode.Mn, r) {
+			builder.WriteRune(r)
+		}
+	}
+	//A Caser keeps state between calls, so it cannot be shared by concurrent lookups
+	return strings.Join(strings.Fields(cases.Fold().String(builder.String())), " ")
+}
@@ -0,0 +1,168 @@
+package index
+
+import (
+	schema "pokemon-service/schema"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+type trieNode struct {
+	children map[rune]*trieNode
+	// Pokemons whose normalized name ends at this node
+	ids map[string]bool
+}
+
+type trieEntry struct {
+	pokemon schema.Pokemon
+	// Normalized name, the path of the entry in the trie
+	key  string
+	hits atomic.Uint64
+}
+
+// Trie indexes pokemons by the characters of their normalized name, so every name starting with a prefix
+// is found by walking down the prefix alone. It counts lookups per pokemon to rank suggestions by popularity.
+type Trie struct {
+	mutex   sync.RWMutex
+	root    *trieNode
+	entries map[string]*trieEntry
+}
+
+func NewTrie() *Trie {
+	return &Trie{root: &trieNode{}, entries: map[string]*trieEntry{}}
+}
+
+func (trie *Trie) Put(pokemon schema.Pokemon) {
+	trie.mutex.Lock()
+	defer trie.mutex.Unlock()
+	key := Normalize(pokemon.Name)
+	//Updates keep the popularity gathered so far
+	entry, ok := trie.entries[pokemon.Id]
+	if ok {
+		trie.unlink(entry)
+	} else {
+		entry = &trieEntry{}
+	}
+	entry.pokemon, entry.key = pokemon, key
+	if len(key) <= 0 {
+		delete(trie.entries, pokemon.Id)
+		return
+	}
+	trie.entries[pokemon.Id] = entry
+
+	node := trie.root
+	for _, r := range key {
+		if node.children == nil {
+			node.children = map[rune]*trieNode{}
+		}
+		child, ok := node.children[r]
+		if !ok {
+			child = &trieNode{}
+			node.children[r] = child
+		}
+		node = child
+	}
+	if node.ids == nil {
+		node.ids = map[string]bool{}
+	}
+	node.ids[pokemon.Id] = true
+}
+
+func (trie *Trie) Remove(pokemon schema.Pokemon) {
+	trie.mutex.Lock()
+	defer trie.mutex.Unlock()
+	if entry, ok := trie.entries[pokemon.Id]; ok {
+		trie.unlink(entry)
+		delete(trie.entries, pokemon.Id)
+	}
+}
+
+func (trie *Trie) Reset() {
+	trie.mutex.Lock()
+	defer trie.mutex.Unlock()
+	trie.root = &trieNode{}
+	trie.entries = map[string]*trieEntry{}
+}
+
+// Counts a lookup of the pokemon with the given ID, pokemons that are not indexed are ignored
+func (trie *Trie) Hit(id string) {
+	trie.mutex.RLock()
+	defer trie.mutex.RUnlock()
+	if entry, ok := trie.entries[id]; ok {
+		entry.hits.Add(1)
+	}
+}
+
+// Pokemons whose normalized name starts with the normalized prefix, most looked up first and at most limit
+// of them. Ties go to the shorter name, the closest completion.
+func (trie *Trie) Suggest(prefix string, limit int) []schema.Suggestion {
+	if limit <= 0 {
+		return nil
+	}
+	trie.mutex.RLock()
+	node := trie.root
+	for _, r := range Normalize(prefix) {
+		if node = node.children[r]; node == nil {
+			trie.mutex.RUnlock()
+			return []schema.Suggestion{}
+		}
+	}
+	type ranked struct {
+		schema.Suggestion
+		key string
+	}
+	var candidates []ranked
+	stack := []*trieNode{node}
+	for len(stack) > 0 {
+		node, stack = stack[len(stack)-1], stack[:len(stack)-1]
+		for id := range node.ids {
+			entry := trie.entries[id]
+			candidates = append(candidates, ranked{Suggestion: schema.Suggestion{Id: id, Name: entry.pokemon.Name, Hits: entry.hits.Load()}, key: entry.key})
+		}
+		for _, child := range node.children {
+			stack = append(stack, child)
+		}
+	}
+	trie.mutex.RUnlock()
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Hits != b.Hits {
+			return a.Hits > b.Hits
+		}
+		if len(a.key) != len(b.key) {
+			return len(a.key) < len(b.key)
+		}
+		if a.key != b.key {
+			return a.key < b.key
+		}
+		return a.Id < b.Id
+	})
+	suggestions := make([]schema.Suggestion, 0, min(limit, len(candidates)))
+	for _, candidate := range candidates[:min(limit, len(candidates))] {
+		suggestions = append(suggestions, candidate.Suggestion)
+	}
+	return suggestions
+}
+
+// Takes entry out of the node its name ends at and prunes the nodes left without names below them.
+// Called with the mutex held.
+func (trie *Trie) unlink(entry *trieEntry) {
+	path := []*trieNode{trie.root}
+	runes := []rune(entry.key)
+	for _, r := range runes {
+		next := path[len(path)-1].children[r]
+		if next == nil {
+			return
+		}
+		path = append(path, next)
+	}
+	delete(path[len(path)-1].ids, entry.pokemon.Id)
+	for i := len(runes); i > 0; i-- {
+		node := path[i]
+		if len(node.ids) > 0 || len(node.children) > 0 {
+			return
+		}
+		delete(path[i-1].children, runes[i-1])
+	}
+}
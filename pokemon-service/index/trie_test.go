@@ -0,0 +1,72 @@
+package index
+
+import (
+	schema "pokemon-service/schema"
+	"testing"
+)
+
+func TestTrieSuggest(t *testing.T) {
+	trie := NewTrie()
+	for _, pokemon := range []schema.Pokemon{
+		{Id: "PK1", Name: "Chespin"},
+		{Id: "PK2", Name: "Chesnaught"},
+		{Id: "PK3", Name: "Charmander"},
+		{Id: "PK4", Name: "Ches"},
+		{Id: "PK5", Name: "Mew"},
+	} {
+		trie.Put(pokemon)
+	}
+	trie.Hit("PK2")
+	trie.Hit("PK2")
+	trie.Hit("PK3")
+	trie.Hit("PK9")
+
+	inputs := []struct {
+		testName string
+		prefix   string
+		limit    int
+		expected []string
+	}{
+		{testName: "TestSuggestPopularFirst", prefix: "ches", limit: 10, expected: []string{"PK2", "PK4", "PK1"}},
+		{testName: "TestSuggestNormalizedPrefix", prefix: " CH", limit: 10, expected: []string{"PK2", "PK3", "PK4", "PK1"}},
+		{testName: "TestSuggestLimit", prefix: "c", limit: 2, expected: []string{"PK2", "PK3"}},
+		{testName: "TestSuggestEmptyPrefix", prefix: "", limit: 1, expected: []string{"PK2"}},
+		{testName: "TestSuggestNoMatch", prefix: "z", limit: 10, expected: []string{}},
+	}
+	for _, item := range inputs {
+		suggestions := trie.Suggest(item.prefix, item.limit)
+		if len(suggestions) != len(item.expected) {
+			t.Errorf("%v: unexpected suggestions: got %+v want %v", item.testName, suggestions, item.expected)
+			continue
+		}
+		for i, id := range item.expected {
+			if suggestions[i].Id != id {
+				t.Errorf("%v: unexpected suggestion %v: got %+v want %v", item.testName, i, suggestions[i], id)
+			}
+		}
+	}
+}
+
+func TestTrieFollowsWrites(t *testing.T) {
+	trie := NewTrie()
+	trie.Put(schema.Pokemon{Id: "PK1", Name: "Chespin"})
+	trie.Hit("PK1")
+
+	//Renaming keeps the popularity and moves the record to its new name
+	trie.Put(schema.Pokemon{Id: "PK1", Name: "Quilladin"})
+	if suggestions := trie.Suggest("ches", 10); len(suggestions) != 0 {
+		t.Errorf("old name still suggested: %+v", suggestions)
+	}
+	if suggestions := trie.Suggest("quil", 10); len(suggestions) != 1 || suggestions[0].Hits != 1 || suggestions[0].Name != "Quilladin" {
+		t.Errorf("unexpected suggestions after rename: %+v", suggestions)
+	}
+
+	trie.Remove(schema.Pokemon{Id: "PK1"})
+	if suggestions := trie.Suggest("", 10); len(suggestions) != 0 || len(trie.root.children) != 0 {
+		t.Errorf("removed record left suggestions or nodes behind: %+v", suggestions)
+	}
+	trie.Put(schema.Pokemon{Id: "PK1", Name: "Quilladin"})
+	if suggestions := trie.Suggest("q", 10); len(suggestions) != 1 || suggestions[0].Hits != 0 {
+		t.Errorf("popularity survived removal: %+v", suggestions)
+	}
+}
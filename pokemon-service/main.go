@@ -2,25 +2,60 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	audit "pokemon-service/audit"
+	auth "pokemon-service/auth"
+	codec "pokemon-service/codec"
+	events "pokemon-service/events"
+	eviction "pokemon-service/eviction"
+	graphqlapi "pokemon-service/graphqlapi"
+	grpcserver "pokemon-service/grpcserver"
 	handlers "pokemon-service/handlers"
+	history "pokemon-service/history"
+	idempotency "pokemon-service/idempotency"
+	idgen "pokemon-service/idgen"
+	index "pokemon-service/index"
+	matchup "pokemon-service/matchup"
 	middlewares "pokemon-service/middlewares"
+	openapi "pokemon-service/openapi"
 	s "pokemon-service/schema"
+	store "pokemon-service/store"
+	trash "pokemon-service/trash"
+	watch "pokemon-service/watch"
+	webhooks "pokemon-service/webhooks"
+	"strings"
 	"syscall"
 	"time"
 
-	"github.com/allegro/bigcache"
+	"github.com/allegro/bigcache/v3"
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/gorilla/mux"
 )
 
 var (
 	loggerFileName = "logger.text"
-	logger         = s.Logger{}
+	// Keeps the numbers reserved by the sequence ID allocator across restarts
+	idSequenceFileName = "id.sequence"
+	eventLogSize       = 1000
+	grpcAddr           = "127.0.0.1:9000"
+	// Response bodies shorter than this are sent uncompressed
+	compressMinSize = 1024
+	// Deleted pokemons can be restored for this long before they are purged
+	trashRetention = 7 * 24 * time.Hour
+	// Revisions kept per pokemon for reads at an earlier time and reverts
+	revisionsKept = 10
+	// Audit log of every change, appended to as NDJSON and read back on start. Other tenants than the default
+	// one keep theirs next to it, named after the tenant.
+	auditFileName = "audit.ndjson"
+	// The v1 routes stay as a shim for the v2 resource API until they are removed at legacySunset
+	legacyDeprecatedAt = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	legacySunset       = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+	logger             = s.Logger{}
 )
 
 // Logging every transaction details in logger file for observing ongoing traffic
@@ -41,29 +76,142 @@ func init() {
 func main() {
 	r := mux.NewRouter()
 	//cache, _ := bigcache.NewBigCache(bigcache.DefaultConfig(24 * time.Hour))
-	cache, err := customerConfigBigCache()
+	// Format of the records stored in cache, set through STORAGE_CODEC env variable: json, gob, msgpack or binary
+	records, err := codec.RecordCodecByName(os.Getenv("STORAGE_CODEC"))
 	if err != nil {
-		log.Fatal("Unable to load cache data:", err.Error())
+		log.Fatal("Unable to configure storage codec:", err.Error())
 	}
-	loadingInMemCache(cache)
-	service := &handlers.Service{Cache: cache, Logger: &logger}
+	// Keys callers identify themselves with, set through API_KEYS env variable as actor:key pairs separated by commas.
+	// Actors written as actor@tenant act in that tenant.
+	keys, err := auth.ParseKeys(os.Getenv("API_KEYS"))
+	if err != nil {
+		log.Fatal("Unable to configure API keys:", err.Error())
+	}
+	// Tenants sharing the deployment, each with a catalog of its own, set through TENANTS env variable as
+	// name:quota pairs separated by commas. The default tenant always exists.
+	quotas, err := auth.ParseTenants(os.Getenv("TENANTS"))
+	if err != nil {
+		log.Fatal("Unable to configure tenants:", err.Error())
+	}
+	for _, tenant := range keys.Tenants() {
+		if _, ok := quotas[tenant]; !ok {
+			log.Fatal("API keys act in tenant " + tenant + " which is not listed in TENANTS")
+		}
+	}
+	// Allocator of IDs for pokemons added without one, set through ID_ALLOCATOR env variable: sequence, ulid or uuidv7.
+	// Shared by the tenants, every catalog skips the IDs it already holds.
+	ids, err := idgen.ByName(os.Getenv("ID_ALLOCATOR"), idgen.Config{StateFile: idSequenceFileName})
+	if err != nil {
+		log.Fatal("Unable to configure ID allocator:", err.Error())
+	}
+	// Type effectiveness chart read from the JSON file TYPE_CHART names, the standard chart when it is not set
+	chart := matchup.DefaultChart()
+	if path := os.Getenv("TYPE_CHART"); len(path) > 0 {
+		chart, err = matchup.ReadChart(path)
+		if err != nil {
+			log.Fatal("Unable to load type chart:", err.Error())
+		}
+	}
+	idempotencyKeys := idempotency.NewStore(idempotency.Config{})
+	spec, err := openapi.Spec()
+	if err != nil {
+		log.Fatal("Unable to build OpenAPI document:", err.Error())
+	}
+	validator, err := openapi.NewValidator(spec)
+	if err != nil {
+		log.Fatal("Unable to build request validator:", err.Error())
+	}
+	services := map[string]*handlers.Service{}
+	for name, quota := range quotas {
+		services[name], err = newTenantService(name, quota, records, ids, chart, spec)
+		if err != nil {
+			log.Fatal("Unable to build tenant "+name+":", err.Error())
+		}
+	}
+	service := services[auth.DefaultTenant]
+	tenants := handlers.NewTenants(services)
 
 	commonMiddleware := []middlewares.Middleware{
 		middlewares.LoggingRequest,
 		middlewares.LoggingResponse,
+		// Outside the response logger, which records the body before it is compressed
+		middlewares.Compress(compressMinSize),
 	}
+	// Requests not matching the OpenAPI document are rejected before they reach the handler.
+	// Callers are identified by their API key first, so changes are recorded under their actor.
+	validatedMiddleware := append([]middlewares.Middleware{middlewares.ValidateRequest(validator), middlewares.Identify(keys)}, commonMiddleware...)
+	// Pokemon routes answer in the media type the Accept header asks for
+	// and mutating requests carrying an Idempotency-Key can be retried safely
+	pokemonMiddleware := append([]middlewares.Middleware{middlewares.Negotiate, middlewares.Idempotent(idempotencyKeys)}, validatedMiddleware...)
 	r.HandleFunc("/health-check", middlewares.Chain(service.HealthCheckHandler, logger, commonMiddleware...)).Methods("GET")
-	r.HandleFunc("/pokemon-service/getByID/{Id}", middlewares.Chain(service.GetByID, logger, commonMiddleware...)).Methods("GET")
-	r.HandleFunc("/pokemon-service/getByName/{Name}", middlewares.Chain(service.GetByName, logger, commonMiddleware...)).Methods("GET")
-	r.HandleFunc("/pokemon-service/{Id}", middlewares.Chain(service.DeleteByID, logger, commonMiddleware...)).Methods("DELETE")
-	r.HandleFunc("/pokemon-service/Add", middlewares.Chain(service.AddPokemon, logger, commonMiddleware...)).Methods("POST")
+	r.HandleFunc("/openapi.json", middlewares.Chain(service.OpenAPISpec, logger, commonMiddleware...)).Methods("GET")
+	r.HandleFunc("/docs", middlewares.Chain(service.OpenAPIDocs, logger, commonMiddleware...)).Methods("GET")
+	r.HandleFunc("/v2/pokemon", middlewares.Chain(tenants.Serve((*handlers.Service).ListPokemon), logger, pokemonMiddleware...)).Methods("GET")
+	r.HandleFunc("/v2/pokemon", middlewares.Chain(tenants.Serve((*handlers.Service).CreatePokemon), logger, pokemonMiddleware...)).Methods("POST")
+	r.HandleFunc("/v2/pokemon/{id}", middlewares.Chain(tenants.Serve((*handlers.Service).GetPokemon), logger, pokemonMiddleware...)).Methods("GET")
+	r.HandleFunc("/v2/pokemon/{id}", middlewares.Chain(tenants.Serve((*handlers.Service).ReplacePokemon), logger, pokemonMiddleware...)).Methods("PUT")
+	r.HandleFunc("/v2/pokemon/{id}", middlewares.Chain(tenants.Serve((*handlers.Service).PatchPokemon), logger, pokemonMiddleware...)).Methods("PATCH")
+	r.HandleFunc("/v2/pokemon/{id}", middlewares.Chain(tenants.Serve((*handlers.Service).DeletePokemon), logger, pokemonMiddleware...)).Methods("DELETE")
+	r.HandleFunc("/v2/pokemon/{id}/evolutions", middlewares.Chain(tenants.Serve((*handlers.Service).GetEvolutions), logger, validatedMiddleware...)).Methods("GET")
+	r.HandleFunc("/v2/pokemon/{id}/history", middlewares.Chain(tenants.Serve((*handlers.Service).GetHistory), logger, validatedMiddleware...)).Methods("GET")
+	r.HandleFunc("/v2/pokemon/{id}/revert", middlewares.Chain(tenants.Serve((*handlers.Service).RevertPokemon), logger, pokemonMiddleware...)).Methods("POST")
+	r.HandleFunc("/pokemon-service/batchGet", middlewares.Chain(tenants.Serve((*handlers.Service).BatchGet), logger, pokemonMiddleware...)).Methods("POST")
+	r.HandleFunc("/pokemon-service/search", middlewares.Chain(tenants.Serve((*handlers.Service).SearchPokemon), logger, pokemonMiddleware...)).Methods("GET")
+	r.HandleFunc("/pokemon-service/suggest", middlewares.Chain(tenants.Serve((*handlers.Service).SuggestPokemon), logger, validatedMiddleware...)).Methods("GET")
+	r.HandleFunc("/pokemon-service/types/{type}", middlewares.Chain(tenants.Serve((*handlers.Service).ListByType), logger, pokemonMiddleware...)).Methods("GET")
+	r.HandleFunc("/pokemon-service/facets", middlewares.Chain(tenants.Serve((*handlers.Service).FacetCounts), logger, validatedMiddleware...)).Methods("GET")
+	r.HandleFunc("/pokemon-service/matchup", middlewares.Chain(tenants.Serve((*handlers.Service).MatchPokemon), logger, validatedMiddleware...)).Methods("POST")
+	r.HandleFunc("/pokemon-service/trash", middlewares.Chain(tenants.Serve((*handlers.Service).ListTrash), logger, validatedMiddleware...)).Methods("GET")
+	r.HandleFunc("/pokemon-service/{Id}/restore", middlewares.Chain(tenants.Serve((*handlers.Service).RestorePokemon), logger, pokemonMiddleware...)).Methods("POST")
+
+	// Legacy v1 routes, deprecated in favour of /v2/pokemon
+	legacyMiddleware := append([]middlewares.Middleware{middlewares.Deprecated(legacyDeprecatedAt, legacySunset, "/v2/pokemon")}, pokemonMiddleware...)
+	r.HandleFunc("/pokemon-service/getByID/{Id}", middlewares.Chain(tenants.Serve((*handlers.Service).GetByID), logger, legacyMiddleware...)).Methods("GET")
+	r.HandleFunc("/pokemon-service/getByName/{Name}", middlewares.Chain(tenants.Serve((*handlers.Service).GetByName), logger, legacyMiddleware...)).Methods("GET")
+	r.HandleFunc("/pokemon-service/{Id}", middlewares.Chain(tenants.Serve((*handlers.Service).DeleteByID), logger, legacyMiddleware...)).Methods("DELETE")
+	r.HandleFunc("/pokemon-service/Add", middlewares.Chain(tenants.Serve((*handlers.Service).AddPokemon), logger, legacyMiddleware...)).Methods("POST")
+	r.HandleFunc("/graphql", middlewares.Chain(tenants.Serve((*handlers.Service).ExecuteGraphQL), logger, validatedMiddleware...)).Methods("GET", "POST")
+	// Long lived stream, the response logger would buffer it forever so only the request is logged
+	streamMiddleware := []middlewares.Middleware{middlewares.ValidateRequest(validator), middlewares.Identify(keys), middlewares.LoggingRequest}
+	r.HandleFunc("/pokemon-service/events", middlewares.Chain(tenants.Serve((*handlers.Service).StreamEvents), logger, streamMiddleware...)).Methods("GET")
+	r.HandleFunc("/pokemon-service/watch", middlewares.Chain(tenants.Serve((*handlers.Service).WatchPokemon), logger, streamMiddleware...)).Methods("GET")
+
+	// Operational endpoints, only reachable with the admin token set through ADMIN_TOKEN env variable.
+	// The token is checked first, so callers without it learn nothing from validation errors.
+	// Admins act in the tenant named in X-Tenant.
+	adminMiddleware := append([]middlewares.Middleware{middlewares.SelectTenant, middlewares.ValidateRequest(validator), middlewares.AdminOnly(os.Getenv("ADMIN_TOKEN"))}, commonMiddleware...)
+	r.HandleFunc("/admin/tenants", middlewares.Chain(tenants.ListTenants, logger, adminMiddleware...)).Methods("GET")
+	r.HandleFunc("/admin/cache/stats", middlewares.Chain(tenants.Serve((*handlers.Service).CacheStats), logger, adminMiddleware...)).Methods("GET")
+	r.HandleFunc("/admin/cache/keys", middlewares.Chain(tenants.Serve((*handlers.Service).CacheKeys), logger, adminMiddleware...)).Methods("GET")
+	r.HandleFunc("/admin/cache", middlewares.Chain(tenants.Serve((*handlers.Service).FlushCache), logger, adminMiddleware...)).Methods("DELETE")
+	r.HandleFunc("/admin/cache/invalidate", middlewares.Chain(tenants.Serve((*handlers.Service).InvalidateCache), logger, adminMiddleware...)).Methods("POST")
+	r.HandleFunc("/admin/webhooks", middlewares.Chain(tenants.Serve((*handlers.Service).RegisterWebhook), logger, adminMiddleware...)).Methods("POST")
+	r.HandleFunc("/admin/webhooks", middlewares.Chain(tenants.Serve((*handlers.Service).ListWebhooks), logger, adminMiddleware...)).Methods("GET")
+	r.HandleFunc("/admin/webhooks/dead-letters", middlewares.Chain(tenants.Serve((*handlers.Service).ListDeadLetters), logger, adminMiddleware...)).Methods("GET")
+	r.HandleFunc("/admin/webhooks/dead-letters/replay", middlewares.Chain(tenants.Serve((*handlers.Service).ReplayDeadLetters), logger, adminMiddleware...)).Methods("POST")
+	r.HandleFunc("/admin/webhooks/{Id}", middlewares.Chain(tenants.Serve((*handlers.Service).DeleteWebhook), logger, adminMiddleware...)).Methods("DELETE")
+	r.HandleFunc("/admin/audit", middlewares.Chain(tenants.Serve((*handlers.Service).ListAudit), logger, adminMiddleware...)).Methods("GET")
+	// The export streams every entry, only the request is logged like for the event streams
+	exportMiddleware := []middlewares.Middleware{middlewares.SelectTenant, middlewares.ValidateRequest(validator), middlewares.AdminOnly(os.Getenv("ADMIN_TOKEN")), middlewares.LoggingRequest}
+	r.HandleFunc("/admin/audit/export", middlewares.Chain(tenants.Serve((*handlers.Service).ExportAudit), logger, exportMiddleware...)).Methods("GET")
+	// Every route has to be documented, so the document served at /openapi.json cannot drift from the router
+	if err := openapi.CheckRouter(spec, r); err != nil {
+		log.Fatal(err)
+	}
 
 	srv := &http.Server{
-		Handler:      r,
+		// Every route is also served under /tenants/{tenant}
+		Handler:      middlewares.TenantPrefix(r),
 		Addr:         "127.0.0.1:8000",
 		WriteTimeout: 15 * time.Second,
 		ReadTimeout:  15 * time.Second,
 	}
+	// Open event streams never go idle, end them so Shutdown does not wait for its timeout.
+	// Websockets are hijacked and not tracked by Shutdown at all, the hub sends them a going away close.
+	for _, scoped := range services {
+		srv.RegisterOnShutdown(scoped.Stream.Close)
+		srv.RegisterOnShutdown(scoped.Watch.Close)
+	}
 
 	// Start the server in a separate Goroutine.
 	go func() {
@@ -73,6 +221,23 @@ func main() {
 		}
 	}()
 
+	// gRPC API on its own port, reading and writing the same stores as the REST handlers
+	grpcTenants := map[string]*grpcserver.Server{}
+	for name, scoped := range services {
+		grpcTenants[name] = &grpcserver.Server{Store: scoped.Store, Stream: scoped.Stream, History: scoped.History}
+	}
+	grpcServer := grpcserver.New(&grpcserver.Server{Store: service.Store, Stream: service.Stream, History: service.History, Keys: keys, Tenants: grpcTenants}, logger)
+	go func() {
+		listener, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		service.Logger.InfoLogger.Println("Starting the gRPC server on", grpcAddr)
+		if err := grpcServer.Serve(listener); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
 	// Implement graceful shutdown for the server to handle fault tolerance
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -86,18 +251,80 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		service.Logger.InfoLogger.Fatalf("Server shutdown error: %v", err)
 	}
+	//Watch streams were already ended by stream.Close, so this only waits for unary calls in flight
+	grpcServer.GracefulStop()
+	for _, scoped := range services {
+		// Stop the cache before the recorder so no removal callback fires into a closed recorder
+		scoped.Cache.Close()
+		scoped.Evictions.Close()
+		scoped.Webhooks.Close()
+		scoped.Trash.Close()
+		scoped.Audit.Close()
+	}
+	idempotencyKeys.Close()
 	service.Logger.InfoLogger.Println("Server gracefully stopped")
 }
-func loadingInMemCache(cache *bigcache.BigCache) {
+
+// Builds the catalog of one tenant: its cache, store and indexes and every component fed by its events.
+// Only the default tenant starts with the sample pokemons.
+func newTenantService(name string, quota int, records codec.RecordCodec, ids idgen.Allocator, chart *matchup.Chart, spec *openapi3.T) (*handlers.Service, error) {
+	evictions := eviction.NewRecorder(&logger, records)
+	cache, err := customerConfigBigCache(evictions.OnRemoveWithReason)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load cache data: %w", err)
+	}
+	if name == auth.DefaultTenant {
+		loadingInMemCache(cache, records)
+	}
+	bus := events.NewBus()
+	dispatcher := webhooks.NewDispatcher(webhooks.Config{}, &logger)
+	stream := events.NewStream(eventLogSize)
+	bus.Subscribe(dispatcher.Handle)
+	bus.Subscribe(stream.Append)
+	hub := watch.NewHub(watch.Config{}, &logger)
+	bus.Subscribe(hub.Broadcast)
+	bin := trash.NewBin(trash.Config{Retention: trashRetention}, &logger)
+	bus.Subscribe(bin.Handle)
+	auditLog, err := audit.NewLog(audit.Config{File: auditFileOf(name)}, &logger)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open audit log: %w", err)
+	}
+	bus.Subscribe(auditLog.Handle)
+	revisions := history.New(history.Config{MaxRevisions: revisionsKept}, &logger)
+	bus.Subscribe(revisions.Handle)
+	pokemonStore := store.New(cache, bus, records, ids)
+	pokemonStore.SetQuota(quota)
+	suggestions := index.NewTrie()
+	pokemonStore.AddIndex(suggestions)
+	facets := index.NewFacets()
+	pokemonStore.AddIndex(facets)
+	graphQL, err := graphqlapi.New(pokemonStore, revisions, graphqlapi.Limits{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to build GraphQL schema: %w", err)
+	}
+	evictions.Subscribe(pokemonStore.CleanupNameKey)
+	evictions.Subscribe(pokemonStore.PublishEviction)
+	return &handlers.Service{Cache: cache, Store: pokemonStore, Logger: &logger, Evictions: evictions, Stream: stream, Webhooks: dispatcher, Watch: hub, GraphQL: graphQL, OpenAPI: spec, Names: pokemonStore.Names(), Suggestions: suggestions, Facets: facets, Chart: chart, Trash: bin, Audit: auditLog, History: revisions}, nil
+}
+
+// Audit log file of a tenant, the default tenant keeps the name it had before there were tenants
+func auditFileOf(tenant string) string {
+	if tenant == auth.DefaultTenant {
+		return auditFileName
+	}
+	return strings.TrimSuffix(auditFileName, ".ndjson") + "." + tenant + ".ndjson"
+}
+
+func loadingInMemCache(cache *bigcache.BigCache, records codec.RecordCodec) {
 	pokemons := loadSamplePokemonData(cache)
 	for _, val := range pokemons {
-		resp, _ := json.Marshal(val)
+		resp, _ := records.Marshal(val)
 		cache.Set(val.Name, resp)
 		cache.Set(val.Id, resp)
 	}
 }
 func loadSamplePokemonData(cache *bigcache.BigCache) []s.Pokemon {
-	//generateUniqueIds := fmt.Sprintf("PK%v", rand.Intn(100000))
+	//IDs of pokemons added without one come from the ID allocator, see idgen
 	return []s.Pokemon{
 		{Id: fmt.Sprintf("PK%v", 10001), Name: "Chespin", Type: "TT", Height: "20.9", Weight: "30.9", Abilities: "Eat&Sleep"},
 		{Id: fmt.Sprintf("PK%v", 10002), Name: "Fennekin", Type: "PP", Height: "10.9", Weight: "31.1", Abilities: "Eat&Sleep"},
@@ -110,7 +337,7 @@ func loadSamplePokemonData(cache *bigcache.BigCache) []s.Pokemon {
 		{Id: fmt.Sprintf("PK%v", 10009), Name: "PokemonY", Type: "WY", Height: "20.9", Weight: "33.2", Abilities: "Eat&Sleep"},
 	}
 }
-func customerConfigBigCache() (*bigcache.BigCache, error) {
+func customerConfigBigCache(onRemove func(key string, entry []byte, reason bigcache.RemoveReason)) (*bigcache.BigCache, error) {
 	config := bigcache.Config{
 		// number of shards (must be a power of 2)
 		Shards: 1024,
@@ -146,7 +373,7 @@ func customerConfigBigCache() (*bigcache.BigCache, error) {
 		// for the new entry, or because delete was called. A constant representing the reason will be passed through.
 		// Default value is nil which means no callback and it prevents from unwrapping the oldest entry.
 		// Ignored if OnRemove is specified.
-		OnRemoveWithReason: nil,
+		OnRemoveWithReason: onRemove,
 	}
 	return bigcache.NewBigCache(config)
 }
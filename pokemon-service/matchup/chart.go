@@ -0,0 +1,118 @@
+package matchup
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	index "pokemon-service/index"
+	"sort"
+)
+
+// Standard chart of the main series games since generation VI
+//
+//go:embed chart.json
+var defaultChart []byte
+
+// Chart holds how effective a move of each type is against each type. Types are compared regardless of
+// case, accents and whitespace, pairs the chart does not list are neutral.
+type Chart struct {
+	// Attacking type to defending type to multiplier, both normalized
+	multipliers map[string]map[string]float64
+	// Type as written in the chart by normalized type
+	labels map[string]string
+}
+
+// Chart the service starts with unless another one is configured
+func DefaultChart() *Chart {
+	chart, err := LoadChart(bytes.NewReader(defaultChart))
+	if err != nil {
+		panic(fmt.Sprintf("embedded type chart: %v", err))
+	}
+	return chart
+}
+
+// Reads a chart from a JSON file, see LoadChart
+func ReadChart(path string) (*Chart, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadChart(file)
+}
+
+// Decodes a JSON object of attacking types, each mapping defending types to the multiplier of the move,
+// like {"Fire": {"Grass": 2, "Water": 0.5}}. Neutral pairs can be left out.
+func LoadChart(r io.Reader) (*Chart, error) {
+	var data map[string]map[string]float64
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return nil, fmt.Errorf("invalid type chart: %w", err)
+	}
+	chart := &Chart{multipliers: map[string]map[string]float64{}, labels: map[string]string{}}
+	for attacking, defending := range data {
+		key, err := chart.addType(attacking)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := chart.multipliers[key]; ok {
+			return nil, fmt.Errorf("invalid type chart: %q is listed twice", attacking)
+		}
+		chart.multipliers[key] = map[string]float64{}
+		for defendingType, multiplier := range defending {
+			defendingKey, err := chart.addType(defendingType)
+			if err != nil {
+				return nil, err
+			}
+			if multiplier < 0 {
+				return nil, fmt.Errorf("invalid type chart: %v against %v is negative", attacking, defendingType)
+			}
+			chart.multipliers[key][defendingKey] = multiplier
+		}
+	}
+	return chart, nil
+}
+
+// Types on the chart, sorted
+func (chart *Chart) Types() []string {
+	types := make([]string, 0, len(chart.labels))
+	for _, label := range chart.labels {
+		types = append(types, label)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Whether pokemonType is on the chart
+func (chart *Chart) Knows(pokemonType string) bool {
+	_, ok := chart.labels[index.Normalize(pokemonType)]
+	return ok
+}
+
+// Multiplier of a move of attacking type against a pokemon of the defending types, the product of the
+// multipliers against each of them
+func (chart *Chart) Multiplier(attacking string, defending []string) float64 {
+	multipliers := chart.multipliers[index.Normalize(attacking)]
+	product := 1.0
+	for _, defendingType := range defending {
+		if multiplier, ok := multipliers[index.Normalize(defendingType)]; ok {
+			product *= multiplier
+		}
+	}
+	return product
+}
+
+func (chart *Chart) addType(pokemonType string) (string, error) {
+	key := index.Normalize(pokemonType)
+	if len(key) <= 0 {
+		return "", errors.New("invalid type chart: blank type")
+	}
+	if label, ok := chart.labels[key]; ok && label != pokemonType {
+		return "", fmt.Errorf("invalid type chart: %q and %q are the same type", label, pokemonType)
+	}
+	chart.labels[key] = pokemonType
+	return key, nil
+}
@@ -0,0 +1,67 @@
+package matchup
+
+import (
+	"math"
+	index "pokemon-service/index"
+	schema "pokemon-service/schema"
+	"sort"
+)
+
+const (
+	DefaultLevel = 50
+	DefaultPower = 80
+	// Bonus of a move sharing a type with the pokemon using it
+	sameTypeBonus = 1.5
+)
+
+// Matches attacker against defender both ways with moves of Power at Level, which must be positive
+func (chart *Chart) Matchup(attacker schema.Pokemon, defender schema.Pokemon, level int, power int) schema.Matchup {
+	attackerTypes := index.Types(attacker)
+	defenderTypes := index.Types(defender)
+	matchup := schema.Matchup{
+		Attacker:           attacker,
+		Defender:           defender,
+		AttackerToDefender: chart.effectiveness(attackerTypes, defenderTypes, level, power),
+		DefenderToAttacker: chart.effectiveness(defenderTypes, attackerTypes, level, power),
+		UnknownTypes:       []string{},
+		Level:              level,
+		Power:              power,
+	}
+	seen := map[string]bool{}
+	for _, pokemonType := range append(attackerTypes, defenderTypes...) {
+		if key := index.Normalize(pokemonType); !chart.Knows(pokemonType) && !seen[key] {
+			seen[key] = true
+			matchup.UnknownTypes = append(matchup.UnknownTypes, pokemonType)
+		}
+	}
+	sort.Strings(matchup.UnknownTypes)
+	return matchup
+}
+
+// Picks the most effective move of the attacking types, the first one listed on ties
+func (chart *Chart) effectiveness(attacking []string, defending []string, level int, power int) schema.Effectiveness {
+	effectiveness := schema.Effectiveness{Multipliers: map[string]float64{}}
+	bonus := sameTypeBonus
+	if len(attacking) <= 0 {
+		//Typeless move without the same type bonus, neutral against every type
+		attacking = []string{""}
+		bonus = 1
+	}
+	for i, attackingType := range attacking {
+		multiplier := chart.Multiplier(attackingType, defending)
+		effectiveness.Multipliers[attackingType] = multiplier
+		if i == 0 || multiplier > effectiveness.Multiplier {
+			effectiveness.BestType = attackingType
+			effectiveness.Multiplier = multiplier
+		}
+	}
+	effectiveness.Damage = estimate(level, power, bonus*effectiveness.Multiplier)
+	return effectiveness
+}
+
+// Damage formula of the main series games for equal attack and defense and without random factors
+// or critical hits, rounded to one decimal
+func estimate(level int, power int, modifier float64) float64 {
+	base := (2*float64(level)/5+2)*float64(power)/50 + 2
+	return math.Round(base*modifier*10) / 10
+}
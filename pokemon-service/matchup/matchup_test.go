@@ -0,0 +1,76 @@
+package matchup
+
+import (
+	"fmt"
+	schema "pokemon-service/schema"
+	"strings"
+	"testing"
+)
+
+func TestMultiplier(t *testing.T) {
+	chart := DefaultChart()
+	inputs := []struct {
+		testName  string
+		attacking string
+		defending []string
+		expected  float64
+	}{
+		{testName: "TestSuperEffective", attacking: "Fire", defending: []string{"Grass"}, expected: 2},
+		{testName: "TestDualTypeMultiplies", attacking: "ice", defending: []string{"Dragon", "Flying"}, expected: 4},
+		{testName: "TestDualTypeCancels", attacking: "Fire", defending: []string{"Grass", "Water"}, expected: 1},
+		{testName: "TestImmune", attacking: "Electric", defending: []string{"Water", "Ground"}, expected: 0},
+		{testName: "TestUnknownType", attacking: "Sound", defending: []string{"Grass"}, expected: 1},
+	}
+	for _, item := range inputs {
+		if multiplier := chart.Multiplier(item.attacking, item.defending); multiplier != item.expected {
+			t.Errorf("%v: unexpected multiplier: got %v want %v", item.testName, multiplier, item.expected)
+		}
+	}
+	if types := chart.Types(); len(types) != 18 {
+		t.Errorf("unexpected types in the default chart: %v", types)
+	}
+}
+
+func TestLoadChart(t *testing.T) {
+	inputs := []struct {
+		testName string
+		data     string
+		err      bool
+	}{
+		{testName: "TestLoadChart", data: `{"Sound": {"Ghost": 0, "Glass": 2}, "Glass": {}}`},
+		{testName: "TestLoadChartNegative", data: `{"Sound": {"Glass": -1}}`, err: true},
+		{testName: "TestLoadChartBlankType", data: `{" ": {}}`, err: true},
+		{testName: "TestLoadChartSameType", data: `{"Sound": {"SOUND": 0.5}}`, err: true},
+		{testName: "TestLoadChartNotJson", data: `Sound,Glass,2`, err: true},
+	}
+	for _, item := range inputs {
+		if _, err := LoadChart(strings.NewReader(item.data)); (err != nil) != item.err {
+			t.Errorf("%v: unexpected error: %v", item.testName, err)
+		}
+	}
+}
+
+func TestMatchup(t *testing.T) {
+	chart := DefaultChart()
+	pikachu := schema.Pokemon{Id: "PK25", Name: "Pikachu", Type: "Electric"}
+	gyarados := schema.Pokemon{Id: "PK130", Name: "Gyarados", Type: "Water/Flying"}
+	matchup := chart.Matchup(pikachu, gyarados, DefaultLevel, DefaultPower)
+
+	attack := matchup.AttackerToDefender
+	if attack.BestType != "Electric" || attack.Multiplier != 4 || attack.Damage != 223.2 {
+		t.Errorf("unexpected attack: %+v", attack)
+	}
+	counter := matchup.DefenderToAttacker
+	if counter.BestType != "Water" || fmt.Sprint(counter.Multipliers) != "map[Flying:0.5 Water:1]" || counter.Damage != 55.8 {
+		t.Errorf("unexpected counter: %+v", counter)
+	}
+	if len(matchup.UnknownTypes) != 0 {
+		t.Errorf("unexpected unknown types: %v", matchup.UnknownTypes)
+	}
+
+	//Pokemons without a known type get a neutral typeless move
+	matchup = chart.Matchup(schema.Pokemon{Id: "PK1", Type: "TT"}, schema.Pokemon{Id: "PK2"}, DefaultLevel, DefaultPower)
+	if matchup.DefenderToAttacker.Multiplier != 1 || matchup.DefenderToAttacker.Damage != 37.2 || fmt.Sprint(matchup.UnknownTypes) != "[TT]" {
+		t.Errorf("unexpected matchup of unknown types: %+v", matchup)
+	}
+}
@@ -0,0 +1,35 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"net/http"
+	schema "pokemon-service/schema"
+	utility "pokemon-service/utility"
+	"time"
+)
+
+const adminTokenHeader = "X-Admin-Token"
+
+// AdminOnly builds a middleware that only lets requests carrying the configured admin token through.
+// An empty token disables the admin API altogether.
+func AdminOnly(token string) Middleware {
+	return func(handler http.HandlerFunc, l schema.Logger) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			var adminResp schema.DataResponse
+			w.Header().Set("Content-Type", "Application/json")
+
+			if len(token) <= 0 {
+				utility.FrameHttpDataResponse(403, "Admin API is disabled", &adminResp, start, w)
+				return
+			}
+			provided := req.Header.Get(adminTokenHeader)
+			if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				l.WarnLogger.Println("Rejected admin request with:", req.URL.Path+" and Method:"+req.Method)
+				utility.FrameHttpDataResponse(401, "Valid "+adminTokenHeader+" header is expected", &adminResp, start, w)
+				return
+			}
+			handler.ServeHTTP(w, req)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminOnly(t *testing.T) {
+	// create a handler to use as "next" which only answers when the token was accepted
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	inputs := []struct {
+		testName string
+		token    string
+		header   string
+		status   int
+	}{
+		{testName: "TestAdminOnlyValidToken", token: "secret", header: "secret", status: 200},
+		{testName: "TestAdminOnlyInvalidToken", token: "secret", header: "guess", status: 401},
+		{testName: "TestAdminOnlyMissingToken", token: "secret", header: "", status: 401},
+		{testName: "TestAdminOnlyDisabled", token: "", header: "", status: 403},
+	}
+
+	for _, item := range inputs {
+		handlerToTest := AdminOnly(item.token)(nextHandler, discardLogger())
+
+		req, err := http.NewRequest("GET", "/admin/cache/stats", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(item.header) > 0 {
+			req.Header.Set(adminTokenHeader, item.header)
+		}
+
+		rr := httptest.NewRecorder()
+		handlerToTest.ServeHTTP(rr, req)
+
+		if rr.Code != item.status {
+			t.Errorf("%v: handler returned wrong status code: got %v want %v", item.testName, rr.Code, item.status)
+		}
+	}
+}
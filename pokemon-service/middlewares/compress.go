@@ -0,0 +1,185 @@
+package middlewares
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	schema "pokemon-service/schema"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Content codings the Compress middleware produces, in order of preference when the client accepts several equally
+var encoders = []struct {
+	name string
+	pool *sync.Pool
+}{
+	{name: "gzip", pool: &sync.Pool{New: func() interface{} { return gzip.NewWriter(io.Discard) }}},
+	{name: "deflate", pool: &sync.Pool{New: func() interface{} {
+		writer, _ := flate.NewWriter(io.Discard, flate.DefaultCompression)
+		return writer
+	}}},
+}
+
+// Writers are pooled, resetting one is far cheaper than allocating its compression state
+type resettableWriter interface {
+	io.WriteCloser
+	Reset(io.Writer)
+	Flush() error
+}
+
+// Compress builds a middleware compressing response bodies with the content coding negotiated from
+// Accept-Encoding. Bodies shorter than minSize are sent as they are, compressing them costs more than it saves.
+// It has to wrap the response logger, so the logger records the uncompressed body and the status the handler set.
+func Compress(minSize int) Middleware {
+	return func(handler http.HandlerFunc, l schema.Logger) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			encoding := negotiateEncoding(req.Header.Get("Accept-Encoding"))
+			if encoding < 0 || req.Method == http.MethodHead {
+				handler.ServeHTTP(w, req)
+				return
+			}
+
+			compressed := &compressWriter{ResponseWriter: w, encoding: encoding, minSize: minSize, status: http.StatusOK}
+			defer compressed.Close()
+			handler.ServeHTTP(compressed, req)
+		}
+	}
+}
+
+// Index in encoders of the preferred coding in an Accept-Encoding header, -1 when the body is sent as it is
+func negotiateEncoding(acceptEncoding string) int {
+	chosen, chosenQuality := -1, 0.0
+	wildcard, explicit := -1.0, map[string]float64{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "*" {
+			wildcard = quality
+		} else if len(coding) > 0 {
+			explicit[coding] = quality
+		}
+	}
+	for index, encoder := range encoders {
+		quality, ok := explicit[encoder.name]
+		if !ok {
+			quality = wildcard
+		}
+		if quality > chosenQuality {
+			chosen, chosenQuality = index, quality
+		}
+	}
+	return chosen
+}
+
+// Buffers the start of the body until it reaches minSize, then sends headers with Content-Encoding and
+// compresses the rest on the fly. Bodies that end before minSize are sent uncompressed on Close.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding int
+	minSize  int
+	status   int
+	buffer   bytes.Buffer
+	// Set once the headers were sent, encoder stays nil when the body goes out uncompressed
+	started bool
+	encoder resettableWriter
+}
+
+func (cw *compressWriter) WriteHeader(statusCode int) {
+	if cw.started {
+		return
+	}
+	cw.status = statusCode
+	//Nothing to compress in responses that cannot have a body
+	if statusCode < http.StatusOK || statusCode == http.StatusNoContent || statusCode == http.StatusNotModified {
+		cw.start(false)
+	}
+}
+
+func (cw *compressWriter) Write(data []byte) (int, error) {
+	if cw.started {
+		if cw.encoder != nil {
+			return cw.encoder.Write(data)
+		}
+		return cw.ResponseWriter.Write(data)
+	}
+	cw.buffer.Write(data)
+	if cw.buffer.Len() >= cw.minSize {
+		if err := cw.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+// Sends what the handler wrote so far, compressed unless the body is too short to tell
+func (cw *compressWriter) Flush() {
+	if !cw.started {
+		cw.start(cw.buffer.Len() >= cw.minSize)
+	}
+	if cw.encoder != nil {
+		cw.encoder.Flush()
+	}
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Ends the body, sending a short one uncompressed and returning the encoder to its pool
+func (cw *compressWriter) Close() error {
+	if !cw.started {
+		return cw.start(false)
+	}
+	if cw.encoder == nil {
+		return nil
+	}
+	err := cw.encoder.Close()
+	cw.encoder.Reset(io.Discard)
+	encoders[cw.encoding].pool.Put(cw.encoder)
+	cw.encoder = nil
+	return err
+}
+
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// Sends the headers and the buffered start of the body
+func (cw *compressWriter) start(compress bool) error {
+	cw.started = true
+	header := cw.ResponseWriter.Header()
+	//Handler encoded the body itself
+	if len(header.Get("Content-Encoding")) > 0 {
+		compress = false
+	}
+	if compress {
+		header.Set("Content-Encoding", encoders[cw.encoding].name)
+		header.Del("Content-Length")
+		cw.encoder = encoders[cw.encoding].pool.Get().(resettableWriter)
+		cw.encoder.Reset(cw.ResponseWriter)
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+	if cw.buffer.Len() <= 0 {
+		return nil
+	}
+	var err error
+	if cw.encoder != nil {
+		_, err = cw.encoder.Write(cw.buffer.Bytes())
+	} else {
+		_, err = cw.ResponseWriter.Write(cw.buffer.Bytes())
+	}
+	cw.buffer.Reset()
+	return err
+}
@@ -0,0 +1,104 @@
+package middlewares
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat(`{"ID":"PK10001","Name":"Picachoo1"}`, 100)
+	inputs := []struct {
+		testName       string
+		acceptEncoding string
+		status         int
+		body           string
+		encoding       string
+	}{
+		{testName: "TestCompressGzip", acceptEncoding: "gzip", status: 200, body: large, encoding: "gzip"},
+		{testName: "TestCompressDeflate", acceptEncoding: "deflate, gzip;q=0.5", status: 200, body: large, encoding: "deflate"},
+		{testName: "TestCompressWildcard", acceptEncoding: "br, *", status: 404, body: large, encoding: "gzip"},
+		{testName: "TestCompressSmallBody", acceptEncoding: "gzip", status: 200, body: `{"ID":"PK10001"}`, encoding: ""},
+		{testName: "TestCompressIdentity", acceptEncoding: "", status: 200, body: large, encoding: ""},
+		{testName: "TestCompressRefused", acceptEncoding: "gzip;q=0, deflate;q=0", status: 200, body: large, encoding: ""},
+		{testName: "TestCompressNoContent", acceptEncoding: "gzip", status: 204, body: "", encoding: ""},
+	}
+
+	for _, item := range inputs {
+		// create a handler to use as "next" which writes the body in small chunks
+		nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "Application/json")
+			w.WriteHeader(item.status)
+			for body := item.body; len(body) > 0; {
+				chunk := min(len(body), 100)
+				w.Write([]byte(body[:chunk]))
+				body = body[chunk:]
+			}
+		})
+		req, _ := http.NewRequest("GET", "/v2/pokemon", nil)
+		req.Header.Set("Accept-Encoding", item.acceptEncoding)
+		rr := httptest.NewRecorder()
+		Compress(1024)(nextHandler, discardLogger()).ServeHTTP(rr, req)
+
+		if rr.Code != item.status {
+			t.Errorf("%v: handler returned wrong status code: got %v want %v", item.testName, rr.Code, item.status)
+		}
+		if encoding := rr.Header().Get("Content-Encoding"); encoding != item.encoding {
+			t.Errorf("%v: handler returned wrong encoding: got %q want %q", item.testName, encoding, item.encoding)
+		}
+		if body := decompress(t, rr.Header().Get("Content-Encoding"), rr.Body); body != item.body {
+			t.Errorf("%v: body changed, got %v bytes want %v", item.testName, len(body), len(item.body))
+		}
+	}
+}
+
+func TestCompressLogsUncompressedBody(t *testing.T) {
+	var logged bytes.Buffer
+	logger := discardLogger()
+	logger.InfoLogger = log.New(&logged, "Info:", 0)
+
+	large := strings.Repeat("Picachoo1,", 200)
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(large))
+	})
+	handlerToTest := Chain(nextHandler, logger, LoggingResponse, Compress(1024))
+
+	req, _ := http.NewRequest("POST", "/v2/pokemon", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+	handlerToTest.ServeHTTP(rr, req)
+
+	if rr.Header().Get("Content-Encoding") != "gzip" || rr.Code != http.StatusCreated {
+		t.Fatalf("response was not compressed: %v %v", rr.Code, rr.Header())
+	}
+	if !strings.Contains(logged.String(), large) || !strings.Contains(logged.String(), "Status Code: 201") {
+		t.Errorf("logger did not record the uncompressed body and status: %.200v", logged.String())
+	}
+}
+
+func decompress(t *testing.T, encoding string, body io.Reader) string {
+	t.Helper()
+	var reader io.Reader = body
+	switch encoding {
+	case "gzip":
+		gzipReader, err := gzip.NewReader(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = gzipReader
+	case "deflate":
+		reader = flate.NewReader(body)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	schema "pokemon-service/schema"
+	"time"
+)
+
+// Deprecated builds a middleware announcing that a route is going away, so clients can migrate before it does.
+// It sets Deprecation (RFC 9745), Sunset (RFC 8594) and a Link to the route replacing it.
+func Deprecated(deprecatedAt time.Time, sunset time.Time, successor string) Middleware {
+	deprecation := fmt.Sprintf("@%d", deprecatedAt.Unix())
+	sunsetDate := sunset.UTC().Format(http.TimeFormat)
+	link := fmt.Sprintf("<%v>; rel=\"successor-version\"", successor)
+	return func(handler http.HandlerFunc, l schema.Logger) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Deprecation", deprecation)
+			w.Header().Set("Sunset", sunsetDate)
+			w.Header().Add("Link", link)
+			handler.ServeHTTP(w, req)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	auth "pokemon-service/auth"
+	idempotency "pokemon-service/idempotency"
+	schema "pokemon-service/schema"
+	utility "pokemon-service/utility"
+	"time"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	// Set on responses replayed for a repeated Idempotency-Key
+	idempotentReplayHeader = "Idempotent-Replayed"
+	idempotencyKeyMaxSize  = 255
+)
+
+// Idempotent builds a middleware making mutating requests that carry an Idempotency-Key safe to retry.
+// The first request with a key runs and its response is recorded, repeating it replays that response without
+// running the handler again. Reusing the key for a different request gets 422, and 409 while the first one runs.
+// Server errors are not recorded, so requests failing with them can be retried. Keys are scoped to the tenant
+// and the actor the request was identified for, so callers using the same key never see each other's responses.
+func Idempotent(keys *idempotency.Store) Middleware {
+	return func(handler http.HandlerFunc, l schema.Logger) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			key := req.Header.Get(idempotencyKeyHeader)
+			if len(key) <= 0 || req.Method == http.MethodGet || req.Method == http.MethodHead {
+				handler.ServeHTTP(w, req)
+				return
+			}
+
+			start := time.Now()
+			var idempotencyResp schema.DataResponse
+			if len(key) > idempotencyKeyMaxSize {
+				w.Header().Set("Content-Type", "Application/json")
+				utility.FrameHttpDataResponse(400, fmt.Sprintf("%v longer than %v characters", idempotencyKeyHeader, idempotencyKeyMaxSize), &idempotencyResp, start, w)
+				return
+			}
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				w.Header().Set("Content-Type", "Application/json")
+				utility.FrameHttpDataResponse(400, "Unable to read request body", &idempotencyResp, start, w)
+				return
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			scoped := auth.Tenant(req.Context()) + "/" + auth.Actor(req.Context()) + "/" + key
+			recorded, err := keys.Begin(scoped, fingerprint(req, body))
+			switch {
+			case errors.Is(err, idempotency.ErrMismatch):
+				l.WarnLogger.Println("Rejected reused", idempotencyKeyHeader+":", key, "for:", req.URL.Path+" and Method:"+req.Method)
+				w.Header().Set("Content-Type", "Application/json")
+				utility.FrameHttpDataResponse(422, fmt.Sprintf("%v:%v was used for a different request", idempotencyKeyHeader, key), &idempotencyResp, start, w)
+				return
+			case errors.Is(err, idempotency.ErrInProgress):
+				w.Header().Set("Content-Type", "Application/json")
+				utility.FrameHttpDataResponse(409, fmt.Sprintf("Request with %v:%v is still in progress", idempotencyKeyHeader, key), &idempotencyResp, start, w)
+				return
+			case recorded != nil:
+				for name, values := range recorded.Header {
+					w.Header()[name] = values
+				}
+				w.Header().Set(idempotentReplayHeader, "true")
+				w.WriteHeader(recorded.Status)
+				w.Write(recorded.Body)
+				return
+			}
+
+			recorder := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+			completed := false
+			//Handler panicked or failed, the key is released so the request can be retried
+			defer func() {
+				if !completed {
+					keys.Release(scoped)
+				}
+			}()
+			handler.ServeHTTP(recorder, req)
+			if recorder.status >= http.StatusInternalServerError {
+				return
+			}
+			keys.Complete(scoped, idempotency.Response{Status: recorder.status, Header: recorder.header, Body: recorder.body.Bytes()})
+			completed = true
+		}
+	}
+}
+
+// Identifies a request by method, target, body type and body
+func fingerprint(req *http.Request, body []byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%v %v\n%v\n", req.Method, req.URL.RequestURI(), req.Header.Get("Content-Type"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Passes the response on while keeping its status, headers and body for replays
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(statusCode int) {
+	if rw.header == nil {
+		rw.status = statusCode
+		rw.snapshotHeader()
+	}
+	rw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rw *recordingWriter) Write(data []byte) (int, error) {
+	if rw.header == nil {
+		rw.snapshotHeader()
+	}
+	rw.body.Write(data)
+	return rw.ResponseWriter.Write(data)
+}
+
+func (rw *recordingWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// Headers as the handler sent them, encoding applied further out is applied again to replays
+func (rw *recordingWriter) snapshotHeader() {
+	rw.header = rw.ResponseWriter.Header().Clone()
+	rw.header.Del("Content-Encoding")
+	rw.header.Del("Content-Length")
+}
@@ -0,0 +1,88 @@
+package middlewares
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	auth "pokemon-service/auth"
+	idempotency "pokemon-service/idempotency"
+	"strconv"
+	"testing"
+)
+
+func TestIdempotent(t *testing.T) {
+	keys := idempotency.NewStore(idempotency.Config{})
+	defer keys.Close()
+
+	// create a handler to use as "next" which counts how often it ran
+	calls := 0
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Query().Get("fail") == "true" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Location", "/v2/pokemon/PK10003")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("call " + strconv.Itoa(calls)))
+	})
+	handlerToTest := Idempotent(keys)(nextHandler, discardLogger())
+
+	inputs := []struct {
+		testName string
+		method   string
+		target   string
+		tenant   string
+		actor    string
+		key      string
+		body     string
+		status   int
+		response string
+		calls    int
+		replayed bool
+	}{
+		{testName: "TestIdempotentFirst", method: "POST", target: "/v2/pokemon", key: "key-1", body: `{"ID":"PK10003"}`, status: 201, response: "call 1", calls: 1},
+		{testName: "TestIdempotentReplay", method: "POST", target: "/v2/pokemon", key: "key-1", body: `{"ID":"PK10003"}`, status: 201, response: "call 1", calls: 1, replayed: true},
+		{testName: "TestIdempotentDifferentBody", method: "POST", target: "/v2/pokemon", key: "key-1", body: `{"ID":"PK10004"}`, status: 422, calls: 1},
+		{testName: "TestIdempotentDifferentTarget", method: "PUT", target: "/v2/pokemon/PK10003", key: "key-1", body: `{"ID":"PK10003"}`, status: 422, calls: 1},
+		{testName: "TestIdempotentWithoutKey", method: "POST", target: "/v2/pokemon", body: `{"ID":"PK10003"}`, status: 201, response: "call 2", calls: 2},
+		{testName: "TestIdempotentGet", method: "GET", target: "/v2/pokemon/PK10003", key: "key-1", status: 201, response: "call 3", calls: 3},
+		{testName: "TestIdempotentOtherTenant", method: "POST", target: "/v2/pokemon", tenant: "kanto", key: "key-1", body: `{"ID":"PK10003"}`, status: 201, response: "call 4", calls: 4},
+		{testName: "TestIdempotentOtherActor", method: "POST", target: "/v2/pokemon", actor: "misty", key: "key-1", body: `{"ID":"PK10003"}`, status: 201, response: "call 5", calls: 5},
+		{testName: "TestIdempotentSameActor", method: "POST", target: "/v2/pokemon", actor: "misty", key: "key-1", body: `{"ID":"PK10003"}`, status: 201, response: "call 5", calls: 5, replayed: true},
+		{testName: "TestIdempotentServerError", method: "POST", target: "/v2/pokemon?fail=true", key: "key-2", status: 500, calls: 6},
+		{testName: "TestIdempotentRetryAfterServerError", method: "POST", target: "/v2/pokemon?fail=true", key: "key-2", status: 500, calls: 7},
+	}
+
+	for _, item := range inputs {
+		req, err := http.NewRequest(item.method, item.target, bytes.NewBufferString(item.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Idempotency-Key", item.key)
+		if len(item.tenant) > 0 {
+			req = req.WithContext(auth.WithTenant(req.Context(), item.tenant))
+		}
+		if len(item.actor) > 0 {
+			req = req.WithContext(auth.WithActor(req.Context(), item.actor))
+		}
+		rr := httptest.NewRecorder()
+		handlerToTest.ServeHTTP(rr, req)
+
+		if rr.Code != item.status {
+			t.Errorf("%v: handler returned wrong status code: got %v want %v", item.testName, rr.Code, item.status)
+		}
+		if calls != item.calls {
+			t.Errorf("%v: handler ran %v times want %v", item.testName, calls, item.calls)
+		}
+		if len(item.response) > 0 && rr.Body.String() != item.response {
+			t.Errorf("%v: unexpected body: got %v want %v", item.testName, rr.Body.String(), item.response)
+		}
+		if replayed := rr.Header().Get("Idempotent-Replayed") == "true"; replayed != item.replayed {
+			t.Errorf("%v: replayed %v want %v", item.testName, replayed, item.replayed)
+		}
+		if item.replayed && rr.Header().Get("Location") != "/v2/pokemon/PK10003" {
+			t.Errorf("%v: recorded headers were not replayed: %v", item.testName, rr.Header())
+		}
+	}
+}
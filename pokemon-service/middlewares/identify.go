@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"net/http"
+	auth "pokemon-service/auth"
+	schema "pokemon-service/schema"
+	utility "pokemon-service/utility"
+	"time"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+// Identify builds a middleware attaching the actor of the API key a request carries to its context, so changes
+// it makes are recorded under that actor. Requests without a key stay anonymous, unknown keys are rejected.
+// The tenant the key acts in is attached too, naming another one in X-Tenant is forbidden.
+func Identify(keys *auth.Keys) Middleware {
+	return func(handler http.HandlerFunc, l schema.Logger) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			var identifyResp schema.DataResponse
+			identity := auth.Identity{}
+			if key := req.Header.Get(apiKeyHeader); len(key) > 0 {
+				known, ok := keys.Lookup(key)
+				if !ok {
+					w.Header().Set("Content-Type", "Application/json")
+					l.WarnLogger.Println("Rejected unknown", apiKeyHeader, "for:", req.URL.Path+" and Method:"+req.Method)
+					utility.FrameHttpDataResponse(401, "Valid "+apiKeyHeader+" header is expected", &identifyResp, start, w)
+					return
+				}
+				identity = known
+			}
+			tenant, err := identity.Select(req.Header.Get(tenantHeader))
+			if err != nil {
+				w.Header().Set("Content-Type", "Application/json")
+				l.WarnLogger.Println("Rejected", tenantHeader, req.Header.Get(tenantHeader), "for:", req.URL.Path+" and Method:"+req.Method)
+				utility.FrameHttpDataResponse(403, "Rejected "+tenantHeader+": "+err.Error(), &identifyResp, start, w)
+				return
+			}
+			ctx := auth.WithTenant(req.Context(), tenant)
+			if len(identity.Actor) > 0 {
+				ctx = auth.WithActor(ctx, identity.Actor)
+			}
+			handler.ServeHTTP(w, req.WithContext(ctx))
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	auth "pokemon-service/auth"
+	"testing"
+)
+
+func TestIdentify(t *testing.T) {
+	keys, err := auth.ParseKeys("ash:s3cret,misty@johto:t0gepi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// create a handler to use as "next" which answers with the actor and tenant it was given
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(auth.Actor(r.Context()) + "@" + auth.Tenant(r.Context())))
+	})
+	handlerToTest := Identify(keys)(nextHandler, discardLogger())
+
+	inputs := []struct {
+		testName string
+		header   string
+		tenant   string
+		status   int
+		actor    string
+	}{
+		{testName: "TestIdentifyKnownKey", header: "s3cret", status: 200, actor: "ash@default"},
+		{testName: "TestIdentifyNoKey", header: "", status: 200, actor: auth.Anonymous + "@default"},
+		{testName: "TestIdentifyUnknownKey", header: "guess", status: 401},
+		{testName: "TestIdentifyTenantKey", header: "t0gepi", status: 200, actor: "misty@johto"},
+		{testName: "TestIdentifyOwnTenant", header: "t0gepi", tenant: "johto", status: 200, actor: "misty@johto"},
+		{testName: "TestIdentifyForeignTenant", header: "t0gepi", tenant: "default", status: 403},
+		{testName: "TestIdentifyAnonymousTenant", header: "", tenant: "johto", status: 403},
+	}
+
+	for _, item := range inputs {
+		req, err := http.NewRequest("POST", "/v2/pokemon", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(item.header) > 0 {
+			req.Header.Set(apiKeyHeader, item.header)
+		}
+		if len(item.tenant) > 0 {
+			req.Header.Set(tenantHeader, item.tenant)
+		}
+
+		rr := httptest.NewRecorder()
+		handlerToTest.ServeHTTP(rr, req)
+
+		if rr.Code != item.status {
+			t.Errorf("%v: handler returned wrong status code: got %v want %v", item.testName, rr.Code, item.status)
+		}
+		if item.status == 200 && rr.Body.String() != item.actor {
+			t.Errorf("%v: handler got wrong actor: got %v want %v", item.testName, rr.Body.String(), item.actor)
+		}
+	}
+}
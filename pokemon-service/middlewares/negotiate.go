@@ -0,0 +1,30 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	codec "pokemon-service/codec"
+	schema "pokemon-service/schema"
+	utility "pokemon-service/utility"
+	"strings"
+	"time"
+)
+
+// Negotiate picks the response media type from the Accept header and hands it to the handler,
+// answering 406 when none of the accepted types can be produced
+func Negotiate(handler http.HandlerFunc, l schema.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		responseCodec, ok := codec.Negotiate(req.Header.Get("Accept"))
+		if ok {
+			handler.ServeHTTP(&codec.ResponseWriter{ResponseWriter: w, Codec: responseCodec}, req)
+			return
+		}
+
+		start := time.Now()
+		var negotiationResp schema.DataResponse
+		w.Header().Set("Content-Type", "Application/json")
+		l.WarnLogger.Println("Rejected request with:", req.URL.Path+" and Method:"+req.Method, "for Accept:", req.Header.Get("Accept"))
+		utility.FrameHttpDataResponse(406, fmt.Sprintf("None of the accepted media types can be produced, supported: %v", strings.Join(codec.MediaTypes(), ", ")), &negotiationResp, start, w)
+	}
+}
@@ -0,0 +1,84 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	codec "pokemon-service/codec"
+	schema "pokemon-service/schema"
+	utility "pokemon-service/utility"
+	"testing"
+	"time"
+)
+
+func TestNegotiate(t *testing.T) {
+	// create a handler to use as "next" which frames a list of pokemons
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "Application/json")
+		resp := schema.DataResponse{Data: []schema.Pokemon{{Id: "PK10001", Name: "Picachoo1"}}}
+		utility.FrameHttpDataResponse(200, "Success", &resp, time.Now(), w)
+	})
+	handlerToTest := Negotiate(nextHandler, discardLogger())
+
+	inputs := []struct {
+		testName    string
+		accept      string
+		status      int
+		contentType string
+	}{
+		{testName: "TestNegotiateDefault", accept: "", status: 200, contentType: "Application/json"},
+		{testName: "TestNegotiateMessagePack", accept: "application/msgpack", status: 200, contentType: "application/msgpack"},
+		{testName: "TestNegotiateProtobuf", accept: "application/x-protobuf", status: 200, contentType: "application/x-protobuf"},
+		{testName: "TestNegotiateCSV", accept: "text/csv", status: 200, contentType: "text/csv"},
+		{testName: "TestNegotiateUnsupported", accept: "application/xml", status: 406, contentType: "Application/json"},
+	}
+
+	for _, item := range inputs {
+		req, err := http.NewRequest("GET", "/v2/pokemon", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Accept", item.accept)
+
+		rr := httptest.NewRecorder()
+		handlerToTest.ServeHTTP(rr, req)
+
+		if rr.Code != item.status {
+			t.Errorf("%v: handler returned wrong status code: got %v want %v", item.testName, rr.Code, item.status)
+		}
+		if contentType := rr.Header().Get("Content-Type"); contentType != item.contentType {
+			t.Errorf("%v: handler returned wrong content type: got %v want %v", item.testName, contentType, item.contentType)
+		}
+	}
+}
+
+func TestNegotiateNotRepresentable(t *testing.T) {
+	inputs := []struct {
+		testName string
+		status   int
+		want     int
+	}{
+		{testName: "TestNegotiateSuccessNotRepresentable", status: 200, want: 406},
+		{testName: "TestNegotiateErrorFallsBackToJson", status: 404, want: 404},
+	}
+
+	for _, item := range inputs {
+		nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			resp := schema.DataResponse{Data: schema.Pokemon{Id: "PK10001"}}
+			if item.status >= 400 {
+				resp.Data = nil
+			}
+			utility.FrameHttpDataResponse(item.status, "", &resp, time.Now(), w)
+		})
+		req, _ := http.NewRequest("GET", "/v2/pokemon/PK10001", nil)
+		req.Header.Set("Accept", codec.CSV.MediaType())
+		rr := httptest.NewRecorder()
+		Negotiate(nextHandler, discardLogger()).ServeHTTP(rr, req)
+
+		if rr.Code != item.want {
+			t.Errorf("%v: handler returned wrong status code: got %v want %v", item.testName, rr.Code, item.want)
+		}
+		if contentType := rr.Header().Get("Content-Type"); contentType == codec.CSV.MediaType() {
+			t.Errorf("%v: JSON fallback was labelled %v", item.testName, contentType)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package middlewares
 
 import (
 	"github.com/gorilla/mux"
+	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"pokemon-service/schema"
@@ -13,7 +15,7 @@ func TestLoggingRequest(t *testing.T) {
 	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 	})
 
-	logger := schema.Logger{}
+	logger := discardLogger()
 	// create the handler to test, using our custom "next" handler
 	handlerToTest := LoggingRequest(nextHandler, logger)
 
@@ -22,7 +24,7 @@ func TestLoggingRequest(t *testing.T) {
 		"Name": "PK101",
 	}
 
-	req, err := http.NewRequest("GET", "/pokemon-service/{Name}", nil)
+	req, err := http.NewRequest("GET", "/pokemon-service/{Name}", http.NoBody)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -32,3 +34,14 @@ func TestLoggingRequest(t *testing.T) {
 	// call the handler using a mock response recorder (we'll not use that anyway)
 	handlerToTest.ServeHTTP(httptest.NewRecorder(), req)
 }
+
+// Logger writing nowhere, so middlewares can log without a logger file
+func discardLogger() schema.Logger {
+	return schema.Logger{
+		InfoLogger:  log.New(io.Discard, "Info:", 0),
+		WarnLogger:  log.New(io.Discard, "Warn:", 0),
+		DebugLogger: log.New(io.Discard, "Debug:", 0),
+		ErrorLogger: log.New(io.Discard, "Error:", 0),
+		FatalLogger: log.New(io.Discard, "Fatal:", 0),
+	}
+}
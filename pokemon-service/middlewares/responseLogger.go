@@ -44,6 +44,18 @@ func (rww *ResponseWriterWrapper) WriteHeader(statusCode int) {
     (rww.w).WriteHeader(statusCode)
 }
 
+// Unwrap returns the wrapped http.ResponseWriter, so wrappers further out can still be reached
+func (rww *ResponseWriterWrapper) Unwrap() http.ResponseWriter {
+    return rww.w
+}
+
+// Flush sends buffered data on when the wrapped http.ResponseWriter supports it
+func (rww *ResponseWriterWrapper) Flush() {
+    if flusher, ok := (rww.w).(http.Flusher); ok {
+        flusher.Flush()
+    }
+}
+
 func (rww *ResponseWriterWrapper) String() string {
     var buf bytes.Buffer
     for k, v := range (rww.w).Header() {
@@ -64,8 +76,9 @@ func LoggingResponse(handler http.HandlerFunc, l schema.Logger) http.HandlerFunc
 				)
 			}
 		}()
-		wrapped := ResponseWriterWrapper{w: w}
-		handler.ServeHTTP(&wrapped, r)
+		//Handlers that never call WriteHeader answer 200
+		wrapped := NewResponseWriterWrapper(w)
+		handler.ServeHTTP(wrapped, r)
 		l.InfoLogger.Println(
 			wrapped.statusCode,
 			wrapped.String(),
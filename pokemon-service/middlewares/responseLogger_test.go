@@ -4,7 +4,6 @@ import (
 	"github.com/gorilla/mux"
 	"net/http"
 	"net/http/httptest"
-	"pokemon-service/schema"
 	"testing"
 )
 
@@ -13,7 +12,7 @@ func TestLoggingResponse(t *testing.T) {
 	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 	})
 
-	logger := schema.Logger{}
+	logger := discardLogger()
 	// create the handler to test, using our custom "next" handler
 	handlerToTest := LoggingRequest(nextHandler, logger)
 
@@ -22,7 +21,7 @@ func TestLoggingResponse(t *testing.T) {
 		"Name": "PK101",
 	}
 
-	req, err := http.NewRequest("GET", "/pokemon-service/{Name}", nil)
+	req, err := http.NewRequest("GET", "/pokemon-service/{Name}", http.NoBody)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,99 @@
+package middlewares
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	auth "pokemon-service/auth"
+	schema "pokemon-service/schema"
+	utility "pokemon-service/utility"
+	"strings"
+	"time"
+)
+
+const (
+	tenantHeader = "X-Tenant"
+	// Paths under it are served from the catalog of the tenant named by the next segment
+	tenantPrefix = "/tenants/"
+)
+
+// TenantPrefix serves /tenants/{tenant}/... like the same path without the prefix, with the tenant asked for in
+// X-Tenant, so tenants can be selected by path as well as by header. Both naming different tenants gets 400.
+// It wraps the router rather than a route, so every route is reachable under the prefix. Location headers pointing
+// into the service are given the prefix back, so they name the tenant the request was served for.
+func TenantPrefix(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !strings.HasPrefix(req.URL.Path, tenantPrefix) {
+			next.ServeHTTP(w, req)
+			return
+		}
+		tenant, rest, _ := strings.Cut(strings.TrimPrefix(req.URL.Path, tenantPrefix), "/")
+		if requested := req.Header.Get(tenantHeader); len(requested) > 0 && requested != tenant {
+			start := time.Now()
+			var tenantResp schema.DataResponse
+			w.Header().Set("Content-Type", "Application/json")
+			utility.FrameHttpDataResponse(400, "Path names tenant "+tenant+" but "+tenantHeader+" names "+requested, &tenantResp, start, w)
+			return
+		}
+		scoped := req.Clone(req.Context())
+		scoped.URL.Path = "/" + rest
+		scoped.URL.RawPath = ""
+		scoped.RequestURI = scoped.URL.RequestURI()
+		scoped.Header.Set(tenantHeader, tenant)
+		next.ServeHTTP(&prefixWriter{ResponseWriter: w, prefix: tenantPrefix + url.PathEscape(tenant)}, scoped)
+	})
+}
+
+// Puts the stripped tenant prefix in front of the Location header once the status is written
+type prefixWriter struct {
+	http.ResponseWriter
+	prefix      string
+	wroteHeader bool
+}
+
+func (pw *prefixWriter) WriteHeader(statusCode int) {
+	if !pw.wroteHeader {
+		pw.wroteHeader = true
+		//Only paths of this service are prefixed, absolute URLs point elsewhere
+		if location := pw.Header().Get("Location"); strings.HasPrefix(location, "/") && !strings.HasPrefix(location, "//") {
+			pw.Header().Set("Location", pw.prefix+location)
+		}
+	}
+	pw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (pw *prefixWriter) Write(data []byte) (int, error) {
+	if !pw.wroteHeader {
+		pw.WriteHeader(http.StatusOK)
+	}
+	return pw.ResponseWriter.Write(data)
+}
+
+func (pw *prefixWriter) Flush() {
+	if flusher, ok := pw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (pw *prefixWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := pw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	pw.wroteHeader = true
+	return hijacker.Hijack()
+}
+
+// SelectTenant attaches the tenant named in X-Tenant to admin requests, the default tenant when there is none.
+// Admins are not bound to a tenant, so it only belongs behind AdminOnly.
+func SelectTenant(handler http.HandlerFunc, l schema.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		tenant := req.Header.Get(tenantHeader)
+		if len(tenant) <= 0 {
+			tenant = auth.DefaultTenant
+		}
+		handler.ServeHTTP(w, req.WithContext(auth.WithTenant(req.Context(), tenant)))
+	}
+}
@@ -0,0 +1,91 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	auth "pokemon-service/auth"
+	"testing"
+)
+
+func TestTenantPrefix(t *testing.T) {
+	// create a handler to use as "next" which answers with the path and tenant header it was given
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.RequestURI() + " " + r.Header.Get(tenantHeader)))
+	})
+	handlerToTest := TenantPrefix(nextHandler)
+
+	inputs := []struct {
+		testName string
+		target   string
+		header   string
+		status   int
+		served   string
+	}{
+		{testName: "TestTenantPrefix", target: "/tenants/johto/v2/pokemon/PK10001?asOf=now", status: 200, served: "/v2/pokemon/PK10001?asOf=now johto"},
+		{testName: "TestTenantPrefixSameHeader", target: "/tenants/johto/v2/pokemon", header: "johto", status: 200, served: "/v2/pokemon johto"},
+		{testName: "TestTenantPrefixOtherHeader", target: "/tenants/johto/v2/pokemon", header: "kanto", status: 400},
+		{testName: "TestTenantPrefixWithout", target: "/v2/pokemon", header: "kanto", status: 200, served: "/v2/pokemon kanto"},
+	}
+
+	for _, item := range inputs {
+		req, err := http.NewRequest("GET", item.target, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(item.header) > 0 {
+			req.Header.Set(tenantHeader, item.header)
+		}
+
+		rr := httptest.NewRecorder()
+		handlerToTest.ServeHTTP(rr, req)
+
+		if rr.Code != item.status {
+			t.Errorf("%v: handler returned wrong status code: got %v want %v", item.testName, rr.Code, item.status)
+		}
+		if item.status == 200 && rr.Body.String() != item.served {
+			t.Errorf("%v: handler served wrong request: got %v want %v", item.testName, rr.Body.String(), item.served)
+		}
+	}
+}
+
+func TestTenantPrefixLocation(t *testing.T) {
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", r.URL.Query().Get("location"))
+		w.WriteHeader(http.StatusCreated)
+	})
+	handlerToTest := TenantPrefix(nextHandler)
+
+	inputs := []struct {
+		target   string
+		location string
+	}{
+		{target: "/tenants/johto/v2/pokemon?location=/v2/pokemon/PK10001", location: "/tenants/johto/v2/pokemon/PK10001"},
+		{target: "/v2/pokemon?location=/v2/pokemon/PK10001", location: "/v2/pokemon/PK10001"},
+		{target: "/tenants/johto/v2/pokemon?location=https://example.com/hook", location: "https://example.com/hook"},
+	}
+	for _, item := range inputs {
+		req, _ := http.NewRequest("POST", item.target, nil)
+		rr := httptest.NewRecorder()
+		handlerToTest.ServeHTTP(rr, req)
+		if location := rr.Header().Get("Location"); location != item.location {
+			t.Errorf("%v: handler returned wrong location: got %v want %v", item.target, location, item.location)
+		}
+	}
+}
+
+func TestSelectTenant(t *testing.T) {
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(auth.Tenant(r.Context())))
+	})
+	handlerToTest := SelectTenant(nextHandler, discardLogger())
+
+	for header, tenant := range map[string]string{"": auth.DefaultTenant, "johto": "johto"} {
+		req, _ := http.NewRequest("GET", "/admin/cache/stats", nil)
+		req.Header.Set(tenantHeader, header)
+		rr := httptest.NewRecorder()
+		handlerToTest.ServeHTTP(rr, req)
+		if rr.Body.String() != tenant {
+			t.Errorf("%q: handler got wrong tenant: got %v want %v", header, rr.Body.String(), tenant)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	openapi "pokemon-service/openapi"
+	schema "pokemon-service/schema"
+	utility "pokemon-service/utility"
+	"strings"
+	"time"
+)
+
+// ValidateRequest builds a middleware rejecting requests that do not match the OpenAPI document,
+// with 415 for bodies that are not JSON and 400 for anything else
+func ValidateRequest(validator *openapi.Validator) Middleware {
+	return func(handler http.HandlerFunc, l schema.Logger) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			err := validator.Validate(req)
+			if err == nil {
+				handler.ServeHTTP(w, req)
+				return
+			}
+
+			start := time.Now()
+			var validationResp schema.DataResponse
+			w.Header().Set("Content-Type", "Application/json")
+			//Schema errors go on with a dump of the schema and value, the first line names the problem
+			message := strings.SplitN(err.Error(), "\n", 2)[0]
+			l.WarnLogger.Println("Rejected invalid request with:", req.URL.Path+" and Method:"+req.Method, message)
+			if errors.Is(err, openapi.ErrUnsupportedMediaType) {
+				utility.FrameHttpDataResponse(415, message, &validationResp, start, w)
+				return
+			}
+			utility.FrameHttpDataResponse(400, message, &validationResp, start, w)
+		}
+	}
+}
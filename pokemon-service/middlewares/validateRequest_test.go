@@ -0,0 +1,54 @@
+package middlewares
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	openapi "pokemon-service/openapi"
+	"testing"
+)
+
+func TestValidateRequest(t *testing.T) {
+	doc, err := openapi.Spec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator, err := openapi.NewValidator(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// create a handler to use as "next" which only answers when the request was accepted
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handlerToTest := ValidateRequest(validator)(nextHandler, discardLogger())
+
+	inputs := []struct {
+		testName    string
+		contentType string
+		body        string
+		status      int
+	}{
+		{testName: "TestValidateRequestValid", contentType: "application/json", body: `{"ID":"PK10001","Name":"Picachoo1"}`, status: 200},
+		{testName: "TestValidateRequestInvalid", contentType: "application/json", body: `{"ID":1}`, status: 400},
+		{testName: "TestValidateRequestMediaType", contentType: "application/xml", body: `<ID>PK10001</ID>`, status: 415},
+		//Fixmap of ID and Name, and one lacking Name
+		{testName: "TestValidateRequestMessagePack", contentType: "application/msgpack", body: "\x82\xa2ID\xa7PK10001\xa4Name\xa9Picachoo1", status: 200},
+		{testName: "TestValidateRequestMessagePackInvalid", contentType: "application/msgpack", body: "\x81\xa2ID\xa7PK10001", status: 400},
+	}
+
+	for _, item := range inputs {
+		req, err := http.NewRequest("POST", "/pokemon-service/Add", bytes.NewBufferString(item.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", item.contentType)
+
+		rr := httptest.NewRecorder()
+		handlerToTest.ServeHTTP(rr, req)
+
+		if rr.Code != item.status {
+			t.Errorf("%v: handler returned wrong status code: got %v want %v, body: %v", item.testName, rr.Code, item.status, rr.Body.String())
+		}
+	}
+}
@@ -0,0 +1,464 @@
+package openapi
+
+import (
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Every route registered in main.go, CheckRouter fails when the two drift apart
+var catalogue = []operation{
+	{
+		method: "GET", path: "/health-check", id: "healthCheck", tag: "Service",
+		summary: "Reports that the service is up",
+		responses: []response{
+			{status: 200, description: "Service is healthy", mediaType: "text/plain", schema: openapi3.NewStringSchema().NewRef()},
+		},
+	},
+	{
+		method: "GET", path: "/openapi.json", id: "getOpenAPI", tag: "Service",
+		summary: "This document",
+		responses: []response{
+			{status: 200, description: "OpenAPI 3 document", mediaType: jsonMediaType, schema: openapi3.NewObjectSchema().NewRef()},
+		},
+	},
+	{
+		method: "GET", path: "/docs", id: "getDocs", tag: "Service",
+		summary: "Human readable rendering of this document",
+		responses: []response{
+			{status: 200, description: "Documentation page", mediaType: "text/html", schema: openapi3.NewStringSchema().NewRef()},
+		},
+	},
+	{
+		method: "GET", path: "/pokemon-service/getByID/{Id}", id: "getByID", tag: "Pokemon v1", identified: true, deprecated: true, negotiated: true,
+		summary: "Retrieves a pokemon by its ID, as it was at an earlier time with asOf",
+		parameters: openapi3.Parameters{
+			pathParameter("Id", "ID of the pokemon"),
+			queryParameter("asOf", "RFC 3339 timestamp to read the pokemon as it was at from its history", openapi3.NewStringSchema()),
+		},
+		responses: []response{
+			jsonResponse(200, "Pokemon found", pokemonResponse),
+			invalidRequest,
+			jsonResponse(404, "No pokemon with this ID, at asOf or in the history kept", pokemonResponse),
+			jsonResponse(422, "ID is missing or asOf is invalid", pokemonResponse),
+		},
+	},
+	{
+		method: "GET", path: "/pokemon-service/getByName/{Name}", id: "getByName", tag: "Pokemon v1", identified: true, deprecated: true, negotiated: true,
+		summary:    "Retrieves a pokemon by its name, regardless of case, accents and whitespace",
+		parameters: openapi3.Parameters{pathParameter("Name", "Name of the pokemon")},
+		responses: []response{
+			jsonResponse(200, "Pokemon found", pokemonResponse),
+			invalidRequest,
+			jsonResponse(400, "asOf was sent, past records are only read by ID", pokemonResponse),
+			jsonResponse(404, "No pokemon with this name", pokemonResponse),
+			jsonResponse(422, "Name is missing", pokemonResponse),
+		},
+	},
+	{
+		method: "POST", path: "/pokemon-service/Add", id: "addPokemon", tag: "Pokemon v1", identified: true, deprecated: true, negotiated: true, idempotent: true,
+		summary:      "Adds a pokemon, an ID is allocated when none is sent. An existing one with the same ID is only replaced with upsert=true",
+		parameters:   openapi3.Parameters{queryParameter("upsert", "Replace the pokemon with the same ID instead of failing with 409", openapi3.NewBoolSchema())},
+		request:      "PokemonRequest",
+		requestTypes: []string{jsonMediaType, msgpackMediaType},
+		responses: []response{
+			jsonResponse(200, "Pokemon replaced, only with upsert=true", pokemonResponse),
+			jsonResponse(201, "Pokemon created, its URL is in the Location header", pokemonResponse),
+			invalidRequest,
+			jsonResponse(409, "ID or name is taken, the body carries the pokemon holding it", pokemonResponse),
+			jsonResponse(415, "Request body is neither JSON nor MessagePack", dataResponse),
+			jsonResponse(422, "ID is missing or EvolvesFrom does not name an earlier stage", pokemonResponse),
+			jsonResponse(500, "Pokemon could not be stored", pokemonResponse),
+			jsonResponse(507, "Tenant holds as many pokemons as its quota allows", pokemonResponse),
+		},
+	},
+	{
+		method: "DELETE", path: "/pokemon-service/{Id}", id: "deleteByID", tag: "Pokemon v1", identified: true, deprecated: true, negotiated: true, idempotent: true,
+		summary:    "Moves a pokemon to the trash by its ID",
+		parameters: openapi3.Parameters{pathParameter("Id", "ID of the pokemon")},
+		responses: []response{
+			jsonResponse(200, "Pokemon deleted", pokemonResponse),
+			jsonResponse(400, "No pokemon with this ID, or the request does not match the specification", pokemonResponse),
+			jsonResponse(409, "Other pokemons evolve from this one", pokemonResponse),
+			jsonResponse(422, "ID is missing", pokemonResponse),
+		},
+	},
+	{
+		method: "GET", path: "/v2/pokemon", id: "listPokemon", tag: "Pokemon", identified: true, negotiated: true,
+		summary:    "Lists pokemons ordered by ID, or the one with the given name",
+		parameters: openapi3.Parameters{queryParameter("name", "Only the pokemon with this name", openapi3.NewStringSchema())},
+		responses: []response{
+			listResponse(200, "Pokemons, empty when no pokemon has the name", "Pokemon"),
+			invalidRequest,
+		},
+	},
+	{
+		method: "POST", path: "/v2/pokemon", id: "createPokemon", tag: "Pokemon", identified: true, negotiated: true, idempotent: true,
+		summary:      "Creates a pokemon, an ID is allocated when none is sent",
+		request:      "Pokemon",
+		requestTypes: pokemonRequestTypes,
+		responses: []response{
+			jsonResponse(201, "Pokemon created, its URL is in the Location header", envelope("Pokemon")),
+			invalidRequest,
+			jsonResponse(409, "ID or name is taken, Data carries the pokemon holding it", dataResponse),
+			jsonResponse(415, "Request body is not JSON, MessagePack or protobuf", dataResponse),
+			jsonResponse(422, "ID is missing or EvolvesFrom does not name an earlier stage", dataResponse),
+			jsonResponse(507, "Tenant holds as many pokemons as its quota allows", dataResponse),
+		},
+	},
+	{
+		method: "GET", path: "/v2/pokemon/{id}", id: "getPokemon", tag: "Pokemon", identified: true, negotiated: true,
+		summary: "Retrieves a pokemon, as it was at an earlier time with asOf",
+		parameters: openapi3.Parameters{
+			pathParameter("id", "ID of the pokemon"),
+			queryParameter("asOf", "RFC 3339 timestamp to read the pokemon as it was at from its history", openapi3.NewStringSchema()),
+		},
+		responses: []response{
+			jsonResponse(200, "Pokemon found", envelope("Pokemon")),
+			invalidRequest,
+			jsonResponse(404, "No pokemon with this ID, at asOf or in the history kept", dataResponse),
+			jsonResponse(422, "Invalid asOf", dataResponse),
+		},
+	},
+	{
+		method: "PUT", path: "/v2/pokemon/{id}", id: "replacePokemon", tag: "Pokemon", identified: true, negotiated: true, idempotent: true,
+		summary:      "Creates or replaces the pokemon with this ID, the body may leave the ID out",
+		parameters:   openapi3.Parameters{pathParameter("id", "ID of the pokemon")},
+		request:      "Pokemon",
+		requestTypes: pokemonRequestTypes,
+		responses: []response{
+			jsonResponse(200, "Pokemon replaced", envelope("Pokemon")),
+			jsonResponse(201, "Pokemon created, its URL is in the Location header", envelope("Pokemon")),
+			invalidRequest,
+			jsonResponse(409, "Name is taken by another pokemon, Data carries it", dataResponse),
+			jsonResponse(415, "Request body is not JSON, MessagePack or protobuf", dataResponse),
+			jsonResponse(422, "ID in the body differs from the one in the path, or EvolvesFrom does not name an earlier stage", dataResponse),
+			jsonResponse(507, "Tenant holds as many pokemons as its quota allows", dataResponse),
+		},
+	},
+	{
+		method: "PATCH", path: "/v2/pokemon/{id}", id: "patchPokemon", tag: "Pokemon", identified: true, negotiated: true, idempotent: true,
+		summary:      "Updates some fields of a pokemon with a JSON merge patch, null clears a field",
+		parameters:   openapi3.Parameters{pathParameter("id", "ID of the pokemon")},
+		request:      "PokemonPatch",
+		requestTypes: []string{mergePatchMediaType, jsonMediaType},
+		responses: []response{
+			jsonResponse(200, "Pokemon updated", envelope("Pokemon")),
+			invalidRequest,
+			jsonResponse(404, "No pokemon with this ID", dataResponse),
+			jsonResponse(409, "Name is taken by another pokemon, Data carries it", dataResponse),
+			jsonResponse(415, "Request body is not a JSON merge patch", dataResponse),
+			jsonResponse(422, "Patch changes the ID, clears the name, names an unknown field or breaks the evolution chain", dataResponse),
+		},
+	},
+	{
+		method: "DELETE", path: "/v2/pokemon/{id}", id: "deletePokemon", tag: "Pokemon", identified: true, negotiated: true, idempotent: true,
+		summary:    "Moves a pokemon to the trash, it can be restored until the retention period ends",
+		parameters: openapi3.Parameters{pathParameter("id", "ID of the pokemon")},
+		responses: []response{
+			{status: 204, description: "Pokemon deleted"},
+			invalidRequest,
+			jsonResponse(404, "No pokemon with this ID", dataResponse),
+			jsonResponse(409, "Other pokemons evolve from this one", dataResponse),
+		},
+	},
+	{
+		method: "GET", path: "/v2/pokemon/{id}/evolutions", id: "getEvolutions", tag: "Pokemon", identified: true,
+		summary:    "Retrieves the evolution chain of a pokemon as a tree starting at its first stage",
+		parameters: openapi3.Parameters{pathParameter("id", "ID of any stage of the chain")},
+		responses: []response{
+			jsonResponse(200, "Evolution chain", envelope("EvolutionStage")),
+			invalidRequest,
+			jsonResponse(404, "No pokemon with this ID", dataResponse),
+		},
+	},
+	{
+		method: "GET", path: "/v2/pokemon/{id}/history", id: "getHistory", tag: "Pokemon", identified: true,
+		summary:    "Lists the last revisions of a pokemon, the latest first",
+		parameters: openapi3.Parameters{pathParameter("id", "ID of the pokemon")},
+		responses: []response{
+			jsonResponse(200, "Revisions, empty when the pokemon did not change since the service started", listEnvelope("PokemonRevision")),
+			invalidRequest,
+			jsonResponse(404, "No pokemon with this ID", dataResponse),
+		},
+	},
+	{
+		method: "POST", path: "/v2/pokemon/{id}/revert", id: "revertPokemon", tag: "Pokemon", identified: true, negotiated: true, idempotent: true,
+		summary:    "Writes a revision of a pokemon back, which adds a new revision",
+		parameters: openapi3.Parameters{pathParameter("id", "ID of the pokemon")},
+		request:    "RevertRequest",
+		responses: []response{
+			jsonResponse(200, "Pokemon reverted", envelope("Pokemon")),
+			jsonResponse(201, "Pokemon deleted since was created again, its URL is in the Location header", envelope("Pokemon")),
+			invalidRequest,
+			jsonResponse(404, "Revision is not kept in the history", dataResponse),
+			jsonResponse(409, "Name was taken since, Data carries the pokemon holding it", dataResponse),
+			jsonResponse(415, "Request body is not JSON", dataResponse),
+			jsonResponse(422, "Revision removed the pokemon, or its EvolvesFrom no longer names an earlier stage", dataResponse),
+			jsonResponse(507, "Tenant holds as many pokemons as its quota allows", dataResponse),
+		},
+	},
+	{
+		method: "POST", path: "/pokemon-service/batchGet", id: "batchGet", tag: "Pokemon", identified: true, negotiated: true, idempotent: true,
+		summary:      "Fetches up to 500 pokemons by ID and by name in one call",
+		request:      "BatchGetRequest",
+		requestTypes: []string{jsonMediaType, msgpackMediaType},
+		responses: []response{
+			jsonResponse(200, "Found pokemons, keys that were not found or could not be read are listed in Errors", envelope("BatchGetResult")),
+			invalidRequest,
+			jsonResponse(415, "Request body is neither JSON nor MessagePack", dataResponse),
+			jsonResponse(422, "No keys or more than 500 keys were sent", dataResponse),
+		},
+	},
+	{
+		method: "GET", path: "/pokemon-service/search", id: "searchPokemon", tag: "Pokemon", identified: true, negotiated: true,
+		summary: "Searches names: exact matches first, then names starting with q, then names a few typos away",
+		parameters: openapi3.Parameters{
+			{Value: openapi3.NewQueryParameter("q").WithDescription("Name or start of a name, case, accents and whitespace do not matter").WithRequired(true).WithSchema(openapi3.NewStringSchema())},
+			queryParameter("limit", "Most pokemons returned, 10 by default", openapi3.NewIntegerSchema().WithMin(1).WithMax(100)),
+		},
+		responses: []response{
+			listResponse(200, "Matching pokemons, best match first", "Pokemon"),
+			invalidRequest,
+			jsonResponse(422, "q is blank or limit is out of range", dataResponse),
+		},
+	},
+	{
+		method: "GET", path: "/pokemon-service/suggest", id: "suggestPokemon", tag: "Pokemon", identified: true,
+		summary: "Suggests names starting with prefix as the user types, the pokemons looked up most often first",
+		parameters: openapi3.Parameters{
+			queryParameter("prefix", "Start of a name, case, accents and whitespace do not matter. Empty suggests the most popular pokemons", openapi3.NewStringSchema()),
+			queryParameter("limit", "Most suggestions returned, 10 by default", openapi3.NewIntegerSchema().WithMin(1).WithMax(50)),
+		},
+		responses: []response{
+			jsonResponse(200, "Suggestions, most popular first", listEnvelope("Suggestion")),
+			invalidRequest,
+			jsonResponse(422, "limit is out of range", dataResponse),
+		},
+	},
+	{
+		method: "GET", path: "/pokemon-service/types/{type}", id: "listByType", tag: "Pokemon", identified: true, negotiated: true,
+		summary: "Lists the pokemons of a type ordered by ID, types and abilities are compared regardless of case",
+		parameters: openapi3.Parameters{
+			pathParameter("type", "Type, a pokemon with two types like Grass/Poison is listed under both"),
+			queryParameter("ability", "Only pokemons having this ability", openapi3.NewStringSchema()),
+		},
+		responses: []response{
+			listResponse(200, "Pokemons of the type, empty when there are none", "Pokemon"),
+			invalidRequest,
+		},
+	},
+	{
+		method: "GET", path: "/pokemon-service/facets", id: "facetCounts", tag: "Pokemon", identified: true,
+		summary: "Counts pokemons per type and per ability",
+		responses: []response{
+			jsonResponse(200, "Counts", envelope("FacetCounts")),
+			invalidRequest,
+		},
+	},
+	{
+		method: "POST", path: "/pokemon-service/matchup", id: "matchPokemon", tag: "Pokemon", identified: true,
+		summary:      "Matches two pokemons up on the type chart both ways, with a damage estimate for the best move of each",
+		request:      "MatchupRequest",
+		requestTypes: []string{jsonMediaType, msgpackMediaType},
+		responses: []response{
+			jsonResponse(200, "Multipliers and damage estimates", envelope("Matchup")),
+			invalidRequest,
+			jsonResponse(404, "No pokemon with the Attacker or Defender ID", dataResponse),
+			jsonResponse(415, "Request body is neither JSON nor MessagePack", dataResponse),
+			jsonResponse(422, "Attacker or Defender is missing, or Level or Power is out of range", dataResponse),
+		},
+	},
+	{
+		method: "GET", path: "/pokemon-service/trash", id: "listTrash", tag: "Pokemon", identified: true,
+		summary: "Lists the deleted pokemons that can still be restored, the latest deleted first",
+		responses: []response{
+			jsonResponse(200, "Deleted pokemons with the time they are purged at", listEnvelope("TrashedPokemon")),
+			invalidRequest,
+		},
+	},
+	{
+		method: "POST", path: "/pokemon-service/{Id}/restore", id: "restorePokemon", tag: "Pokemon", identified: true, negotiated: true, idempotent: true,
+		summary:    "Brings a deleted pokemon back from the trash",
+		parameters: openapi3.Parameters{pathParameter("Id", "ID of the deleted pokemon")},
+		responses: []response{
+			jsonResponse(200, "Pokemon restored, its URL is in the Location header", envelope("Pokemon")),
+			invalidRequest,
+			jsonResponse(404, "No pokemon with this ID in the trash", dataResponse),
+			jsonResponse(409, "ID or name was taken since, Data carries the pokemon holding it", dataResponse),
+			jsonResponse(422, "Pokemon evolves from a pokemon that no longer exists", dataResponse),
+			jsonResponse(507, "Tenant holds as many pokemons as its quota allows", dataResponse),
+		},
+	},
+	{
+		method: "GET", path: "/graphql", id: "queryGraphQL", tag: "GraphQL", identified: true,
+		summary: "Runs a GraphQL query, mutations are only accepted with POST",
+		parameters: openapi3.Parameters{
+			{Value: openapi3.NewQueryParameter("query").WithDescription("GraphQL document").WithRequired(true).WithSchema(openapi3.NewStringSchema())},
+			queryParameter("operationName", "Operation to run when the document holds several", openapi3.NewStringSchema()),
+			queryParameter("variables", "JSON encoded variables", openapi3.NewStringSchema()),
+		},
+		responses: []response{
+			jsonResponse(200, "GraphQL result, resolver errors are listed in errors", openapi3.NewObjectSchema().NewRef()),
+			jsonResponse(400, "Document was rejected before execution", openapi3.NewObjectSchema().NewRef()),
+		},
+	},
+	{
+		method: "POST", path: "/graphql", id: "executeGraphQL", tag: "GraphQL", identified: true,
+		summary: "Runs a GraphQL query or mutation",
+		request: "GraphQLRequest",
+		responses: []response{
+			jsonResponse(200, "GraphQL result, resolver errors are listed in errors", openapi3.NewObjectSchema().NewRef()),
+			jsonResponse(400, "Document was rejected before execution", openapi3.NewObjectSchema().NewRef()),
+			jsonResponse(415, "Request body is not JSON", dataResponse),
+		},
+	},
+	{
+		method: "GET", path: "/pokemon-service/events", id: "streamEvents", tag: "Events", identified: true,
+		summary: "Streams pokemon changes as Server-Sent Events",
+		parameters: openapi3.Parameters{
+			queryParameter("types", "Comma separated event types, with or without the pokemon. prefix", openapi3.NewStringSchema()),
+			queryParameter("ids", "Comma separated pokemon IDs", openapi3.NewStringSchema()),
+			{Value: openapi3.NewHeaderParameter("Last-Event-ID").WithDescription("Resume after this event sequence").WithSchema(openapi3.NewStringSchema())},
+		},
+		responses: []response{
+			{status: 200, description: "Event stream, every event carries a PokemonEvent as data", mediaType: "text/event-stream", schema: component("PokemonEvent")},
+			invalidRequest,
+		},
+	},
+	{
+		method: "GET", path: "/pokemon-service/watch", id: "watchPokemon", tag: "Events", identified: true,
+		summary: "Upgrades to a websocket for watching individual pokemons",
+		responses: []response{
+			{status: 101, description: "Switched to the websocket protocol"},
+			{status: 400, description: "Request is not a websocket upgrade"},
+			jsonResponse(503, "Server is shutting down", dataResponse),
+		},
+	},
+	{
+		method: "GET", path: "/admin/cache/stats", id: "cacheStats", tag: "Admin", admin: true, tenanted: true,
+		summary: "Cache statistics and eviction counts",
+		responses: []response{
+			jsonResponse(200, "Statistics", envelope("CacheStats")),
+			unauthorized, adminDisabled,
+		},
+	},
+	{
+		method: "GET", path: "/admin/cache/keys", id: "cacheKeys", tag: "Admin", admin: true, tenanted: true,
+		summary: "Keys held in cache",
+		parameters: openapi3.Parameters{
+			queryParameter("prefix", "Only keys starting with this prefix", openapi3.NewStringSchema()),
+			queryParameter("limit", "Most keys returned", openapi3.NewIntegerSchema().WithMin(0)),
+		},
+		responses: []response{
+			jsonResponse(200, "Keys", envelope("CacheKeys")),
+			invalidRequest, unauthorized, adminDisabled,
+			jsonResponse(422, "Invalid limit", dataResponse),
+		},
+	},
+	{
+		method: "DELETE", path: "/admin/cache", id: "flushCache", tag: "Admin", admin: true, tenanted: true,
+		summary: "Removes every entry from cache",
+		responses: []response{
+			jsonResponse(200, "Cache flushed", envelope("InvalidateResult")),
+			unauthorized, adminDisabled,
+			jsonResponse(500, "Cache could not be flushed", dataResponse),
+		},
+	},
+	{
+		method: "POST", path: "/admin/cache/invalidate", id: "invalidateCache", tag: "Admin", admin: true, tenanted: true,
+		summary: "Removes the listed IDs, names and types from cache",
+		request: "InvalidateRequest",
+		responses: []response{
+			jsonResponse(200, "Entries removed", envelope("InvalidateResult")),
+			invalidRequest, unauthorized, adminDisabled,
+			jsonResponse(415, "Request body is not JSON", dataResponse),
+			jsonResponse(422, "Nothing to invalidate", dataResponse),
+		},
+	},
+	{
+		method: "POST", path: "/admin/webhooks", id: "registerWebhook", tag: "Webhooks", admin: true, tenanted: true,
+		summary: "Registers a callback URL for pokemon events",
+		request: "WebhookSubscription",
+		responses: []response{
+			jsonResponse(201, "Webhook registered, the signing secret is only returned here", envelope("WebhookSubscription")),
+			invalidRequest, unauthorized, adminDisabled,
+			jsonResponse(415, "Request body is not JSON", dataResponse),
+			jsonResponse(422, "Invalid URL or event types", dataResponse),
+		},
+	},
+	{
+		method: "GET", path: "/admin/webhooks", id: "listWebhooks", tag: "Webhooks", admin: true, tenanted: true,
+		summary: "Lists registered webhooks without their secrets",
+		responses: []response{
+			jsonResponse(200, "Webhooks", listEnvelope("WebhookSubscription")),
+			unauthorized, adminDisabled,
+		},
+	},
+	{
+		method: "DELETE", path: "/admin/webhooks/{Id}", id: "deleteWebhook", tag: "Webhooks", admin: true, tenanted: true,
+		summary:    "Removes a registered webhook",
+		parameters: openapi3.Parameters{pathParameter("Id", "ID of the webhook")},
+		responses: []response{
+			jsonResponse(200, "Webhook removed", dataResponse),
+			invalidRequest, unauthorized, adminDisabled,
+			jsonResponse(404, "No webhook with this ID", dataResponse),
+		},
+	},
+	{
+		method: "GET", path: "/admin/webhooks/dead-letters", id: "listDeadLetters", tag: "Webhooks", admin: true, tenanted: true,
+		summary: "Deliveries that failed after every retry",
+		responses: []response{
+			jsonResponse(200, "Dead letters", listEnvelope("WebhookDeadLetter")),
+			unauthorized, adminDisabled,
+		},
+	},
+	{
+		method: "POST", path: "/admin/webhooks/dead-letters/replay", id: "replayDeadLetters", tag: "Webhooks", admin: true, tenanted: true,
+		summary: "Delivers dead letters again, all of them when the body is empty",
+		request: "WebhookReplayRequest", optionalBody: true,
+		responses: []response{
+			jsonResponse(202, "Dead letters queued for delivery, the ones of deleted subscriptions are skipped", envelope("WebhookReplayResult")),
+			invalidRequest, unauthorized, adminDisabled,
+			jsonResponse(415, "Request body is not JSON", dataResponse),
+		},
+	},
+	{
+		method: "GET", path: "/admin/tenants", id: "listTenants", tag: "Admin", admin: true,
+		summary: "Tenants with the pokemons they hold, their quota and the requests they served",
+		responses: []response{
+			jsonResponse(200, "Tenants", listEnvelope("TenantStats")),
+			unauthorized, adminDisabled,
+		},
+	},
+	{
+		method: "GET", path: "/admin/audit", id: "listAudit", tag: "Admin", admin: true, tenanted: true,
+		summary:    "Changes made to pokemons with who made them, the latest first",
+		parameters: auditParameters(queryParameter("limit", "Most entries returned, 100 by default", openapi3.NewIntegerSchema().WithMin(1).WithMax(1000))),
+		responses: []response{
+			jsonResponse(200, "Audit entries", listEnvelope("AuditEntry")),
+			invalidRequest, unauthorized, adminDisabled,
+			jsonResponse(422, "Invalid timestamp or limit", dataResponse),
+		},
+	},
+	{
+		method: "GET", path: "/admin/audit/export", id: "exportAudit", tag: "Admin", admin: true, tenanted: true,
+		summary:    "Exports every matching audit entry as NDJSON, oldest first",
+		parameters: auditParameters(),
+		responses: []response{
+			{status: 200, description: "One AuditEntry per line", mediaType: "application/x-ndjson", schema: component("AuditEntry")},
+			invalidRequest, unauthorized, adminDisabled,
+			jsonResponse(422, "Invalid timestamp", dataResponse),
+		},
+	},
+}
+
+// Filters of the audit log followed by extra
+func auditParameters(extra ...*openapi3.ParameterRef) openapi3.Parameters {
+	return append(openapi3.Parameters{
+		queryParameter("actor", "Only changes made by this actor", openapi3.NewStringSchema()),
+		queryParameter("action", "Only changes of this kind", openapi3.NewStringSchema().WithEnum("create", "update", "delete")),
+		queryParameter("pokemonId", "Only changes to this pokemon", openapi3.NewStringSchema()),
+		queryParameter("requestId", "Only changes made by this request", openapi3.NewStringSchema()),
+		queryParameter("since", "Only changes made at or after this RFC 3339 timestamp", openapi3.NewStringSchema()),
+		queryParameter("until", "Only changes made before this RFC 3339 timestamp", openapi3.NewStringSchema()),
+	}, extra...)
+}
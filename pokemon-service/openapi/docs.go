@@ -0,0 +1,10 @@
+package openapi
+
+import (
+	_ "embed"
+)
+
+// Documentation page rendering /openapi.json, embedded so the binary serves it without extra files
+//
+//go:embed docs.html
+var DocsPage []byte
@@ -0,0 +1,402 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	codec "pokemon-service/codec"
+	schema "pokemon-service/schema"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/openapi3gen"
+	"github.com/gorilla/mux"
+)
+
+const (
+	jsonMediaType       = "application/json"
+	mergePatchMediaType = "application/merge-patch+json"
+)
+
+var (
+	msgpackMediaType  = codec.MessagePack.MediaType()
+	protobufMediaType = codec.Protobuf.MediaType()
+	csvMediaType      = codec.CSV.MediaType()
+)
+
+func init() {
+	//Merge patches are JSON, so the request validator decodes them like JSON bodies
+	openapi3filter.RegisterBodyDecoder(mergePatchMediaType, openapi3filter.JSONBodyDecoder)
+	openapi3filter.RegisterBodyDecoder(msgpackMediaType, msgpackBodyDecoder)
+	openapi3filter.RegisterBodyDecoder(protobufMediaType, protobufBodyDecoder)
+}
+
+// MessagePack bodies decode to the same maps as their JSON counterparts
+func msgpackBodyDecoder(body io.Reader, _ http.Header, _ *openapi3.SchemaRef, _ openapi3filter.EncodingFn) (interface{}, error) {
+	var value interface{}
+	if err := codec.MessagePack.Decode(body, &value); err != nil {
+		return nil, &openapi3filter.ParseError{Kind: openapi3filter.KindInvalidFormat, Cause: err}
+	}
+	return value, nil
+}
+
+// Protobuf bodies carry no field names, the only ones accepted are pokemon records so they are validated
+// as the JSON encoding of the decoded record
+func protobufBodyDecoder(body io.Reader, _ http.Header, _ *openapi3.SchemaRef, _ openapi3filter.EncodingFn) (interface{}, error) {
+	var pokemon schema.Pokemon
+	if err := codec.Protobuf.Decode(body, &pokemon); err != nil {
+		return nil, &openapi3filter.ParseError{Kind: openapi3filter.KindInvalidFormat, Cause: err}
+	}
+	data, err := json.Marshal(pokemon)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	err = json.Unmarshal(data, &value)
+	return value, err
+}
+
+// Models described in components, generated from the schema package so the spec follows the structs
+var models = map[string]interface{}{
+	"Pokemon":              schema.Pokemon{},
+	"PokemonRequest":       schema.PokemonRequest{},
+	"PokemonPatch":         schema.PokemonPatch{},
+	"PokemonResponse":      schema.PokemonResponse{},
+	"DataResponse":         schema.DataResponse{},
+	"CacheStats":           schema.CacheStats{},
+	"CacheKeys":            schema.CacheKeys{},
+	"InvalidateRequest":    schema.InvalidateRequest{},
+	"InvalidateResult":     schema.InvalidateResult{},
+	"PokemonEvent":         schema.PokemonEvent{},
+	"WebhookSubscription":  schema.WebhookSubscription{},
+	"WebhookDeadLetter":    schema.WebhookDeadLetter{},
+	"WebhookReplayRequest": schema.WebhookReplayRequest{},
+	"WebhookReplayResult":  schema.WebhookReplayResult{},
+	"GraphQLRequest":       schema.GraphQLRequest{},
+	"BatchGetRequest":      schema.BatchGetRequest{},
+	"BatchGetResult":       schema.BatchGetResult{},
+	"Suggestion":           schema.Suggestion{},
+	"FacetCounts":          schema.FacetCounts{},
+	"EvolutionStage":       schema.EvolutionStage{},
+	"MatchupRequest":       schema.MatchupRequest{},
+	"Matchup":              schema.Matchup{},
+	"TrashedPokemon":       schema.TrashedPokemon{},
+	"AuditEntry":           schema.AuditEntry{},
+	"PokemonRevision":      schema.PokemonRevision{},
+	"RevertRequest":        schema.RevertRequest{},
+	"TenantStats":          schema.TenantStats{},
+}
+
+// Fields a request body has to carry, the generator cannot tell them from the structs
+var required = map[string][]string{
+	"Pokemon":             {"Name"},
+	"PokemonRequest":      {"Name"},
+	"WebhookSubscription": {"URL", "Events"},
+	"GraphQLRequest":      {"query"},
+	"MatchupRequest":      {"Attacker", "Defender"},
+	"RevertRequest":       {"Revision"},
+}
+
+// Builds the OpenAPI 3 document for every operation in the catalogue and validates it
+func Spec() (*openapi3.T, error) {
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info: &openapi3.Info{
+			Title:       "Pokemon Service",
+			Version:     "1.0.0",
+			Description: "Stores pokemon records in an in-memory cache and exposes them over REST, GraphQL, gRPC and event streams.",
+		},
+		//Every route is also served under the prefix of a tenant, see the TenantPrefix middleware
+		Servers: openapi3.Servers{
+			{URL: "/"},
+			{URL: "/tenants/{tenant}", Description: "Catalog of one tenant", Variables: map[string]*openapi3.ServerVariable{
+				"tenant": {Default: "default"},
+			}},
+		},
+		Paths: openapi3.NewPaths(),
+		Components: &openapi3.Components{
+			Schemas: openapi3.Schemas{},
+			SecuritySchemes: openapi3.SecuritySchemes{
+				"AdminToken": &openapi3.SecuritySchemeRef{Value: openapi3.NewSecurityScheme().
+					WithType("apiKey").WithIn("header").WithName("X-Admin-Token")},
+				"ApiKey": &openapi3.SecuritySchemeRef{Value: openapi3.NewSecurityScheme().
+					WithType("apiKey").WithIn("header").WithName("X-API-Key")},
+			},
+		},
+	}
+
+	for name, model := range models {
+		ref, err := openapi3gen.NewSchemaRefForValue(model, nil)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate schema %v: %w", name, err)
+		}
+		ref.Value.Required = required[name]
+		doc.Components.Schemas[name] = ref
+	}
+
+	for _, operation := range catalogue {
+		pathItem := doc.Paths.Value(operation.path)
+		if pathItem == nil {
+			pathItem = &openapi3.PathItem{}
+			doc.Paths.Set(operation.path, pathItem)
+		}
+		pathItem.SetOperation(operation.method, operation.build())
+	}
+
+	//Loading the serialised document resolves every reference, which validation and the request validator need
+	data, err := doc.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	loaded, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load OpenAPI document: %w", err)
+	}
+	if err := loaded.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	return loaded, nil
+}
+
+// Compares the routes registered on router with the documented operations, both ways,
+// so a route cannot be added or removed without updating the catalogue
+func CheckRouter(doc *openapi3.T, router *mux.Router) error {
+	routed := map[string]bool{}
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range methods {
+			routed[method+" "+path] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	documented := map[string]bool{}
+	for path, pathItem := range doc.Paths.Map() {
+		for method := range pathItem.Operations() {
+			documented[method+" "+path] = true
+		}
+	}
+
+	var problems []string
+	for route := range routed {
+		if !documented[route] {
+			problems = append(problems, "undocumented route "+route)
+		}
+	}
+	for route := range documented {
+		if !routed[route] {
+			problems = append(problems, "documented route not registered "+route)
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("router and OpenAPI document differ: %v", strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// Single documented route
+type operation struct {
+	method  string
+	path    string
+	id      string
+	summary string
+	tag     string
+	// Requires the admin token
+	admin bool
+	// Takes an X-API-Key header naming the actor changes are recorded under, see the Identify middleware
+	identified bool
+	// Served from the catalog of the tenant named in X-Tenant or by the /tenants/{tenant} path prefix,
+	// identified operations always are
+	tenanted bool
+	// Served by the legacy shim, responses carry Deprecation and Sunset headers
+	deprecated bool
+	// Response media type follows the Accept header, see the Negotiate middleware
+	negotiated bool
+	// Takes an Idempotency-Key header, see the Idempotent middleware
+	idempotent bool
+	parameters openapi3.Parameters
+	// Component describing the JSON request body, empty when there is none
+	request string
+	// Media types the request body is accepted in, application/json when empty
+	requestTypes []string
+	// Request body may be left out
+	optionalBody bool
+	responses    []response
+}
+
+type response struct {
+	status      int
+	description string
+	// Content type and schema of the body, a nil schema leaves the body undescribed
+	mediaType string
+	schema    *openapi3.SchemaRef
+	// Body is a list of pokemons, which negotiated operations also serve as CSV
+	list bool
+}
+
+func (operation operation) build() *openapi3.Operation {
+	built := &openapi3.Operation{
+		OperationID: operation.id,
+		Summary:     operation.summary,
+		Tags:        []string{operation.tag},
+		Parameters:  operation.parameters,
+		Responses:   openapi3.NewResponses(),
+		Deprecated:  operation.deprecated,
+	}
+	if operation.admin {
+		built.Security = &openapi3.SecurityRequirements{{"AdminToken": []string{}}}
+	}
+	if operation.identified {
+		//Callers without a key are anonymous, so the requirement is optional
+		built.Security = &openapi3.SecurityRequirements{{}, {"ApiKey": []string{}}}
+	}
+	if len(operation.request) > 0 {
+		requestTypes := operation.requestTypes
+		if len(requestTypes) == 0 {
+			requestTypes = []string{jsonMediaType}
+		}
+		built.RequestBody = &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().
+			WithRequired(!operation.optionalBody).
+			WithSchemaRef(component(operation.request), requestTypes)}
+	}
+	//NewResponses adds a default response, every status is listed explicitly instead
+	built.Responses.Delete("default")
+	responses := operation.responses
+	if operation.identified {
+		responses = append(responses,
+			jsonResponse(http.StatusUnauthorized, "X-API-Key is not a known key", dataResponse),
+			jsonResponse(http.StatusForbidden, "X-Tenant names a tenant the API key does not act in", dataResponse))
+	}
+	if operation.identified || operation.tenanted {
+		built.Parameters = append(append(openapi3.Parameters{}, built.Parameters...), &openapi3.ParameterRef{Value: openapi3.NewHeaderParameter("X-Tenant").
+			WithDescription("Tenant whose catalog serves the request, the one of the API key or the default tenant when left out").
+			WithSchema(openapi3.NewStringSchema().WithPattern("^[A-Za-z0-9_-]{1,64}$"))})
+		responses = append(responses, jsonResponse(http.StatusNotFound, "Tenant is not configured", dataResponse))
+	}
+	if operation.negotiated {
+		responses = append(responses, jsonResponse(http.StatusNotAcceptable, "None of the accepted media types can be produced", dataResponse))
+	}
+	if operation.idempotent {
+		built.Parameters = append(append(openapi3.Parameters{}, built.Parameters...), &openapi3.ParameterRef{Value: openapi3.NewHeaderParameter("Idempotency-Key").
+			WithDescription("Makes the request safe to retry, repeating it with the same key replays the first response").
+			WithSchema(openapi3.NewStringSchema().WithMinLength(1).WithMaxLength(255))})
+		responses = append(responses,
+			jsonResponse(http.StatusConflict, "A request with the same Idempotency-Key is still in progress", dataResponse),
+			jsonResponse(http.StatusUnprocessableEntity, "The Idempotency-Key was used for a different request", dataResponse))
+	}
+	for _, resp := range mergeResponses(responses) {
+		description := resp.description
+		value := &openapi3.Response{Description: &description}
+		if resp.schema != nil {
+			value.Content = openapi3.Content{resp.mediaType: openapi3.NewMediaType().WithSchemaRef(resp.schema)}
+		}
+		if operation.negotiated && resp.schema != nil && resp.status < 300 {
+			value.Content[msgpackMediaType] = openapi3.NewMediaType().WithSchemaRef(resp.schema)
+			value.Content[protobufMediaType] = openapi3.NewMediaType().WithSchema(protobufSchema)
+			if resp.list {
+				csvSchema := openapi3.NewStringSchema()
+				csvSchema.Description = "Header row followed by one row per pokemon"
+				value.Content[csvMediaType] = openapi3.NewMediaType().WithSchema(csvSchema)
+			}
+		}
+		if operation.deprecated {
+			value.Headers = openapi3.Headers{
+				"Deprecation": deprecationHeader("Date the route was deprecated, as @ followed by a Unix timestamp"),
+				"Sunset":      deprecationHeader("HTTP date after which the route is removed"),
+				"Link":        deprecationHeader("Route replacing this one, with rel=\"successor-version\""),
+			}
+		}
+		built.AddResponse(resp.status, value)
+	}
+	return built
+}
+
+// Joins the descriptions of responses listed more than once for the same status, keeping the first schema
+func mergeResponses(responses []response) []response {
+	var merged []response
+	index := map[int]int{}
+	for _, resp := range responses {
+		if i, ok := index[resp.status]; ok {
+			merged[i].description += ", or " + strings.ToLower(resp.description[:1]) + resp.description[1:]
+			continue
+		}
+		index[resp.status] = len(merged)
+		merged = append(merged, resp)
+	}
+	return merged
+}
+
+func deprecationHeader(description string) *openapi3.HeaderRef {
+	return &openapi3.HeaderRef{Value: &openapi3.Header{Parameter: openapi3.Parameter{
+		Description: description,
+		Schema:      openapi3.NewStringSchema().NewRef(),
+	}}}
+}
+
+func component(name string) *openapi3.SchemaRef {
+	return openapi3.NewSchemaRef("#/components/schemas/"+name, nil)
+}
+
+// DataResponse envelope whose Data holds the given component
+func envelope(data string) *openapi3.SchemaRef {
+	return dataEnvelope(component(data))
+}
+
+// DataResponse envelope whose Data holds a list of the given component
+func listEnvelope(item string) *openapi3.SchemaRef {
+	list := openapi3.NewArraySchema()
+	list.Items = component(item)
+	return dataEnvelope(list.NewRef())
+}
+
+func dataEnvelope(data *openapi3.SchemaRef) *openapi3.SchemaRef {
+	dataSchema := openapi3.NewObjectSchema()
+	dataSchema.Properties = openapi3.Schemas{"Data": data}
+	return openapi3.NewSchemaRef("", &openapi3.Schema{AllOf: openapi3.SchemaRefs{component("DataResponse"), dataSchema.NewRef()}})
+}
+
+func jsonResponse(status int, description string, schemaRef *openapi3.SchemaRef) response {
+	return response{status: status, description: description, mediaType: jsonMediaType, schema: schemaRef}
+}
+
+// JSON response holding a list of the given component in a DataResponse envelope
+func listResponse(status int, description string, item string) response {
+	resp := jsonResponse(status, description, listEnvelope(item))
+	resp.list = true
+	return resp
+}
+
+func pathParameter(name string, description string) *openapi3.ParameterRef {
+	return &openapi3.ParameterRef{Value: openapi3.NewPathParameter(name).WithDescription(description).WithSchema(openapi3.NewStringSchema().WithMinLength(1))}
+}
+
+func queryParameter(name string, description string, parameterSchema *openapi3.Schema) *openapi3.ParameterRef {
+	return &openapi3.ParameterRef{Value: openapi3.NewQueryParameter(name).WithDescription(description).WithSchema(parameterSchema)}
+}
+
+var (
+	pokemonResponse = component("PokemonResponse")
+	dataResponse    = component("DataResponse")
+	unauthorized    = jsonResponse(http.StatusUnauthorized, "Admin token is missing or wrong", dataResponse)
+	adminDisabled   = jsonResponse(http.StatusForbidden, "Admin API is disabled", dataResponse)
+	invalidRequest  = jsonResponse(http.StatusBadRequest, "Request does not match the specification", dataResponse)
+	// Envelope of negotiated protobuf responses, see pokemonpb/pokemon.proto
+	protobufSchema = &openapi3.Schema{Type: &openapi3.Types{openapi3.TypeString}, Format: "binary", Description: "pokemon.v1.HttpResponse message"}
+	// Media types pokemon records are accepted in
+	pokemonRequestTypes = []string{jsonMediaType, msgpackMediaType, protobufMediaType}
+)
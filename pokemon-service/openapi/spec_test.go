@@ -0,0 +1,60 @@
+package openapi
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestSpec(t *testing.T) {
+	doc, err := Spec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Pokemon", "PokemonRequest", "PokemonResponse"} {
+		if doc.Components.Schemas[name] == nil {
+			t.Errorf("schema %v missing from components", name)
+		}
+	}
+	//Properties come from the json tags of the schema structs, embedded structs are flattened
+	response := doc.Components.Schemas["PokemonResponse"].Value
+	for _, property := range []string{"ID", "Name", "RequestID", "RespMessage", "RespCode", "Latency"} {
+		if response.Properties[property] == nil {
+			t.Errorf("property %v missing from PokemonResponse", property)
+		}
+	}
+	if operation := doc.Paths.Find("/pokemon-service/getByID/{Id}").Get; operation == nil || operation.Responses.Status(404) == nil {
+		t.Errorf("getByID is not documented with its 404 response")
+	}
+}
+
+func TestCheckRouter(t *testing.T) {
+	doc, err := Spec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := func(w http.ResponseWriter, req *http.Request) {}
+
+	router := mux.NewRouter()
+	for _, operation := range catalogue {
+		router.HandleFunc(operation.path, handler).Methods(operation.method)
+	}
+	if err := CheckRouter(doc, router); err != nil {
+		t.Errorf("unexpected error for matching router: %v", err)
+	}
+
+	router.HandleFunc("/pokemon-service/undocumented", handler).Methods("GET")
+	err = CheckRouter(doc, router)
+	if err == nil || !strings.Contains(err.Error(), "undocumented route GET /pokemon-service/undocumented") {
+		t.Errorf("undocumented route not reported: %v", err)
+	}
+
+	partial := mux.NewRouter()
+	partial.HandleFunc("/health-check", handler).Methods("GET")
+	err = CheckRouter(doc, partial)
+	if err == nil || !strings.Contains(err.Error(), "documented route not registered POST /pokemon-service/Add") {
+		t.Errorf("missing route not reported: %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+// Validator checks requests against the operation the document declares for them
+type Validator struct {
+	router routers.Router
+}
+
+func NewValidator(doc *openapi3.T) (*Validator, error) {
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &Validator{router: router}, nil
+}
+
+// Validates path and query parameters, headers and the body of req. Requests the document does not
+// know pass, routing them is left to the router. A body sent without Content-Type is validated as JSON,
+// the handlers always decoded it that way. Errors wrap ErrUnsupportedMediaType when the body is in a media type the operation does not take.
+func (validator *Validator) Validate(req *http.Request) error {
+	route, pathParams, err := validator.router.FindRoute(req)
+	if err != nil {
+		return nil
+	}
+
+	if body := route.Operation.RequestBody; body != nil && req.ContentLength != 0 {
+		contentType := req.Header.Get("Content-Type")
+		if len(contentType) <= 0 {
+			req.Header.Set("Content-Type", jsonMediaType)
+		} else if mediaType, _, err := mime.ParseMediaType(contentType); err != nil || body.Value.Content.Get(mediaType) == nil {
+			var accepted []string
+			for mediaType := range body.Value.Content {
+				accepted = append(accepted, mediaType)
+			}
+			sort.Strings(accepted)
+			return fmt.Errorf("%w %q, expected one of %v", ErrUnsupportedMediaType, contentType, strings.Join(accepted, ", "))
+		}
+	}
+
+	return openapi3filter.ValidateRequest(req.Context(), &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: pathParams,
+		Route:      route,
+		Options: &openapi3filter.Options{
+			//Admin token is checked by the AdminOnly middleware
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		},
+	})
+}
@@ -0,0 +1,70 @@
+package openapi
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	doc, err := Spec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator, err := NewValidator(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inputs := []struct {
+		testName    string
+		method      string
+		url         string
+		contentType string
+		body        string
+		valid       bool
+		unsupported bool
+	}{
+		{testName: "TestValidAdd", method: "POST", url: "/pokemon-service/Add", contentType: "application/json", body: `{"ID":"PK10001","Name":"Picachoo1","Height":"20.9"}`, valid: true},
+		{testName: "TestAddWithoutContentType", method: "POST", url: "/pokemon-service/Add", body: `{"ID":"PK10001","Name":"Picachoo1"}`, valid: true},
+		{testName: "TestAddWithoutId", method: "POST", url: "/pokemon-service/Add", contentType: "application/json", body: `{"Name":"Picachoo1"}`, valid: true},
+		{testName: "TestAddMissingName", method: "POST", url: "/pokemon-service/Add", contentType: "application/json", body: `{"ID":"PK10001"}`},
+		{testName: "TestAddWrongType", method: "POST", url: "/pokemon-service/Add", contentType: "application/json", body: `{"ID":"PK10001","Name":"Picachoo1","Height":20.9}`},
+		{testName: "TestAddNotJson", method: "POST", url: "/pokemon-service/Add", contentType: "text/plain", body: `ID=PK10001`, unsupported: true},
+		{testName: "TestAddEmptyBody", method: "POST", url: "/pokemon-service/Add", contentType: "application/json"},
+		{testName: "TestValidGetByID", method: "GET", url: "/pokemon-service/getByID/PK10001", valid: true},
+		{testName: "TestInvalidLimit", method: "GET", url: "/admin/cache/keys?limit=abc"},
+		{testName: "TestReplayWithoutBody", method: "POST", url: "/admin/webhooks/dead-letters/replay", valid: true},
+		{testName: "TestUnknownRoute", method: "GET", url: "/pokemon-service/unknown/route", valid: true},
+	}
+
+	for _, item := range inputs {
+		var body io.Reader = http.NoBody
+		if len(item.body) > 0 {
+			body = strings.NewReader(item.body)
+		}
+		req, err := http.NewRequest(item.method, item.url, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(item.contentType) > 0 {
+			req.Header.Set("Content-Type", item.contentType)
+		}
+
+		err = validator.Validate(req)
+		if valid := err == nil; valid != item.valid {
+			t.Errorf("%v: valid %v want %v, error: %v", item.testName, valid, item.valid, err)
+		}
+		if unsupported := errors.Is(err, ErrUnsupportedMediaType); unsupported != item.unsupported {
+			t.Errorf("%v: unsupported media type %v want %v", item.testName, unsupported, item.unsupported)
+		}
+		//Handlers still have to be able to read the body
+		if item.valid && len(item.body) > 0 {
+			if data, _ := io.ReadAll(req.Body); string(data) != item.body {
+				t.Errorf("%v: body not restored: %q", item.testName, data)
+			}
+		}
+	}
+}
@@ -0,0 +1,365 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: pokemonpb/pokemon.proto
+
+package pokemonpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	PokemonService_GetByID_FullMethodName   = "/pokemon.v1.PokemonService/GetByID"
+	PokemonService_GetByName_FullMethodName = "/pokemon.v1.PokemonService/GetByName"
+	PokemonService_Add_FullMethodName       = "/pokemon.v1.PokemonService/Add"
+	PokemonService_Update_FullMethodName    = "/pokemon.v1.PokemonService/Update"
+	PokemonService_Delete_FullMethodName    = "/pokemon.v1.PokemonService/Delete"
+	PokemonService_List_FullMethodName      = "/pokemon.v1.PokemonService/List"
+	PokemonService_Watch_FullMethodName     = "/pokemon.v1.PokemonService/Watch"
+)
+
+// PokemonServiceClient is the client API for PokemonService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PokemonServiceClient interface {
+	GetByID(ctx context.Context, in *GetByIDRequest, opts ...grpc.CallOption) (*PokemonReply, error)
+	GetByName(ctx context.Context, in *GetByNameRequest, opts ...grpc.CallOption) (*PokemonReply, error)
+	// Creates the pokemon, ALREADY_EXISTS when its ID or name is taken.
+	Add(ctx context.Context, in *AddRequest, opts ...grpc.CallOption) (*PokemonReply, error)
+	// Replaces an existing pokemon, NOT_FOUND when its ID is unknown.
+	Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*PokemonReply, error)
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*PokemonReply, error)
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListReply, error)
+	// Streams changes to the catalog until the client cancels or the server shuts down.
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (PokemonService_WatchClient, error)
+}
+
+type pokemonServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPokemonServiceClient(cc grpc.ClientConnInterface) PokemonServiceClient {
+	return &pokemonServiceClient{cc}
+}
+
+func (c *pokemonServiceClient) GetByID(ctx context.Context, in *GetByIDRequest, opts ...grpc.CallOption) (*PokemonReply, error) {
+	out := new(PokemonReply)
+	err := c.cc.Invoke(ctx, PokemonService_GetByID_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pokemonServiceClient) GetByName(ctx context.Context, in *GetByNameRequest, opts ...grpc.CallOption) (*PokemonReply, error) {
+	out := new(PokemonReply)
+	err := c.cc.Invoke(ctx, PokemonService_GetByName_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pokemonServiceClient) Add(ctx context.Context, in *AddRequest, opts ...grpc.CallOption) (*PokemonReply, error) {
+	out := new(PokemonReply)
+	err := c.cc.Invoke(ctx, PokemonService_Add_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pokemonServiceClient) Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*PokemonReply, error) {
+	out := new(PokemonReply)
+	err := c.cc.Invoke(ctx, PokemonService_Update_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pokemonServiceClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*PokemonReply, error) {
+	out := new(PokemonReply)
+	err := c.cc.Invoke(ctx, PokemonService_Delete_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pokemonServiceClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListReply, error) {
+	out := new(ListReply)
+	err := c.cc.Invoke(ctx, PokemonService_List_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pokemonServiceClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (PokemonService_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &PokemonService_ServiceDesc.Streams[0], PokemonService_Watch_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &pokemonServiceWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type PokemonService_WatchClient interface {
+	Recv() (*PokemonEvent, error)
+	grpc.ClientStream
+}
+
+type pokemonServiceWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *pokemonServiceWatchClient) Recv() (*PokemonEvent, error) {
+	m := new(PokemonEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PokemonServiceServer is the server API for PokemonService service.
+// All implementations must embed UnimplementedPokemonServiceServer
+// for forward compatibility
+type PokemonServiceServer interface {
+	GetByID(context.Context, *GetByIDRequest) (*PokemonReply, error)
+	GetByName(context.Context, *GetByNameRequest) (*PokemonReply, error)
+	// Creates the pokemon, ALREADY_EXISTS when its ID or name is taken.
+	Add(context.Context, *AddRequest) (*PokemonReply, error)
+	// Replaces an existing pokemon, NOT_FOUND when its ID is unknown.
+	Update(context.Context, *UpdateRequest) (*PokemonReply, error)
+	Delete(context.Context, *DeleteRequest) (*PokemonReply, error)
+	List(context.Context, *ListRequest) (*ListReply, error)
+	// Streams changes to the catalog until the client cancels or the server shuts down.
+	Watch(*WatchRequest, PokemonService_WatchServer) error
+	mustEmbedUnimplementedPokemonServiceServer()
+}
+
+// UnimplementedPokemonServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedPokemonServiceServer struct {
+}
+
+func (UnimplementedPokemonServiceServer) GetByID(context.Context, *GetByIDRequest) (*PokemonReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetByID not implemented")
+}
+func (UnimplementedPokemonServiceServer) GetByName(context.Context, *GetByNameRequest) (*PokemonReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetByName not implemented")
+}
+func (UnimplementedPokemonServiceServer) Add(context.Context, *AddRequest) (*PokemonReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Add not implemented")
+}
+func (UnimplementedPokemonServiceServer) Update(context.Context, *UpdateRequest) (*PokemonReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedPokemonServiceServer) Delete(context.Context, *DeleteRequest) (*PokemonReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedPokemonServiceServer) List(context.Context, *ListRequest) (*ListReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedPokemonServiceServer) Watch(*WatchRequest, PokemonService_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedPokemonServiceServer) mustEmbedUnimplementedPokemonServiceServer() {}
+
+// UnsafePokemonServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PokemonServiceServer will
+// result in compilation errors.
+type UnsafePokemonServiceServer interface {
+	mustEmbedUnimplementedPokemonServiceServer()
+}
+
+func RegisterPokemonServiceServer(s grpc.ServiceRegistrar, srv PokemonServiceServer) {
+	s.RegisterService(&PokemonService_ServiceDesc, srv)
+}
+
+func _PokemonService_GetByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetByIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PokemonServiceServer).GetByID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PokemonService_GetByID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PokemonServiceServer).GetByID(ctx, req.(*GetByIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PokemonService_GetByName_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetByNameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PokemonServiceServer).GetByName(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PokemonService_GetByName_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PokemonServiceServer).GetByName(ctx, req.(*GetByNameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PokemonService_Add_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PokemonServiceServer).Add(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PokemonService_Add_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PokemonServiceServer).Add(ctx, req.(*AddRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PokemonService_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PokemonServiceServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PokemonService_Update_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PokemonServiceServer).Update(ctx, req.(*UpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PokemonService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PokemonServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PokemonService_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PokemonServiceServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PokemonService_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PokemonServiceServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PokemonService_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PokemonServiceServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PokemonService_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PokemonServiceServer).Watch(m, &pokemonServiceWatchServer{stream})
+}
+
+type PokemonService_WatchServer interface {
+	Send(*PokemonEvent) error
+	grpc.ServerStream
+}
+
+type pokemonServiceWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *pokemonServiceWatchServer) Send(m *PokemonEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// PokemonService_ServiceDesc is the grpc.ServiceDesc for PokemonService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PokemonService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pokemon.v1.PokemonService",
+	HandlerType: (*PokemonServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetByID",
+			Handler:    _PokemonService_GetByID_Handler,
+		},
+		{
+			MethodName: "GetByName",
+			Handler:    _PokemonService_GetByName_Handler,
+		},
+		{
+			MethodName: "Add",
+			Handler:    _PokemonService_Add_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _PokemonService_Update_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _PokemonService_Delete_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _PokemonService_List_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _PokemonService_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pokemonpb/pokemon.proto",
+}
@@ -0,0 +1,33 @@
+package schema
+
+// Snapshot of cache internals exposed through the admin API
+type CacheStats struct {
+	Entries       int              `json:"Entries"`
+	Hits          int64            `json:"Hits"`
+	Misses        int64            `json:"Misses"`
+	DelHits       int64            `json:"DelHits"`
+	DelMisses     int64            `json:"DelMisses"`
+	Collisions    int64            `json:"Collisions"`
+	CapacityBytes int              `json:"CapacityBytes"`
+	Evictions     map[string]int64 `json:"Evictions"`
+}
+
+// Keys currently held in cache, optionally filtered by prefix
+type CacheKeys struct {
+	Prefix string   `json:"Prefix,omitempty"`
+	Count  int      `json:"Count"`
+	Keys   []string `json:"Keys"`
+}
+
+// Targeted invalidation request, every listed ID, name and type is removed from cache
+type InvalidateRequest struct {
+	IDs   []string `json:"IDs,omitempty"`
+	Names []string `json:"Names,omitempty"`
+	Types []string `json:"Types,omitempty"`
+}
+
+// Outcome of an invalidation or flush
+type InvalidateResult struct {
+	Pokemons    []string `json:"Pokemons"`
+	KeysRemoved int      `json:"KeysRemoved"`
+}
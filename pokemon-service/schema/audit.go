@@ -0,0 +1,21 @@
+package schema
+
+// Actions recorded in the audit log
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// Change made to a pokemon as recorded in the audit log. Before is left out for creates and After for deletes.
+type AuditEntry struct {
+	// Position in the log, increasing by one per entry
+	Sequence  uint64   `json:"Sequence"`
+	Action    string   `json:"Action"`
+	PokemonId string   `json:"PokemonID"`
+	Actor     string   `json:"Actor"`
+	Before    *Pokemon `json:"Before,omitempty"`
+	After     *Pokemon `json:"After,omitempty"`
+	RequestId string   `json:"RequestID,omitempty"`
+	Timestamp string   `json:"Timestamp"`
+}
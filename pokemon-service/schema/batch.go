@@ -0,0 +1,15 @@
+package schema
+
+// Keys fetched in one batch get, IDs and names can be mixed
+type BatchGetRequest struct {
+	IDs   []string `json:"IDs,omitempty"`
+	Names []string `json:"Names,omitempty"`
+}
+
+// Outcome of a batch get, every requested key either resolved to one of Pokemons or is listed in Errors
+type BatchGetResult struct {
+	// Found records in request order, a record asked for by ID and by name is listed once
+	Pokemons []Pokemon `json:"Pokemons"`
+	// Reason per requested key that was not found or could not be read
+	Errors map[string]string `json:"Errors"`
+}
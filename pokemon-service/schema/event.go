@@ -0,0 +1,31 @@
+package schema
+
+// Kinds of catalog changes published to subscribers
+const (
+	EventCreated = "pokemon.created"
+	EventUpdated = "pokemon.updated"
+	EventDeleted = "pokemon.deleted"
+	EventEvicted = "pokemon.evicted"
+)
+
+// Change to a pokemon record, as delivered to webhooks and other subscribers
+type PokemonEvent struct {
+	EventId string `json:"EventID"`
+	// Position of the event in publishing order, used to resume change streams
+	Sequence   uint64   `json:"Sequence"`
+	Type       string   `json:"Type"`
+	PokemonId  string   `json:"PokemonID"`
+	Pokemon    *Pokemon `json:"Pokemon,omitempty"`
+	RequestId  string   `json:"RequestID,omitempty"`
+	OccurredAt string   `json:"OccurredAt"`
+	// Caller that made the change and the record before an update, only for subscribers in the service
+	// like the audit log. They are left out of the events sent to clients.
+	Actor    string   `json:"-"`
+	Previous *Pokemon `json:"-"`
+}
+
+// Request a change was made in and the caller that made it, written to the events of the change
+type Origin struct {
+	RequestId string
+	Actor     string
+}
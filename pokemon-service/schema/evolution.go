@@ -0,0 +1,15 @@
+package schema
+
+// Link to the pokemon an evolution starts from, reached at Level, on a Condition like "Thunder Stone" or both
+type Evolution struct {
+	Id        string `json:"ID"`
+	Level     int    `json:"Level,omitempty"`
+	Condition string `json:"Condition,omitempty"`
+}
+
+// Stage of an evolution chain, the chain is the tree of stages starting at the first one
+type EvolutionStage struct {
+	Pokemon Pokemon `json:"Pokemon"`
+	// Pokemons evolving from this stage ordered by ID, several when the chain branches
+	EvolvesTo []EvolutionStage `json:"EvolvesTo"`
+}
@@ -0,0 +1,8 @@
+package schema
+
+// GraphQL request body, field names follow the GraphQL over HTTP convention
+type GraphQLRequest struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	OperationName string                 `json:"operationName,omitempty"`
+}
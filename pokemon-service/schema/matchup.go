@@ -0,0 +1,33 @@
+package schema
+
+// Two pokemons to match up by ID or name. Level and Power describe the moves the damage estimate assumes,
+// defaults are used when they are left out.
+type MatchupRequest struct {
+	Attacker string `json:"Attacker"`
+	Defender string `json:"Defender"`
+	Level    int    `json:"Level,omitempty"`
+	Power    int    `json:"Power,omitempty"`
+}
+
+// How two pokemons fare against each other, judged by their Type fields
+type Matchup struct {
+	Attacker           Pokemon       `json:"Attacker"`
+	Defender           Pokemon       `json:"Defender"`
+	AttackerToDefender Effectiveness `json:"AttackerToDefender"`
+	DefenderToAttacker Effectiveness `json:"DefenderToAttacker"`
+	// Types of either pokemon missing from the type chart, they are treated as neutral
+	UnknownTypes []string `json:"UnknownTypes"`
+	Level        int      `json:"Level"`
+	Power        int      `json:"Power"`
+}
+
+// Moves of one pokemon against the other, one move of each of its types or a typeless one when it has none
+type Effectiveness struct {
+	// Multiplier of the move of each type
+	Multipliers map[string]float64 `json:"Multipliers"`
+	// Type of the most effective move, empty for a typeless one
+	BestType   string  `json:"BestType"`
+	Multiplier float64 `json:"Multiplier"`
+	// Damage of the most effective move for equal attack and defense stats, with the same type attack bonus
+	Damage float64 `json:"Damage"`
+}
@@ -7,6 +7,8 @@ type Pokemon struct {
 	Height    string `json:"Height"`
 	Weight    string `json:"Weight"`
 	Abilities string `json:"Abilities"`
+	// Earlier stage of the evolution chain, nil for the first stage
+	EvolvesFrom *Evolution `json:"EvolvesFrom,omitempty"`
 }
 type PokemonRequest struct {
 	Pokemon
@@ -19,3 +21,16 @@ type PokemonResponse struct {
 	RespCode    int    `json:"RespCode"`
 	Latency     string `json:"Latency"`
 }
+
+// Partial update applied with JSON merge patch semantics: absent fields are kept and null clears a field.
+// The ID can be repeated but not changed.
+type PokemonPatch struct {
+	Id        *string `json:"ID,omitempty"`
+	Name      *string `json:"Name,omitempty"`
+	Type      *string `json:"Type,omitempty"`
+	Height    *string `json:"Height,omitempty"`
+	Weight    *string `json:"Weight,omitempty"`
+	Abilities *string `json:"Abilities,omitempty"`
+	// Members sent are merged into the current evolution, null makes the pokemon a first stage
+	EvolvesFrom *Evolution `json:"EvolvesFrom,omitempty"`
+}
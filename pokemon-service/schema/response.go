@@ -0,0 +1,11 @@
+package schema
+
+// Generic response envelope for endpoints that do not return a single pokemon record
+type DataResponse struct {
+	RequestId   string      `json:"RequestID,omitempty"`
+	RequestTs   string      `json:"RequestTS,omitempty"`
+	RespMessage string      `json:"RespMessage"`
+	RespCode    int         `json:"RespCode"`
+	Latency     string      `json:"Latency"`
+	Data        interface{} `json:"Data,omitempty"`
+}
@@ -0,0 +1,19 @@
+package schema
+
+// Version of a pokemon left by a change, as kept in its history
+type PokemonRevision struct {
+	// Counts the changes recorded for the pokemon, starting at 1
+	Revision uint64 `json:"Revision"`
+	// Type of the event the change was published with
+	Event string `json:"Event"`
+	// Record after the change, left out when the change deleted or evicted it
+	Pokemon    *Pokemon `json:"Pokemon,omitempty"`
+	Actor      string   `json:"Actor,omitempty"`
+	RequestId  string   `json:"RequestID,omitempty"`
+	RecordedAt string   `json:"RecordedAt"`
+}
+
+// Revision a pokemon is reverted to
+type RevertRequest struct {
+	Revision uint64 `json:"Revision"`
+}
@@ -0,0 +1,15 @@
+package schema
+
+// Name completing a typed prefix, Hits is how often the pokemon was looked up since it was indexed
+type Suggestion struct {
+	Id   string `json:"ID"`
+	Name string `json:"Name"`
+	Hits uint64 `json:"Hits"`
+}
+
+// Number of pokemons per type and per ability, a pokemon with two types counts towards both
+type FacetCounts struct {
+	Pokemons  int            `json:"Pokemons"`
+	Types     map[string]int `json:"Types"`
+	Abilities map[string]int `json:"Abilities"`
+}
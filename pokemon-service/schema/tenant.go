@@ -0,0 +1,15 @@
+package schema
+
+// Usage of one tenant's catalog and the requests it served
+type TenantStats struct {
+	Tenant   string `json:"Tenant"`
+	Pokemons int    `json:"Pokemons"`
+	// Pokemons the tenant may hold, 0 when there is no limit
+	Quota int `json:"Quota"`
+	// Requests served from the tenant's catalog, and how many of them failed on the client or server side
+	Requests     uint64 `json:"Requests"`
+	ClientErrors uint64 `json:"ClientErrors"`
+	ServerErrors uint64 `json:"ServerErrors"`
+	CacheHits    int64  `json:"CacheHits"`
+	CacheMisses  int64  `json:"CacheMisses"`
+}
@@ -0,0 +1,10 @@
+package schema
+
+// Deleted pokemon kept in the trash, it can be restored until it is purged at PurgeAt
+type TrashedPokemon struct {
+	Pokemon   Pokemon `json:"Pokemon"`
+	DeletedAt string  `json:"DeletedAt"`
+	PurgeAt   string  `json:"PurgeAt"`
+	// Request that deleted the pokemon
+	RequestId string `json:"RequestID,omitempty"`
+}
@@ -0,0 +1,31 @@
+package schema
+
+// Actions a watch client sends over the websocket
+const (
+	WatchSubscribe   = "subscribe"
+	WatchUnsubscribe = "unsubscribe"
+)
+
+// Types of server messages besides catalog event types
+const (
+	WatchSnapshot = "snapshot"
+	WatchNotFound = "not_found"
+	WatchError    = "error"
+)
+
+// Client message changing the set of watched pokemons
+type WatchRequest struct {
+	Action string   `json:"Action"`
+	IDs    []string `json:"IDs,omitempty"`
+	Names  []string `json:"Names,omitempty"`
+}
+
+// Server message, either the current record of a newly watched pokemon, a change to a
+// watched one (carrying the event type) or an error caused by the last client message
+type WatchMessage struct {
+	Type      string   `json:"Type"`
+	PokemonId string   `json:"PokemonID,omitempty"`
+	Pokemon   *Pokemon `json:"Pokemon,omitempty"`
+	Sequence  uint64   `json:"Sequence,omitempty"`
+	Message   string   `json:"Message,omitempty"`
+}
@@ -0,0 +1,33 @@
+package schema
+
+// Callback URL registered for a set of event types
+type WebhookSubscription struct {
+	Id     string   `json:"ID"`
+	URL    string   `json:"URL"`
+	Events []string `json:"Events"`
+	// Shared secret used to sign deliveries, only returned when the subscription is created
+	Secret    string `json:"Secret,omitempty"`
+	CreatedAt string `json:"CreatedAt"`
+}
+
+// Delivery that kept failing after every retry
+type WebhookDeadLetter struct {
+	Id             string       `json:"ID"`
+	SubscriptionId string       `json:"SubscriptionID"`
+	URL            string       `json:"URL"`
+	Event          PokemonEvent `json:"Event"`
+	Attempts       int          `json:"Attempts"`
+	LastError      string       `json:"LastError"`
+	FailedAt       string       `json:"FailedAt"`
+}
+
+// Dead letters to deliver again, every dead letter is replayed when IDs is empty
+type WebhookReplayRequest struct {
+	IDs []string `json:"IDs,omitempty"`
+}
+
+// Outcome of a replay, dead letters of deleted subscriptions are skipped and stay listed
+type WebhookReplayResult struct {
+	Replayed []WebhookDeadLetter `json:"Replayed"`
+	Skipped  []WebhookDeadLetter `json:"Skipped"`
+}
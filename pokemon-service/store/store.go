@@ -0,0 +1,569 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	codec "pokemon-service/codec"
+	events "pokemon-service/events"
+	eviction "pokemon-service/eviction"
+	idgen "pokemon-service/idgen"
+	index "pokemon-service/index"
+	schema "pokemon-service/schema"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/allegro/bigcache/v3"
+)
+
+// IDs taken by other records the allocator may hand out in a row before creating fails
+const allocateAttempts = 1000
+
+// Stages an evolution chain may have, walking a chain stops there even if the records say otherwise
+const maxEvolutionStages = 32
+
+var (
+	ErrNotFound  = errors.New("pokemon not found")
+	ErrMissingId = errors.New("pokemon ID is expected")
+	ErrExists    = errors.New("pokemon already exists")
+	ErrIdChanged = errors.New("pokemon ID cannot be changed")
+	// Wrapped with the reason when EvolvesFrom does not name an earlier stage that exists
+	ErrInvalidEvolution = errors.New("invalid evolution")
+	// Wrapped with the IDs of the later stages when deleting a pokemon others evolve from
+	ErrHasEvolutions = errors.New("pokemon has evolutions")
+	// Wrapped with the quota when creating a record would hold more pokemons than the store may
+	ErrQuotaExceeded = errors.New("pokemon quota exceeded")
+)
+
+// Returned when the ID or name of a record is a key already holding a different record, wraps ErrExists
+type ConflictError struct {
+	Key string
+	// Record stored under Key
+	Existing schema.Pokemon
+}
+
+func (err *ConflictError) Error() string {
+	return fmt.Sprintf("key %v is taken by pokemon %v", err.Key, err.Existing.Id)
+}
+
+func (err *ConflictError) Unwrap() error {
+	return ErrExists
+}
+
+// Index is an in-memory view of the records, the store keeps it up to date on every write and removal
+type Index interface {
+	Put(pokemon schema.Pokemon)
+	Remove(pokemon schema.Pokemon)
+	Reset()
+}
+
+// Store keeps pokemon records in bigcache, every record under its ID and under its name.
+// It is shared by the REST handlers and the gRPC server, so both read and write the same data
+// and every write is published on the event bus exactly once.
+type Store struct {
+	cache   *bigcache.BigCache
+	events  *events.Bus
+	records codec.RecordCodec
+	// Allocates IDs of records created without one, nil when IDs are required
+	ids     idgen.Allocator
+	indexes []Index
+	// Pokemons evolving from each pokemon, for keeping chains intact
+	evolutions *index.Evolutions
+	// IDs of the stored pokemons, for counting them against the quota
+	members *index.Members
+	// Normalized names of the stored pokemons, for lookups by name
+	names *index.Names
+	// Pokemons the store may hold, 0 when there is no limit
+	quota int
+	// Serialises writes, so checks on existing keys and the writes depending on them do not interleave
+	mutex sync.Mutex
+}
+
+// Wraps cache, changes are published on bus which may be nil. Records are stored in the format of records,
+// JSON when it is nil. Records created without an ID get one from ids, when it is nil an ID is required.
+func New(cache *bigcache.BigCache, bus *events.Bus, records codec.RecordCodec, ids idgen.Allocator) *Store {
+	if records == nil {
+		records = codec.JSONRecords
+	}
+	store := &Store{cache: cache, events: bus, records: records, ids: ids, evolutions: index.NewEvolutions(), members: index.NewMembers(), names: index.NewNames()}
+	store.AddIndex(store.evolutions)
+	store.AddIndex(store.members)
+	store.AddIndex(store.names)
+	return store
+}
+
+// Limits the pokemons the store holds, creating records beyond it fails with ErrQuotaExceeded.
+// 0 removes the limit. Records already stored are kept even when there are more than quota.
+func (store *Store) SetQuota(quota int) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	store.quota = quota
+}
+
+// Pokemons the store may hold, 0 when there is no limit
+func (store *Store) Quota() int {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	return store.quota
+}
+
+// Number of stored pokemons
+func (store *Store) Count() int {
+	return store.members.Len()
+}
+
+// Fills index with the stored records and keeps it up to date from now on
+func (store *Store) AddIndex(index Index) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	index.Reset()
+	for _, pokemon := range store.List(nil) {
+		index.Put(pokemon)
+	}
+	store.indexes = append(store.indexes, index)
+}
+
+// Reads the record stored under an ID or name
+func (store *Store) Get(key string) (schema.Pokemon, error) {
+	var pokemon schema.Pokemon
+	data, err := store.cache.Get(key)
+	if errors.Is(err, bigcache.ErrEntryNotFound) {
+		return pokemon, ErrNotFound
+	}
+	if err != nil {
+		return pokemon, err
+	}
+	err = store.records.Unmarshal(data, &pokemon)
+	return pokemon, err
+}
+
+// Reads the record carrying name, names that differ from it only in case, accents or whitespace match too.
+// Distinct names can normalize to the same one, the lowest ID wins so the answer does not change between calls.
+func (store *Store) GetByName(name string) (schema.Pokemon, error) {
+	pokemon, err := store.Get(name)
+	if err == nil && pokemon.Name == name {
+		return pokemon, nil
+	}
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return pokemon, err
+	}
+	for _, id := range store.names.Lookup(name) {
+		pokemon, err := store.Get(id)
+		if err == nil && pokemon.Id == id {
+			return pokemon, nil
+		}
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return pokemon, err
+		}
+	}
+	return schema.Pokemon{}, ErrNotFound
+}
+
+// Normalized names of the stored pokemons, kept up to date by the store
+func (store *Store) Names() *index.Names {
+	return store.names
+}
+
+// Stores pokemon, overwriting a record with the same ID. Returns true when no record existed before.
+// A ConflictError is returned when its name belongs to a different record.
+func (store *Store) Add(pokemon schema.Pokemon, origin schema.Origin) (bool, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if err := store.checkKeys(pokemon); err != nil {
+		return false, err
+	}
+	if err := store.checkEvolution(pokemon); err != nil {
+		return false, err
+	}
+	existing, err := store.Get(pokemon.Id)
+	created := errors.Is(err, ErrNotFound)
+	if created {
+		if err := store.checkQuota(); err != nil {
+			return false, err
+		}
+	}
+	if err := store.write(pokemon); err != nil {
+		return false, err
+	}
+	if created {
+		store.publish(schema.EventCreated, pokemon, nil, origin)
+		return true, nil
+	}
+	store.dropName(existing, pokemon)
+	store.publish(schema.EventUpdated, pokemon, &existing, origin)
+	return false, nil
+}
+
+// Stores a new record and returns it, a ConflictError when its ID or name is already the key of a stored record.
+// Records without an ID get the next free one from the allocator, ErrMissingId when there is none.
+func (store *Store) Create(pokemon schema.Pokemon, origin schema.Origin) (schema.Pokemon, error) {
+	if len(pokemon.Id) <= 0 && store.ids == nil {
+		return pokemon, ErrMissingId
+	}
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if len(pokemon.Id) <= 0 {
+		id, err := store.allocate()
+		if err != nil {
+			return pokemon, err
+		}
+		pokemon.Id = id
+	} else if existing, err := store.Get(pokemon.Id); err == nil {
+		return pokemon, &ConflictError{Key: pokemon.Id, Existing: existing}
+	} else if !errors.Is(err, ErrNotFound) {
+		return pokemon, err
+	}
+	if err := store.checkKeys(pokemon); err != nil {
+		return pokemon, err
+	}
+	if err := store.checkEvolution(pokemon); err != nil {
+		return pokemon, err
+	}
+	//Conflicts are reported first, the quota only matters once a record would be stored
+	if err := store.checkQuota(); err != nil {
+		return pokemon, err
+	}
+	if err := store.write(pokemon); err != nil {
+		return pokemon, err
+	}
+	store.publish(schema.EventCreated, pokemon, nil, origin)
+	return pokemon, nil
+}
+
+// Replaces an existing record, ErrNotFound when its ID is unknown and a ConflictError when its new name
+// belongs to a different record
+func (store *Store) Update(pokemon schema.Pokemon, origin schema.Origin) error {
+	if len(pokemon.Id) <= 0 {
+		return ErrMissingId
+	}
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	existing, err := store.Get(pokemon.Id)
+	if err != nil {
+		return err
+	}
+	if err := store.checkKeys(pokemon); err != nil {
+		return err
+	}
+	if err := store.checkEvolution(pokemon); err != nil {
+		return err
+	}
+	if err := store.write(pokemon); err != nil {
+		return err
+	}
+	store.dropName(existing, pokemon)
+	store.publish(schema.EventUpdated, pokemon, &existing, origin)
+	return nil
+}
+
+// Applies change to the stored record with the given ID and stores the result, all under the write lock
+// so concurrent modifications are not lost. change must keep the ID, errors it returns are passed on.
+func (store *Store) Modify(id string, change func(schema.Pokemon) (schema.Pokemon, error), origin schema.Origin) (schema.Pokemon, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	existing, err := store.Get(id)
+	if err != nil {
+		return existing, err
+	}
+	modified, err := change(existing)
+	if err != nil {
+		return existing, err
+	}
+	if modified.Id != existing.Id {
+		return existing, ErrIdChanged
+	}
+	if err := store.checkKeys(modified); err != nil {
+		return existing, err
+	}
+	if err := store.checkEvolution(modified); err != nil {
+		return existing, err
+	}
+	if err := store.write(modified); err != nil {
+		return existing, err
+	}
+	store.dropName(existing, modified)
+	store.publish(schema.EventUpdated, modified, &existing, origin)
+	return modified, nil
+}
+
+// Removes the record with the given ID and returns it, ErrHasEvolutions while other pokemons evolve from it
+func (store *Store) Delete(id string, origin schema.Origin) (schema.Pokemon, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	pokemon, err := store.Get(id)
+	if err != nil {
+		return pokemon, err
+	}
+	if later := store.evolutions.EvolvesTo(pokemon.Id); len(later) > 0 {
+		return pokemon, fmt.Errorf("%w: %v evolve from %v", ErrHasEvolutions, strings.Join(later, ", "), pokemon.Id)
+	}
+	store.remove(pokemon)
+	store.publish(schema.EventDeleted, pokemon, nil, origin)
+	return pokemon, nil
+}
+
+// Removes both keys of pokemon without publishing, used for cache maintenance rather than catalog changes.
+// Returns how many keys were removed.
+func (store *Store) Evict(pokemon schema.Pokemon) int {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	return store.remove(pokemon)
+}
+
+// Evolution chain the pokemon stored under an ID or name belongs to, starting at its first stage.
+// Stages that left the cache end the chain where they were.
+func (store *Store) Evolutions(key string) (schema.EvolutionStage, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	first, err := store.Get(key)
+	if err != nil {
+		return schema.EvolutionStage{}, err
+	}
+	for stage := 0; first.EvolvesFrom != nil && stage < maxEvolutionStages; stage++ {
+		earlier, err := store.Get(first.EvolvesFrom.Id)
+		if err != nil {
+			break
+		}
+		first = earlier
+	}
+	return store.stage(first, 0), nil
+}
+
+// Records ordered by ID, only the ones match accepts when it is not nil
+func (store *Store) List(match func(schema.Pokemon) bool) []schema.Pokemon {
+	pokemons := []schema.Pokemon{}
+	iterator := store.cache.Iterator()
+	for iterator.SetNext() {
+		entry, err := iterator.Value()
+		if err != nil {
+			//Entry was removed while iterating, skip it
+			continue
+		}
+		var pokemon schema.Pokemon
+		if err := store.records.Unmarshal(entry.Value(), &pokemon); err != nil {
+			continue
+		}
+		//Each record is stored under its ID and name, only count it once
+		if entry.Key() != pokemon.Id {
+			continue
+		}
+		if match == nil || match(pokemon) {
+			pokemons = append(pokemons, pokemon)
+		}
+	}
+	sort.Slice(pokemons, func(i, j int) bool { return pokemons[i].Id < pokemons[j].Id })
+	return pokemons
+}
+
+// Drops every record and empties the indexes, returns how many keys were removed
+func (store *Store) Flush() (int, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	removed := store.cache.Len()
+	if err := store.cache.Reset(); err != nil {
+		return 0, err
+	}
+	for _, index := range store.indexes {
+		index.Reset()
+	}
+	return removed, nil
+}
+
+// Eviction listener dropping the name key and the index entries once the ID key of the same record left
+// the cache, so neither a name nor an index resolves to a record that can no longer be fetched by ID
+func (store *Store) CleanupNameKey(event eviction.Event) {
+	if !event.Decoded || event.Key != event.Pokemon.Id {
+		return
+	}
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	//Record was added again in the meantime, its name key is live
+	if _, err := store.cache.Get(event.Pokemon.Id); err == nil {
+		return
+	}
+	store.unindex(event.Pokemon)
+	if event.Pokemon.Name == event.Pokemon.Id {
+		return
+	}
+	if current, err := store.Get(event.Pokemon.Name); err == nil && current.Id == event.Pokemon.Id {
+		store.cache.Delete(event.Pokemon.Name)
+	}
+}
+
+// Eviction listener publishing records that expired or were pushed out of the cache
+func (store *Store) PublishEviction(event eviction.Event) {
+	if event.Reason == eviction.Deleted || !event.Decoded || event.Key != event.Pokemon.Id {
+		return
+	}
+	store.publish(schema.EventEvicted, event.Pokemon, nil, schema.Origin{})
+}
+
+// Makes sure neither key of pokemon holds a different record, so writing it cannot take over a key
+// of another record. Called with the mutex held.
+func (store *Store) checkKeys(pokemon schema.Pokemon) error {
+	for _, key := range []string{pokemon.Id, pokemon.Name} {
+		if len(key) <= 0 {
+			continue
+		}
+		existing, err := store.Get(key)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if existing.Id != pokemon.Id {
+			return &ConflictError{Key: key, Existing: existing}
+		}
+	}
+	return nil
+}
+
+// Makes sure there is room for one more record. Called with the mutex held.
+func (store *Store) checkQuota() error {
+	if store.quota > 0 && store.members.Len() >= store.quota {
+		return fmt.Errorf("%w: the store holds at most %v pokemons", ErrQuotaExceeded, store.quota)
+	}
+	return nil
+}
+
+// Makes sure pokemon evolves from a stored record that is neither pokemon itself nor one of its later stages,
+// so every chain stays a tree. Called with the mutex held.
+func (store *Store) checkEvolution(pokemon schema.Pokemon) error {
+	if pokemon.EvolvesFrom == nil {
+		return nil
+	}
+	if len(pokemon.EvolvesFrom.Id) <= 0 {
+		return fmt.Errorf("%w: ID of the earlier stage is expected", ErrInvalidEvolution)
+	}
+	if pokemon.EvolvesFrom.Level < 0 {
+		return fmt.Errorf("%w: level cannot be negative", ErrInvalidEvolution)
+	}
+	if pokemon.EvolvesFrom.Id == pokemon.Id {
+		return fmt.Errorf("%w: %v cannot evolve from itself", ErrInvalidEvolution, pokemon.Id)
+	}
+	earlier, err := store.Get(pokemon.EvolvesFrom.Id)
+	if errors.Is(err, ErrNotFound) || (err == nil && earlier.Id != pokemon.EvolvesFrom.Id) {
+		//Names are keys too, but links have to survive renames
+		return fmt.Errorf("%w: no pokemon with ID %v to evolve from", ErrInvalidEvolution, pokemon.EvolvesFrom.Id)
+	}
+	if err != nil {
+		return err
+	}
+	//Links are compared before fetching, so a loop through a stage that left the cache is caught too
+	for stage := 1; earlier.EvolvesFrom != nil; stage++ {
+		if earlier.EvolvesFrom.Id == pokemon.Id {
+			return fmt.Errorf("%w: %v cannot evolve from its later stage %v", ErrInvalidEvolution, pokemon.Id, earlier.Id)
+		}
+		if stage >= maxEvolutionStages {
+			return fmt.Errorf("%w: chains have at most %v stages", ErrInvalidEvolution, maxEvolutionStages)
+		}
+		earlier, err = store.Get(earlier.EvolvesFrom.Id)
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stage of pokemon with the stages evolving from it, no deeper than chains may be.
+// Called with the mutex held.
+func (store *Store) stage(pokemon schema.Pokemon, depth int) schema.EvolutionStage {
+	stage := schema.EvolutionStage{Pokemon: pokemon, EvolvesTo: []schema.EvolutionStage{}}
+	if depth >= maxEvolutionStages {
+		return stage
+	}
+	for _, id := range store.evolutions.EvolvesTo(pokemon.Id) {
+		if later, err := store.Get(id); err == nil {
+			stage.EvolvesTo = append(stage.EvolvesTo, store.stage(later, depth+1))
+		}
+	}
+	return stage
+}
+
+// Next allocated ID that is not a key yet, records created with an ID of their own may have taken some.
+// Called with the mutex held, so a free ID stays free until the record is written.
+func (store *Store) allocate() (string, error) {
+	for attempt := 0; attempt < allocateAttempts; attempt++ {
+		id, err := store.ids.Next()
+		if err != nil {
+			return "", err
+		}
+		if _, err := store.Get(id); errors.Is(err, ErrNotFound) {
+			return id, nil
+		} else if err != nil {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("no free ID after %v attempts of the %v allocator", allocateAttempts, store.ids.Name())
+}
+
+func (store *Store) write(pokemon schema.Pokemon) error {
+	data, err := store.records.Marshal(pokemon)
+	if err != nil {
+		return err
+	}
+	if err := store.cache.Set(pokemon.Name, data); err != nil {
+		return err
+	}
+	if err := store.cache.Set(pokemon.Id, data); err != nil {
+		return err
+	}
+	for _, index := range store.indexes {
+		index.Put(pokemon)
+	}
+	return nil
+}
+
+// Drops the name key of the previous version of a record when the record was renamed
+func (store *Store) dropName(previous schema.Pokemon, current schema.Pokemon) {
+	if previous.Name == current.Name || previous.Name == current.Id {
+		return
+	}
+	if owner, err := store.Get(previous.Name); err == nil && owner.Id == previous.Id {
+		store.cache.Delete(previous.Name)
+	}
+}
+
+// Deletes the ID key and, when it still belongs to pokemon, the name key
+func (store *Store) remove(pokemon schema.Pokemon) int {
+	store.unindex(pokemon)
+	removed := 0
+	if store.cache.Delete(pokemon.Id) == nil {
+		removed++
+	}
+	//Name key may already point to a different record, only drop it when it still belongs to this one
+	if current, err := store.Get(pokemon.Name); err == nil && current.Id == pokemon.Id {
+		if store.cache.Delete(pokemon.Name) == nil {
+			removed++
+		}
+	}
+	return removed
+}
+
+func (store *Store) unindex(pokemon schema.Pokemon) {
+	for _, index := range store.indexes {
+		index.Remove(pokemon)
+	}
+}
+
+// Publishes a change of pokemon, previous is the record it replaced
+func (store *Store) publish(eventType string, pokemon schema.Pokemon, previous *schema.Pokemon, origin schema.Origin) {
+	store.events.Publish(schema.PokemonEvent{
+		Type:      eventType,
+		PokemonId: pokemon.Id,
+		Pokemon:   &pokemon,
+		RequestId: origin.RequestId,
+		Actor:     origin.Actor,
+		Previous:  previous,
+	})
+}
@@ -0,0 +1,446 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	codec "pokemon-service/codec"
+	events "pokemon-service/events"
+	eviction "pokemon-service/eviction"
+	idgen "pokemon-service/idgen"
+	"pokemon-service/schema"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/allegro/bigcache/v3"
+)
+
+func TestWritePaths(t *testing.T) {
+	store, published := loadStore()
+
+	created, err := store.Add(schema.Pokemon{Id: "PK20001", Name: "Bulbasaur"}, schema.Origin{RequestId: "req-1"})
+	if err != nil || !created {
+		t.Fatalf("unexpected add result: created %v err %v", created, err)
+	}
+	created, err = store.Add(schema.Pokemon{Id: "PK20001", Name: "Ivysaur"}, schema.Origin{RequestId: "req-2", Actor: "ash"})
+	if err != nil || created {
+		t.Fatalf("unexpected upsert result: created %v err %v", created, err)
+	}
+	//Renamed record must not stay reachable under its old name
+	if _, err := store.Get("Bulbasaur"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("old name still resolves: %v", err)
+	}
+	if pokemon, err := store.Get("Ivysaur"); err != nil || pokemon.Id != "PK20001" {
+		t.Errorf("new name does not resolve: %+v %v", pokemon, err)
+	}
+
+	if err := store.Update(schema.Pokemon{Id: "PK29999"}, schema.Origin{RequestId: "req-3"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unexpected update error for unknown ID: %v", err)
+	}
+	if err := store.Update(schema.Pokemon{Name: "Nameless"}, schema.Origin{RequestId: "req-3"}); !errors.Is(err, ErrMissingId) {
+		t.Errorf("unexpected update error without ID: %v", err)
+	}
+	if err := store.Update(schema.Pokemon{Id: "PK20001", Name: "Venusaur", Type: "Grass"}, schema.Origin{RequestId: "req-4"}); err != nil {
+		t.Fatalf("unexpected update error: %v", err)
+	}
+
+	deleted, err := store.Delete("PK20001", schema.Origin{RequestId: "req-5"})
+	if err != nil || deleted.Name != "Venusaur" {
+		t.Fatalf("unexpected delete result: %+v %v", deleted, err)
+	}
+	for _, key := range []string{"PK20001", "Venusaur"} {
+		if _, err := store.Get(key); !errors.Is(err, ErrNotFound) {
+			t.Errorf("key %v still present after delete: %v", key, err)
+		}
+	}
+	if _, err := store.Delete("PK20001", schema.Origin{RequestId: "req-6"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unexpected error deleting twice: %v", err)
+	}
+
+	expected := []string{schema.EventCreated, schema.EventUpdated, schema.EventUpdated, schema.EventDeleted}
+	if len(*published) != len(expected) {
+		t.Fatalf("unexpected events: got %+v want %v", *published, expected)
+	}
+	for i, eventType := range expected {
+		if (*published)[i].Type != eventType {
+			t.Errorf("unexpected event %v: got %v want %v", i, (*published)[i].Type, eventType)
+		}
+	}
+	//Updates carry the record they replaced
+	upsert := (*published)[1]
+	if upsert.RequestId != "req-2" || upsert.Actor != "ash" || upsert.Previous == nil || upsert.Previous.Name != "Bulbasaur" {
+		t.Errorf("unexpected upsert event: %+v previous %+v", upsert, upsert.Previous)
+	}
+	if update := (*published)[2]; update.Previous == nil || update.Previous.Name != "Ivysaur" || update.Pokemon.Name != "Venusaur" {
+		t.Errorf("unexpected update event: %+v previous %+v", update, update.Previous)
+	}
+	if (*published)[0].Previous != nil || (*published)[3].Previous != nil {
+		t.Errorf("create and delete events carry a previous record")
+	}
+}
+
+func TestCreateAndModify(t *testing.T) {
+	store, published := loadStore()
+
+	if _, err := store.Create(schema.Pokemon{Name: "Nameless"}, schema.Origin{}); !errors.Is(err, ErrMissingId) {
+		t.Errorf("unexpected create error without ID: %v", err)
+	}
+	if _, err := store.Create(schema.Pokemon{Id: "PK20001", Name: "Bulbasaur"}, schema.Origin{RequestId: "req-1"}); err != nil {
+		t.Fatalf("unexpected create error: %v", err)
+	}
+	if _, err := store.Create(schema.Pokemon{Id: "PK20001", Name: "Ivysaur"}, schema.Origin{RequestId: "req-2"}); !errors.Is(err, ErrExists) {
+		t.Errorf("unexpected error creating twice: %v", err)
+	}
+	var conflict *ConflictError
+	if _, err := store.Create(schema.Pokemon{Id: "PK20002", Name: "Bulbasaur"}, schema.Origin{}); !errors.As(err, &conflict) || conflict.Key != "Bulbasaur" || conflict.Existing.Id != "PK20001" {
+		t.Errorf("unexpected error creating with a taken name: %v", err)
+	}
+	if _, err := store.Add(schema.Pokemon{Id: "Bulbasaur", Name: "Venusaur"}, schema.Origin{}); !errors.Is(err, ErrExists) {
+		t.Errorf("unexpected error adding with an ID that is another record's name: %v", err)
+	}
+	store.Create(schema.Pokemon{Id: "PK20003", Name: "Charmander"}, schema.Origin{})
+	if err := store.Update(schema.Pokemon{Id: "PK20003", Name: "Bulbasaur"}, schema.Origin{}); !errors.Is(err, ErrExists) {
+		t.Errorf("unexpected error renaming to a taken name: %v", err)
+	}
+	if pokemon, _ := store.Get("Bulbasaur"); pokemon.Id != "PK20001" {
+		t.Errorf("conflicting write took over the name: %+v", pokemon)
+	}
+
+	modified, err := store.Modify("PK20001", func(pokemon schema.Pokemon) (schema.Pokemon, error) {
+		pokemon.Name = "Ivysaur"
+		return pokemon, nil
+	}, schema.Origin{RequestId: "req-3"})
+	if err != nil || modified.Name != "Ivysaur" {
+		t.Fatalf("unexpected modify result: %+v %v", modified, err)
+	}
+	if _, err := store.Get("Bulbasaur"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("old name still resolves: %v", err)
+	}
+	_, err = store.Modify("PK20001", func(pokemon schema.Pokemon) (schema.Pokemon, error) {
+		pokemon.Id = "PK20002"
+		return pokemon, nil
+	}, schema.Origin{RequestId: "req-4"})
+	if !errors.Is(err, ErrIdChanged) {
+		t.Errorf("unexpected error changing the ID: %v", err)
+	}
+	if _, err := store.Modify("PK29999", func(pokemon schema.Pokemon) (schema.Pokemon, error) { return pokemon, nil }, schema.Origin{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unexpected modify error for unknown ID: %v", err)
+	}
+
+	expected := []string{schema.EventCreated, schema.EventCreated, schema.EventUpdated}
+	if len(*published) != len(expected) || (*published)[0].Type != expected[0] || (*published)[2].Type != expected[2] {
+		t.Errorf("unexpected events: got %+v want %v", *published, expected)
+	}
+}
+
+func TestCreateAllocatesIds(t *testing.T) {
+	cache, _ := bigcache.NewBigCache(bigcache.DefaultConfig(24 * time.Hour))
+	ids, err := idgen.NewSequence(idgen.Config{StateFile: filepath.Join(t.TempDir(), "id.sequence"), Start: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := New(cache, nil, nil, ids)
+	//Taken by a record created with its own ID, allocation skips it
+	store.Create(schema.Pokemon{Id: "PK2", Name: "Ivysaur"}, schema.Origin{})
+
+	var wg sync.WaitGroup
+	created := make([]schema.Pokemon, 50)
+	for i := range created {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			pokemon, err := store.Create(schema.Pokemon{Name: fmt.Sprintf("Bulbasaur%v", i)}, schema.Origin{})
+			if err != nil {
+				t.Error(err)
+			}
+			created[i] = pokemon
+		}(i)
+	}
+	wg.Wait()
+
+	seen := map[string]bool{"PK2": true}
+	for _, pokemon := range created {
+		if seen[pokemon.Id] {
+			t.Errorf("allocated %v twice", pokemon.Id)
+		}
+		seen[pokemon.Id] = true
+		if stored, err := store.Get(pokemon.Id); err != nil || stored.Name != pokemon.Name {
+			t.Errorf("unexpected record for %v: %+v %v", pokemon.Id, stored, err)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	store, _ := loadStore()
+	store.Add(schema.Pokemon{Id: "PK20002", Name: "Charmander", Type: "Fire"}, schema.Origin{})
+	store.Add(schema.Pokemon{Id: "PK20001", Name: "Bulbasaur", Type: "Grass"}, schema.Origin{})
+	store.Add(schema.Pokemon{Id: "PK20003", Name: "Charmeleon", Type: "Fire"}, schema.Origin{})
+
+	all := store.List(nil)
+	if len(all) != 3 || all[0].Id != "PK20001" || all[2].Id != "PK20003" {
+		t.Errorf("unexpected list: %+v", all)
+	}
+	fire := store.List(func(pokemon schema.Pokemon) bool { return pokemon.Type == "Fire" })
+	if len(fire) != 2 {
+		t.Errorf("unexpected filtered list: %+v", fire)
+	}
+}
+
+func TestQuota(t *testing.T) {
+	store, _ := loadStore()
+	store.SetQuota(2)
+	store.Add(schema.Pokemon{Id: "PK20001", Name: "Bulbasaur"}, schema.Origin{})
+	store.Create(schema.Pokemon{Id: "PK20002", Name: "Charmander"}, schema.Origin{})
+	if _, err := store.Create(schema.Pokemon{Id: "PK20003", Name: "Squirtle"}, schema.Origin{}); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("unexpected error creating beyond the quota: %v", err)
+	}
+	if _, err := store.Add(schema.Pokemon{Id: "PK20003", Name: "Squirtle"}, schema.Origin{}); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("unexpected error adding beyond the quota: %v", err)
+	}
+	//Re-posting a stored record is a conflict, not a lack of room
+	var conflict *ConflictError
+	if _, err := store.Create(schema.Pokemon{Id: "PK20001", Name: "Bulbasaur"}, schema.Origin{}); !errors.As(err, &conflict) || conflict.Existing.Id != "PK20001" {
+		t.Errorf("unexpected error creating a taken ID at the quota: %v", err)
+	}
+	if _, err := store.Create(schema.Pokemon{Id: "PK20003", Name: "Charmander"}, schema.Origin{}); !errors.As(err, &conflict) || conflict.Existing.Id != "PK20002" {
+		t.Errorf("unexpected error creating a taken name at the quota: %v", err)
+	}
+	//Replacing a stored record does not take more room
+	if _, err := store.Add(schema.Pokemon{Id: "PK20001", Name: "Ivysaur"}, schema.Origin{}); err != nil {
+		t.Errorf("unexpected error replacing a record at the quota: %v", err)
+	}
+	if count := store.Count(); count != 2 {
+		t.Errorf("unexpected count: %v", count)
+	}
+
+	store.Delete("PK20002", schema.Origin{})
+	if _, err := store.Create(schema.Pokemon{Id: "PK20003", Name: "Squirtle"}, schema.Origin{}); err != nil {
+		t.Errorf("unexpected error creating after a deletion made room: %v", err)
+	}
+	store.SetQuota(0)
+	if _, err := store.Create(schema.Pokemon{Id: "PK20004", Name: "Pikachu"}, schema.Origin{}); err != nil || store.Count() != 3 {
+		t.Errorf("unexpected result without a quota: count %v err %v", store.Count(), err)
+	}
+}
+
+func TestCleanupNameKey(t *testing.T) {
+	inputs := []struct {
+		testName    string
+		deleteID    bool
+		event       eviction.Event
+		nameRemains bool
+	}{
+		{testName: "TestCleanupAfterIDEvicted", deleteID: true, event: evictionEvent("PK10001", "PK10001", "Picachoo1"), nameRemains: false},
+		{testName: "TestCleanupIDStillCached", deleteID: false, event: evictionEvent("PK10001", "PK10001", "Picachoo1"), nameRemains: true},
+		{testName: "TestCleanupNameEvicted", deleteID: true, event: evictionEvent("Picachoo1", "PK10001", "Picachoo1"), nameRemains: true},
+	}
+
+	for _, item := range inputs {
+		store, _ := loadStore()
+		store.Add(schema.Pokemon{Id: "PK10001", Name: "Picachoo1"}, schema.Origin{})
+		if item.deleteID {
+			store.cache.Delete("PK10001")
+		}
+		store.CleanupNameKey(item.event)
+
+		_, err := store.cache.Get("Picachoo1")
+		if remains := err == nil; remains != item.nameRemains {
+			t.Errorf("%v: name key present %v want %v", item.testName, remains, item.nameRemains)
+		}
+	}
+}
+
+func TestIndexes(t *testing.T) {
+	store, _ := loadStore()
+	store.Add(schema.Pokemon{Id: "PK10001", Name: "Picachoo1"}, schema.Origin{})
+	indexed := recordingIndex{}
+	store.AddIndex(indexed)
+	if _, ok := indexed["PK10001"]; !ok {
+		t.Errorf("index was not filled with the stored records: %v", indexed)
+	}
+
+	store.Create(schema.Pokemon{Id: "PK10002", Name: "Picachoo2"}, schema.Origin{})
+	store.Modify("PK10002", func(pokemon schema.Pokemon) (schema.Pokemon, error) {
+		pokemon.Name = "Raichu"
+		return pokemon, nil
+	}, schema.Origin{})
+	if indexed["PK10002"].Name != "Raichu" {
+		t.Errorf("index missed a write: %v", indexed)
+	}
+	store.Delete("PK10002", schema.Origin{})
+	if _, ok := indexed["PK10002"]; ok {
+		t.Errorf("index kept a deleted record: %v", indexed)
+	}
+
+	//Expired records leave the index once their ID key is gone
+	store.CleanupNameKey(evictionEvent("PK10001", "PK10001", "Picachoo1"))
+	if _, ok := indexed["PK10001"]; !ok {
+		t.Errorf("index dropped a record that is still cached: %v", indexed)
+	}
+	store.cache.Delete("PK10001")
+	store.CleanupNameKey(evictionEvent("PK10001", "PK10001", "Picachoo1"))
+	if _, ok := indexed["PK10001"]; ok {
+		t.Errorf("index kept an expired record: %v", indexed)
+	}
+
+	store.Add(schema.Pokemon{Id: "PK10003", Name: "Picachoo3"}, schema.Origin{})
+	if removed, err := store.Flush(); err != nil || removed != 2 || len(indexed) != 0 {
+		t.Errorf("unexpected flush: removed %v err %v index %v", removed, err, indexed)
+	}
+}
+
+func TestGetByName(t *testing.T) {
+	store, _ := loadStore()
+	store.Add(schema.Pokemon{Id: "PK10001", Name: "Picachoo1"}, schema.Origin{})
+	store.Add(schema.Pokemon{Id: "PK10002", Name: "Flabébé"}, schema.Origin{})
+	store.Add(schema.Pokemon{Id: "PK10003", Name: "PICACHOO1"}, schema.Origin{})
+
+	inputs := []struct {
+		name string
+		id   string
+	}{
+		{name: "Picachoo1", id: "PK10001"},
+		{name: "PICACHOO1", id: "PK10003"},
+		//Both names normalize to this one, the lowest ID wins
+		{name: " picachoo1 ", id: "PK10001"},
+		{name: "flabebe", id: "PK10002"},
+		{name: "PK10001"},
+		{name: "Unknown"},
+	}
+	for _, item := range inputs {
+		pokemon, err := store.GetByName(item.name)
+		if len(item.id) <= 0 {
+			if !errors.Is(err, ErrNotFound) {
+				t.Errorf("%q: expected ErrNotFound, got %+v %v", item.name, pokemon, err)
+			}
+			continue
+		}
+		if err != nil || pokemon.Id != item.id {
+			t.Errorf("%q: got %+v %v want %v", item.name, pokemon, err, item.id)
+		}
+	}
+}
+
+func TestEvolutions(t *testing.T) {
+	store, _ := loadStore()
+	store.Add(schema.Pokemon{Id: "PK1", Name: "Eevee"}, schema.Origin{})
+	store.Add(schema.Pokemon{Id: "PK2", Name: "Vaporeon", EvolvesFrom: &schema.Evolution{Id: "PK1", Condition: "Water Stone"}}, schema.Origin{})
+	store.Add(schema.Pokemon{Id: "PK3", Name: "Jolteon", EvolvesFrom: &schema.Evolution{Id: "PK1", Condition: "Thunder Stone"}}, schema.Origin{})
+
+	inputs := []struct {
+		testName string
+		pokemon  schema.Pokemon
+		err      error
+	}{
+		{testName: "TestEvolvesFromUnknown", pokemon: schema.Pokemon{Id: "PK4", Name: "Flareon", EvolvesFrom: &schema.Evolution{Id: "PK9"}}, err: ErrInvalidEvolution},
+		{testName: "TestEvolvesFromName", pokemon: schema.Pokemon{Id: "PK4", Name: "Flareon", EvolvesFrom: &schema.Evolution{Id: "Eevee"}}, err: ErrInvalidEvolution},
+		{testName: "TestEvolvesFromItself", pokemon: schema.Pokemon{Id: "PK4", Name: "Flareon", EvolvesFrom: &schema.Evolution{Id: "PK4"}}, err: ErrInvalidEvolution},
+		{testName: "TestEvolvesFromLaterStage", pokemon: schema.Pokemon{Id: "PK1", Name: "Eevee", EvolvesFrom: &schema.Evolution{Id: "PK2"}}, err: ErrInvalidEvolution},
+		{testName: "TestEvolvesFromNegativeLevel", pokemon: schema.Pokemon{Id: "PK4", Name: "Flareon", EvolvesFrom: &schema.Evolution{Id: "PK1", Level: -1}}, err: ErrInvalidEvolution},
+		{testName: "TestEvolvesFromFirstStage", pokemon: schema.Pokemon{Id: "PK4", Name: "Flareon", EvolvesFrom: &schema.Evolution{Id: "PK1", Condition: "Fire Stone"}}},
+	}
+	for _, item := range inputs {
+		if _, err := store.Add(item.pokemon, schema.Origin{}); !errors.Is(err, item.err) {
+			t.Errorf("%v: unexpected error: got %v want %v", item.testName, err, item.err)
+		}
+	}
+
+	chain, err := store.Evolutions("PK3")
+	if err != nil || chain.Pokemon.Id != "PK1" || len(chain.EvolvesTo) != 3 || chain.EvolvesTo[0].Pokemon.Id != "PK2" || chain.EvolvesTo[2].Pokemon.EvolvesFrom.Condition != "Fire Stone" {
+		t.Errorf("unexpected chain: %+v %v", chain, err)
+	}
+	if _, err := store.Evolutions("PK9"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unexpected error for an unknown pokemon: %v", err)
+	}
+
+	//Earlier stages stay while later ones evolve from them
+	if _, err := store.Delete("PK1", schema.Origin{}); !errors.Is(err, ErrHasEvolutions) {
+		t.Errorf("deleted a pokemon others evolve from: %v", err)
+	}
+	for _, id := range []string{"PK2", "PK3", "PK4", "PK1"} {
+		if _, err := store.Delete(id, schema.Origin{}); err != nil {
+			t.Errorf("unable to delete %v: %v", id, err)
+		}
+	}
+
+	//A stage that left the cache cannot be re-added as a later stage of its own evolution
+	store.Add(schema.Pokemon{Id: "PK1", Name: "Eevee"}, schema.Origin{})
+	store.Add(schema.Pokemon{Id: "PK2", Name: "Vaporeon", EvolvesFrom: &schema.Evolution{Id: "PK1"}}, schema.Origin{})
+	store.Evict(schema.Pokemon{Id: "PK1", Name: "Eevee"})
+	if _, err := store.Add(schema.Pokemon{Id: "PK1", Name: "Eevee", EvolvesFrom: &schema.Evolution{Id: "PK2"}}, schema.Origin{}); !errors.Is(err, ErrInvalidEvolution) {
+		t.Errorf("unexpected error for a loop through an evicted stage: %v", err)
+	}
+	if chain, err := store.Evolutions("PK2"); err != nil || chain.Pokemon.Id != "PK2" {
+		t.Errorf("chain did not start at the first stage still cached: %+v %v", chain, err)
+	}
+}
+
+func TestPublishEviction(t *testing.T) {
+	store, published := loadStore()
+	store.PublishEviction(evictionEvent("PK10001", "PK10001", "Picachoo1"))
+	store.PublishEviction(evictionEvent("Picachoo1", "PK10001", "Picachoo1"))
+	deleted := evictionEvent("PK10002", "PK10002", "Picachoo2")
+	deleted.Reason = eviction.Deleted
+	store.PublishEviction(deleted)
+
+	if len(*published) != 1 || (*published)[0].Type != schema.EventEvicted || (*published)[0].PokemonId != "PK10001" {
+		t.Errorf("unexpected events: %+v", *published)
+	}
+}
+
+// Empty store publishing on a bus whose events are collected in the returned slice
+func loadStore() (*Store, *[]schema.PokemonEvent) {
+	cache, _ := bigcache.NewBigCache(bigcache.DefaultConfig(24 * time.Hour))
+	bus := events.NewBus()
+	published := &[]schema.PokemonEvent{}
+	bus.Subscribe(func(event schema.PokemonEvent) { *published = append(*published, event) })
+	return New(cache, bus, nil, nil), published
+}
+
+// Index holding the records it was given by ID
+type recordingIndex map[string]schema.Pokemon
+
+func (index recordingIndex) Put(pokemon schema.Pokemon) { index[pokemon.Id] = pokemon }
+
+func (index recordingIndex) Remove(pokemon schema.Pokemon) { delete(index, pokemon.Id) }
+
+func (index recordingIndex) Reset() {
+	for id := range index {
+		delete(index, id)
+	}
+}
+
+func evictionEvent(key string, id string, name string) eviction.Event {
+	return eviction.Event{Key: key, Reason: eviction.Expired, Pokemon: schema.Pokemon{Id: id, Name: name}, Decoded: true}
+}
+
+// Compares the record codecs on the size of a stored record and the cost of reading it back by ID:
+// go test ./store -run ^$ -bench GetByID -benchmem
+func BenchmarkGetByID(b *testing.B) {
+	pokemon := schema.Pokemon{Id: "PK10001", Name: "Chespin", Type: "TT", Height: "20.9", Weight: "30.9", Abilities: "Eat&Sleep"}
+
+	for _, records := range codec.RecordCodecs() {
+		b.Run(records.Name(), func(b *testing.B) {
+			cache, err := bigcache.NewBigCache(bigcache.DefaultConfig(time.Hour))
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer cache.Close()
+			store := New(cache, nil, records, nil)
+			if _, err := store.Create(pokemon, schema.Origin{}); err != nil {
+				b.Fatal(err)
+			}
+			data, _ := records.Marshal(pokemon)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := store.Get(pokemon.Id); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(len(data)), "B/record")
+		})
+	}
+}
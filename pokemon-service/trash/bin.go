@@ -0,0 +1,175 @@
+package trash
+
+import (
+	schema "pokemon-service/schema"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Tunes the bin, zero values fall back to defaults
+type Config struct {
+	// How long a deleted pokemon can be restored
+	Retention time.Duration
+	// Pokemons kept at most, the ones purged first make room for new ones
+	MaxEntries int
+	// Interval between purges of pokemons past their retention
+	PurgeInterval time.Duration
+}
+
+func (config Config) withDefaults() Config {
+	if config.Retention <= 0 {
+		config.Retention = 7 * 24 * time.Hour
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = 10000
+	}
+	if config.PurgeInterval <= 0 {
+		config.PurgeInterval = time.Minute
+	}
+	return config
+}
+
+type entry struct {
+	pokemon   schema.Pokemon
+	requestId string
+	deletedAt time.Time
+	purgeAt   time.Time
+}
+
+// Bin keeps deleted pokemons by ID for the retention period, so deletes can be undone.
+// It fills up from the delete events of the store, a pokemon deleted again replaces the earlier copy.
+type Bin struct {
+	config  Config
+	logger  *schema.Logger
+	mutex   sync.Mutex
+	entries map[string]*entry
+	done    chan struct{}
+	once    sync.Once
+}
+
+// Creates a bin and starts purging pokemons past their retention, Close stops it
+func NewBin(config Config, logger *schema.Logger) *Bin {
+	bin := &Bin{config: config.withDefaults(), logger: logger, entries: map[string]*entry{}, done: make(chan struct{})}
+	go bin.purgeOnSchedule()
+	return bin
+}
+
+// Event bus subscriber keeping the deleted pokemons
+func (bin *Bin) Handle(event schema.PokemonEvent) {
+	if event.Type != schema.EventDeleted || event.Pokemon == nil {
+		return
+	}
+	bin.mutex.Lock()
+	defer bin.mutex.Unlock()
+	now := time.Now()
+	if _, ok := bin.entries[event.Pokemon.Id]; !ok && len(bin.entries) >= bin.config.MaxEntries {
+		bin.evict()
+	}
+	bin.entries[event.Pokemon.Id] = &entry{pokemon: *event.Pokemon, requestId: event.RequestId, deletedAt: now, purgeAt: now.Add(bin.config.Retention)}
+}
+
+// Pokemons in the bin, the latest deleted first
+func (bin *Bin) List() []schema.TrashedPokemon {
+	bin.mutex.Lock()
+	defer bin.mutex.Unlock()
+	entries := make([]*entry, 0, len(bin.entries))
+	for _, trashed := range bin.entries {
+		entries = append(entries, trashed)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].deletedAt.Equal(entries[j].deletedAt) {
+			return entries[i].deletedAt.After(entries[j].deletedAt)
+		}
+		return entries[i].pokemon.Id < entries[j].pokemon.Id
+	})
+	trashed := make([]schema.TrashedPokemon, 0, len(entries))
+	for _, item := range entries {
+		trashed = append(trashed, item.schema())
+	}
+	return trashed
+}
+
+// Removes the pokemon with the given ID from the bin for restoring it, false when it is not there.
+// Put it back with Return when restoring fails.
+func (bin *Bin) Take(id string) (schema.TrashedPokemon, bool) {
+	bin.mutex.Lock()
+	defer bin.mutex.Unlock()
+	trashed, ok := bin.entries[id]
+	if !ok {
+		return schema.TrashedPokemon{}, false
+	}
+	delete(bin.entries, id)
+	return trashed.schema(), true
+}
+
+// Puts back a pokemon Take removed, unless the same ID was deleted again in the meantime
+func (bin *Bin) Return(trashed schema.TrashedPokemon) {
+	deletedAt, errDeleted := time.Parse(time.RFC3339Nano, trashed.DeletedAt)
+	purgeAt, errPurge := time.Parse(time.RFC3339Nano, trashed.PurgeAt)
+	if errDeleted != nil || errPurge != nil {
+		return
+	}
+	bin.mutex.Lock()
+	defer bin.mutex.Unlock()
+	if _, ok := bin.entries[trashed.Pokemon.Id]; ok {
+		return
+	}
+	bin.entries[trashed.Pokemon.Id] = &entry{pokemon: trashed.Pokemon, requestId: trashed.RequestId, deletedAt: deletedAt, purgeAt: purgeAt}
+}
+
+func (bin *Bin) Close() {
+	bin.once.Do(func() { close(bin.done) })
+}
+
+// Drops the pokemons past their retention at now, returns how many were dropped
+func (bin *Bin) purge(now time.Time) int {
+	bin.mutex.Lock()
+	defer bin.mutex.Unlock()
+	purged := 0
+	for id, trashed := range bin.entries {
+		if !now.Before(trashed.purgeAt) {
+			delete(bin.entries, id)
+			purged++
+		}
+	}
+	return purged
+}
+
+// Drops the pokemon purged first to make room. Called with the mutex held.
+func (bin *Bin) evict() {
+	var first *entry
+	for _, trashed := range bin.entries {
+		if first == nil || trashed.purgeAt.Before(first.purgeAt) {
+			first = trashed
+		}
+	}
+	if first != nil {
+		delete(bin.entries, first.pokemon.Id)
+		bin.logger.WarnLogger.Println("Trash is full, purged pokemon", first.pokemon.Id, "before its retention ended")
+	}
+}
+
+func (bin *Bin) purgeOnSchedule() {
+	ticker := time.NewTicker(bin.config.PurgeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-bin.done:
+			return
+		case now := <-ticker.C:
+			if purged := bin.purge(now); purged > 0 {
+				bin.logger.InfoLogger.Println("Purged", purged, "pokemons from the trash")
+			}
+		}
+	}
+}
+
+func (trashed *entry) schema() schema.TrashedPokemon {
+	return schema.TrashedPokemon{
+		Pokemon:   trashed.pokemon,
+		DeletedAt: trashed.deletedAt.UTC().Format(time.RFC3339Nano),
+		PurgeAt:   trashed.purgeAt.UTC().Format(time.RFC3339Nano),
+		RequestId: trashed.requestId,
+	}
+}
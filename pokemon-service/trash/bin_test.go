@@ -0,0 +1,83 @@
+package trash
+
+import (
+	"fmt"
+	"io"
+	"log"
+	schema "pokemon-service/schema"
+	"testing"
+	"time"
+)
+
+func TestBin(t *testing.T) {
+	bin := NewBin(Config{Retention: time.Hour, MaxEntries: 2}, discardLogger())
+	defer bin.Close()
+	bin.Handle(deleted("PK1", "req-1"))
+	time.Sleep(time.Millisecond)
+	bin.Handle(deleted("PK2", "req-2"))
+	bin.Handle(schema.PokemonEvent{Type: schema.EventEvicted, Pokemon: &schema.Pokemon{Id: "PK3"}})
+
+	if listed := ids(bin.List()); listed != "[PK2 PK1]" {
+		t.Errorf("unexpected pokemons in the trash: %v", listed)
+	}
+
+	trashed, ok := bin.Take("PK1")
+	if !ok || trashed.Pokemon.Id != "PK1" || trashed.RequestId != "req-1" {
+		t.Errorf("unexpected pokemon taken: %+v %v", trashed, ok)
+	}
+	if _, ok := bin.Take("PK1"); ok {
+		t.Errorf("pokemon was taken twice")
+	}
+	bin.Return(trashed)
+	if listed := ids(bin.List()); listed != "[PK2 PK1]" {
+		t.Errorf("unexpected pokemons after returning one: %v", listed)
+	}
+
+	//A full bin makes room by purging the pokemon retained the shortest
+	bin.Handle(deleted("PK4", "req-4"))
+	if listed := ids(bin.List()); listed != "[PK4 PK2]" {
+		t.Errorf("unexpected pokemons in a full trash: %v", listed)
+	}
+
+	if purged := bin.purge(time.Now()); purged != 0 {
+		t.Errorf("purged %v pokemons within their retention", purged)
+	}
+	if purged := bin.purge(time.Now().Add(time.Hour)); purged != 2 || len(bin.List()) != 0 {
+		t.Errorf("unexpected purge: %v %v", purged, bin.List())
+	}
+}
+
+func TestPurgeOnSchedule(t *testing.T) {
+	bin := NewBin(Config{Retention: time.Millisecond, PurgeInterval: 5 * time.Millisecond}, discardLogger())
+	defer bin.Close()
+	bin.Handle(deleted("PK1", ""))
+	deadline := time.Now().Add(time.Second)
+	for len(bin.List()) > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if trashed := bin.List(); len(trashed) != 0 {
+		t.Errorf("pokemon past its retention was not purged: %v", trashed)
+	}
+}
+
+func deleted(id string, requestId string) schema.PokemonEvent {
+	return schema.PokemonEvent{Type: schema.EventDeleted, PokemonId: id, Pokemon: &schema.Pokemon{Id: id}, RequestId: requestId}
+}
+
+func ids(trashed []schema.TrashedPokemon) string {
+	listed := []string{}
+	for _, item := range trashed {
+		listed = append(listed, item.Pokemon.Id)
+	}
+	return fmt.Sprint(listed)
+}
+
+func discardLogger() *schema.Logger {
+	return &schema.Logger{
+		InfoLogger:  log.New(io.Discard, "Info:", 0),
+		WarnLogger:  log.New(io.Discard, "Warn:", 0),
+		DebugLogger: log.New(io.Discard, "Debug:", 0),
+		ErrorLogger: log.New(io.Discard, "Error:", 0),
+		FatalLogger: log.New(io.Discard, "Fatal:", 0),
+	}
+}
@@ -2,16 +2,56 @@ package utility
 
 import (
 	schema "pokemon-service/schema"
-	"encoding/json"
+	codec "pokemon-service/codec"
+	"bytes"
+	"errors"
+	"fmt"
 	"net/http"
 	"time"
 )
 
 func FrameHttpResponse(status int, errMsg string, userResp *schema.PokemonResponse, start time.Time, w http.ResponseWriter) {
-	w.WriteHeader(status)
 	userResp.RequestTs = start.Format(time.RFC3339)
 	userResp.RespMessage = errMsg
 	userResp.RespCode = status
 	userResp.Latency = time.Since(start).String()
-	json.NewEncoder(w).Encode(userResp)
-}
\ No newline at end of file
+	writeResponse(status, userResp, userResp.RequestId, start, w)
+}
+
+// Frames response for endpoints returning arbitrary data instead of a single pokemon record
+func FrameHttpDataResponse(status int, errMsg string, userResp *schema.DataResponse, start time.Time, w http.ResponseWriter) {
+	userResp.RequestTs = start.Format(time.RFC3339)
+	userResp.RespMessage = errMsg
+	userResp.RespCode = status
+	userResp.Latency = time.Since(start).String()
+	writeResponse(status, userResp, userResp.RequestId, start, w)
+}
+
+// Encodes userResp in the media type negotiated for w, JSON unless the Negotiate middleware picked another one.
+// Errors the negotiated type cannot carry are still reported in JSON, other payloads it cannot carry get 406.
+func writeResponse(status int, userResp interface{}, requestId string, start time.Time, w http.ResponseWriter) {
+	responseCodec := codec.Of(w)
+	if responseCodec == codec.JSON {
+		w.WriteHeader(status)
+		codec.JSON.Encode(w, userResp)
+		return
+	}
+
+	var body bytes.Buffer
+	err := responseCodec.Encode(&body, userResp)
+	if errors.Is(err, codec.ErrNotRepresentable) && status >= 400 {
+		w.WriteHeader(status)
+		codec.JSON.Encode(w, userResp)
+		return
+	}
+	if err != nil {
+		notAcceptable := schema.DataResponse{RequestId: requestId, RequestTs: start.Format(time.RFC3339), RespCode: http.StatusNotAcceptable,
+			RespMessage: fmt.Sprintf("Response cannot be encoded as %v", responseCodec.MediaType()), Latency: time.Since(start).String()}
+		w.WriteHeader(http.StatusNotAcceptable)
+		codec.JSON.Encode(w, notAcceptable)
+		return
+	}
+	w.Header().Set("Content-Type", responseCodec.MediaType())
+	w.WriteHeader(status)
+	w.Write(body.Bytes())
+}
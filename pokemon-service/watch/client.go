@@ -0,0 +1,205 @@
+package watch
+
+import (
+	"encoding/json"
+	"fmt"
+	schema "pokemon-service/schema"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Single websocket connection and the pokemons it watches
+type client struct {
+	hub    *Hub
+	conn   *websocket.Conn
+	lookup Lookup
+	queue  chan schema.WatchMessage
+
+	mutex sync.Mutex
+	ids   map[string]bool
+	names map[string]bool
+
+	stopOnce    sync.Once
+	done        chan struct{}
+	closeCode   int
+	closeReason string
+}
+
+func newClient(hub *Hub, conn *websocket.Conn, lookup Lookup) *client {
+	return &client{
+		hub:    hub,
+		conn:   conn,
+		lookup: lookup,
+		queue:  make(chan schema.WatchMessage, hub.config.SendBuffer),
+		ids:    map[string]bool{},
+		names:  map[string]bool{},
+		done:   make(chan struct{}),
+	}
+}
+
+// Reads client messages on the calling goroutine and writes on a second one until either side stops
+func (watcher *client) run() {
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+		watcher.write()
+	}()
+
+	watcher.read()
+	watcher.stop(websocket.CloseNormalClosure, "")
+	<-writerDone
+}
+
+func (watcher *client) read() {
+	config := watcher.hub.config
+	watcher.conn.SetReadLimit(config.MaxMessageSize)
+	watcher.conn.SetReadDeadline(time.Now().Add(config.PongWait))
+	watcher.conn.SetPongHandler(func(string) error {
+		return watcher.conn.SetReadDeadline(time.Now().Add(config.PongWait))
+	})
+
+	for {
+		_, data, err := watcher.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var request schema.WatchRequest
+		if err := json.Unmarshal(data, &request); err != nil {
+			watcher.send(schema.WatchMessage{Type: schema.WatchError, Message: "Invalid Json request"})
+			continue
+		}
+		watcher.handle(request)
+	}
+}
+
+func (watcher *client) handle(request schema.WatchRequest) {
+	switch request.Action {
+	case schema.WatchSubscribe:
+		added, err := watcher.subscribe(request)
+		if err != nil {
+			watcher.send(schema.WatchMessage{Type: schema.WatchError, Message: err.Error()})
+			return
+		}
+		//Current record first, so the client has a baseline before any change arrives
+		for _, key := range added {
+			pokemon, err := watcher.lookup(key)
+			if err != nil {
+				watcher.send(schema.WatchMessage{Type: schema.WatchNotFound, PokemonId: key, Message: fmt.Sprintf("Unable to get data from cache for:%v", key)})
+				continue
+			}
+			watcher.send(schema.WatchMessage{Type: schema.WatchSnapshot, PokemonId: pokemon.Id, Pokemon: &pokemon})
+		}
+	case schema.WatchUnsubscribe:
+		watcher.mutex.Lock()
+		for _, id := range request.IDs {
+			delete(watcher.ids, id)
+		}
+		for _, name := range request.Names {
+			delete(watcher.names, name)
+		}
+		watcher.mutex.Unlock()
+	default:
+		watcher.send(schema.WatchMessage{Type: schema.WatchError, Message: fmt.Sprintf("Unknown action:%v", request.Action)})
+	}
+}
+
+// Adds the requested keys and returns the ones that were not watched yet
+func (watcher *client) subscribe(request schema.WatchRequest) ([]string, error) {
+	watcher.mutex.Lock()
+	defer watcher.mutex.Unlock()
+
+	added := []string{}
+	newIds, newNames := map[string]bool{}, map[string]bool{}
+	for _, id := range request.IDs {
+		if len(id) > 0 && !watcher.ids[id] && !newIds[id] {
+			newIds[id] = true
+			added = append(added, id)
+		}
+	}
+	for _, name := range request.Names {
+		if len(name) > 0 && !watcher.names[name] && !newNames[name] {
+			newNames[name] = true
+			added = append(added, name)
+		}
+	}
+	if total := len(watcher.ids) + len(watcher.names) + len(added); total > watcher.hub.config.MaxKeys {
+		return nil, fmt.Errorf("at most %d IDs and names can be watched per connection", watcher.hub.config.MaxKeys)
+	}
+	for id := range newIds {
+		watcher.ids[id] = true
+	}
+	for name := range newNames {
+		watcher.names[name] = true
+	}
+	return added, nil
+}
+
+func (watcher *client) watches(id string, name string) bool {
+	watcher.mutex.Lock()
+	defer watcher.mutex.Unlock()
+	return watcher.ids[id] || (len(name) > 0 && watcher.names[name])
+}
+
+// Queues message without blocking, a client whose queue is full is disconnected
+func (watcher *client) send(message schema.WatchMessage) {
+	select {
+	case <-watcher.done:
+	case watcher.queue <- message:
+	default:
+		watcher.stop(websocket.CloseTryAgainLater, "client is too slow, reconnect to resume")
+	}
+}
+
+func (watcher *client) write() {
+	config := watcher.hub.config
+	ping := time.NewTicker(config.PingPeriod)
+	defer func() {
+		ping.Stop()
+		watcher.conn.Close()
+	}()
+
+	for {
+		select {
+		case message := <-watcher.queue:
+			watcher.conn.SetWriteDeadline(time.Now().Add(config.WriteWait))
+			if err := watcher.conn.WriteJSON(message); err != nil {
+				watcher.stop(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-ping.C:
+			if err := watcher.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(config.WriteWait)); err != nil {
+				watcher.stop(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-watcher.done:
+			if watcher.closeCode != websocket.CloseAbnormalClosure {
+				message := websocket.FormatCloseMessage(watcher.closeCode, watcher.closeReason)
+				watcher.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(config.WriteWait))
+			}
+			return
+		}
+	}
+}
+
+// Ends the connection with the given close code, only the first call has an effect
+func (watcher *client) stop(code int, reason string) {
+	watcher.stopOnce.Do(func() {
+		watcher.closeCode = code
+		watcher.closeReason = reason
+		close(watcher.done)
+	})
+}
+
+// Closes the connection because the server is going away
+func (watcher *client) shutdown() {
+	watcher.stop(websocket.CloseGoingAway, "server is shutting down")
+}
+
+// Closes a connection that never made it into the hub
+func (watcher *client) closeWith(code int, reason string) {
+	message := websocket.FormatCloseMessage(code, reason)
+	watcher.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(watcher.hub.config.WriteWait))
+	watcher.conn.Close()
+}
@@ -0,0 +1,142 @@
+package watch
+
+import (
+	"errors"
+	"net/http"
+	schema "pokemon-service/schema"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Tunes connections, zero values fall back to defaults
+type Config struct {
+	// Messages queued per connection before the client is considered too slow and disconnected
+	SendBuffer int
+	// IDs plus names a single connection may watch
+	MaxKeys int
+	// Largest client message accepted, in bytes
+	MaxMessageSize int64
+	PingPeriod     time.Duration
+	PongWait       time.Duration
+	WriteWait      time.Duration
+}
+
+func (config Config) withDefaults() Config {
+	if config.SendBuffer <= 0 {
+		config.SendBuffer = 64
+	}
+	if config.MaxKeys <= 0 {
+		config.MaxKeys = 100
+	}
+	if config.MaxMessageSize <= 0 {
+		config.MaxMessageSize = 4096
+	}
+	if config.PongWait <= 0 {
+		config.PongWait = 60 * time.Second
+	}
+	if config.PingPeriod <= 0 || config.PingPeriod >= config.PongWait {
+		config.PingPeriod = config.PongWait * 9 / 10
+	}
+	if config.WriteWait <= 0 {
+		config.WriteWait = 10 * time.Second
+	}
+	return config
+}
+
+// Resolves an ID or name to the current record, used to send snapshots on subscribe
+type Lookup func(key string) (schema.Pokemon, error)
+
+var ErrHubClosed = errors.New("watch hub is closed")
+
+// Hub tracks websocket clients watching individual pokemons and pushes changed records to them.
+// Hijacked websocket connections are invisible to http.Server.Shutdown, so the hub closes them itself.
+type Hub struct {
+	config   Config
+	logger   *schema.Logger
+	upgrader websocket.Upgrader
+	mutex    sync.Mutex
+	clients  map[*client]struct{}
+	closed   bool
+	wait     sync.WaitGroup
+}
+
+func NewHub(config Config, logger *schema.Logger) *Hub {
+	return &Hub{
+		config:   config.withDefaults(),
+		logger:   logger,
+		upgrader: websocket.Upgrader{ReadBufferSize: 1024, WriteBufferSize: 1024},
+		clients:  map[*client]struct{}{},
+	}
+}
+
+// Upgrades the request to a websocket and serves the client until it leaves or the hub closes
+func (hub *Hub) Serve(w http.ResponseWriter, req *http.Request, lookup Lookup) error {
+	hub.mutex.Lock()
+	if hub.closed {
+		hub.mutex.Unlock()
+		return ErrHubClosed
+	}
+	hub.wait.Add(1)
+	hub.mutex.Unlock()
+	defer hub.wait.Done()
+
+	conn, err := hub.upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		//Upgrader already answered the request with an error status
+		return err
+	}
+	watcher := newClient(hub, conn, lookup)
+
+	hub.mutex.Lock()
+	if hub.closed {
+		hub.mutex.Unlock()
+		watcher.closeWith(websocket.CloseGoingAway, "server is shutting down")
+		return ErrHubClosed
+	}
+	hub.clients[watcher] = struct{}{}
+	hub.mutex.Unlock()
+
+	watcher.run()
+
+	hub.mutex.Lock()
+	delete(hub.clients, watcher)
+	hub.mutex.Unlock()
+	return nil
+}
+
+// Event bus subscriber, queues event for every client watching the pokemon without blocking
+func (hub *Hub) Broadcast(event schema.PokemonEvent) {
+	message := schema.WatchMessage{Type: event.Type, PokemonId: event.PokemonId, Pokemon: event.Pokemon, Sequence: event.Sequence}
+	name := ""
+	if event.Pokemon != nil {
+		name = event.Pokemon.Name
+	}
+
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+	for watcher := range hub.clients {
+		if watcher.watches(event.PokemonId, name) {
+			watcher.send(message)
+		}
+	}
+}
+
+// Number of connected clients
+func (hub *Hub) Clients() int {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+	return len(hub.clients)
+}
+
+// Sends a going away close frame to every client and waits for their connections to finish
+func (hub *Hub) Close() {
+	hub.mutex.Lock()
+	hub.closed = true
+	for watcher := range hub.clients {
+		watcher.shutdown()
+	}
+	hub.mutex.Unlock()
+	hub.wait.Wait()
+}
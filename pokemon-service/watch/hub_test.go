@@ -0,0 +1,121 @@
+package watch
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"pokemon-service/schema"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestWatchSubscribeAndBroadcast(t *testing.T) {
+	hub, conn := dialHub(t, Config{MaxKeys: 3})
+	defer hub.Close()
+
+	conn.WriteJSON(schema.WatchRequest{Action: schema.WatchSubscribe, IDs: []string{"PK10001", "PK1000908"}, Names: []string{"Fennekin"}})
+	expectMessage(t, conn, schema.WatchSnapshot, "PK10001")
+	expectMessage(t, conn, schema.WatchNotFound, "PK1000908")
+	expectMessage(t, conn, schema.WatchSnapshot, "PK10002")
+
+	//Only changes to watched IDs or names reach the client
+	waitForClients(t, hub, 1)
+	hub.Broadcast(schema.PokemonEvent{Type: schema.EventUpdated, PokemonId: "PK10003", Pokemon: &schema.Pokemon{Id: "PK10003", Name: "Froakie"}})
+	hub.Broadcast(schema.PokemonEvent{Type: schema.EventUpdated, PokemonId: "PK10002", Pokemon: &schema.Pokemon{Id: "PK10002", Name: "Fennekin"}})
+	expectMessage(t, conn, schema.EventUpdated, "PK10002")
+
+	conn.WriteJSON(schema.WatchRequest{Action: schema.WatchSubscribe, IDs: []string{"PK10004", "PK10005"}})
+	expectMessage(t, conn, schema.WatchError, "")
+
+	conn.WriteJSON(schema.WatchRequest{Action: schema.WatchUnsubscribe, IDs: []string{"PK10001"}})
+	conn.WriteJSON(schema.WatchRequest{Action: "rename"})
+	expectMessage(t, conn, schema.WatchError, "")
+	hub.Broadcast(schema.PokemonEvent{Type: schema.EventDeleted, PokemonId: "PK10001", Pokemon: &schema.Pokemon{Id: "PK10001", Name: "Chespin"}})
+	hub.Broadcast(schema.PokemonEvent{Type: schema.EventDeleted, PokemonId: "PK1000908"})
+	expectMessage(t, conn, schema.EventDeleted, "PK1000908")
+}
+
+func TestWatchShutdown(t *testing.T) {
+	hub, conn := dialHub(t, Config{})
+	waitForClients(t, hub, 1)
+
+	hub.Close()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err := conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Errorf("expected going away close, got %v", err)
+	}
+	if hub.Clients() != 0 {
+		t.Errorf("unexpected clients after close: got %v want 0", hub.Clients())
+	}
+}
+
+func TestWatchSlowClient(t *testing.T) {
+	hub := NewHub(Config{SendBuffer: 1}, nil)
+	watcher := &client{hub: hub, queue: make(chan schema.WatchMessage, 1), done: make(chan struct{})}
+
+	watcher.send(schema.WatchMessage{Type: schema.EventUpdated})
+	watcher.send(schema.WatchMessage{Type: schema.EventUpdated})
+	select {
+	case <-watcher.done:
+		if watcher.closeCode != websocket.CloseTryAgainLater {
+			t.Errorf("unexpected close code: got %v want %v", watcher.closeCode, websocket.CloseTryAgainLater)
+		}
+	default:
+		t.Error("slow client was not disconnected")
+	}
+}
+
+func dialHub(t *testing.T, config Config) (*Hub, *websocket.Conn) {
+	t.Helper()
+	pokemons := map[string]schema.Pokemon{
+		"PK10001":  {Id: "PK10001", Name: "Chespin"},
+		"PK10002":  {Id: "PK10002", Name: "Fennekin"},
+		"Fennekin": {Id: "PK10002", Name: "Fennekin"},
+	}
+	lookup := func(key string) (schema.Pokemon, error) {
+		if pokemon, ok := pokemons[key]; ok {
+			return pokemon, nil
+		}
+		return schema.Pokemon{}, errors.New("not found")
+	}
+
+	hub := NewHub(config, nil)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hub.Serve(w, r, lookup)
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return hub, conn
+}
+
+func expectMessage(t *testing.T, conn *websocket.Conn, messageType string, pokemonId string) {
+	t.Helper()
+	var message schema.WatchMessage
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if err := conn.ReadJSON(&message); err != nil {
+		t.Fatalf("waiting for %v: %v", messageType, err)
+	}
+	if message.Type != messageType || message.PokemonId != pokemonId {
+		t.Errorf("unexpected message: got %+v want type %v id %v", message, messageType, pokemonId)
+	}
+}
+
+func waitForClients(t *testing.T, hub *Hub, clients int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for hub.Clients() != clients && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if hub.Clients() != clients {
+		t.Fatalf("unexpected clients: got %v want %v", hub.Clients(), clients)
+	}
+}
@@ -0,0 +1,367 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	schema "pokemon-service/schema"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Headers sent with every delivery
+const (
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+	TimestampHeader = "X-Webhook-Timestamp"
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+var ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+
+// Tunes delivery, zero values fall back to defaults
+type Config struct {
+	Workers        int
+	QueueSize      int
+	MaxAttempts    int
+	BaseDelay      time.Duration
+	MaxDelay       time.Duration
+	MaxDeadLetters int
+	Timeout        time.Duration
+}
+
+func (config Config) withDefaults() Config {
+	if config.Workers <= 0 {
+		config.Workers = 4
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 1024
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 5
+	}
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = 500 * time.Millisecond
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = 30 * time.Second
+	}
+	if config.MaxDeadLetters <= 0 {
+		config.MaxDeadLetters = 1000
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	return config
+}
+
+// Single attempt to hand an event to one subscription
+type delivery struct {
+	id           string
+	subscription schema.WebhookSubscription
+	event        schema.PokemonEvent
+	attempt      int
+}
+
+// Dispatcher keeps webhook subscriptions and delivers catalog events to them.
+// Deliveries are signed with the subscription secret and retried with exponential backoff,
+// deliveries still failing after the last attempt are kept as dead letters for replay.
+type Dispatcher struct {
+	config        Config
+	client        *http.Client
+	logger        *schema.Logger
+	mutex         sync.RWMutex
+	subscriptions map[string]schema.WebhookSubscription
+	deadMutex     sync.Mutex
+	deadLetters   []schema.WebhookDeadLetter
+	queue         chan delivery
+	ctx           context.Context
+	cancel        context.CancelFunc
+	workers       sync.WaitGroup
+}
+
+// Creates a dispatcher and starts its delivery workers, Close stops them
+func NewDispatcher(config Config, logger *schema.Logger) *Dispatcher {
+	config = config.withDefaults()
+	ctx, cancel := context.WithCancel(context.Background())
+	dispatcher := &Dispatcher{
+		config:        config,
+		client:        &http.Client{Timeout: config.Timeout},
+		logger:        logger,
+		subscriptions: map[string]schema.WebhookSubscription{},
+		queue:         make(chan delivery, config.QueueSize),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+	for i := 0; i < config.Workers; i++ {
+		dispatcher.workers.Add(1)
+		go dispatcher.work()
+	}
+	return dispatcher
+}
+
+// Validates and stores a subscription, generating its ID and, when missing, its secret
+func (dispatcher *Dispatcher) Register(subscription schema.WebhookSubscription) (schema.WebhookSubscription, error) {
+	target, err := url.Parse(subscription.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || len(target.Host) <= 0 {
+		return subscription, fmt.Errorf("invalid callback URL:%v", subscription.URL)
+	}
+	if len(subscription.Events) <= 0 {
+		return subscription, errors.New("at least one event type is expected")
+	}
+	for _, eventType := range subscription.Events {
+		if !knownEvent(eventType) {
+			return subscription, fmt.Errorf("unknown event type:%v", eventType)
+		}
+	}
+	if len(subscription.Secret) <= 0 {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return subscription, err
+		}
+		subscription.Secret = hex.EncodeToString(secret)
+	}
+	subscription.Id = uuid.New().String()
+	subscription.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	dispatcher.mutex.Lock()
+	dispatcher.subscriptions[subscription.Id] = subscription
+	dispatcher.mutex.Unlock()
+	return subscription, nil
+}
+
+// Removes a subscription, pending retries for it still run
+func (dispatcher *Dispatcher) Unregister(id string) error {
+	dispatcher.mutex.Lock()
+	defer dispatcher.mutex.Unlock()
+	if _, ok := dispatcher.subscriptions[id]; !ok {
+		return ErrSubscriptionNotFound
+	}
+	delete(dispatcher.subscriptions, id)
+	return nil
+}
+
+// Lists subscriptions ordered by creation, secrets are left out
+func (dispatcher *Dispatcher) Subscriptions() []schema.WebhookSubscription {
+	dispatcher.mutex.RLock()
+	defer dispatcher.mutex.RUnlock()
+	subscriptions := make([]schema.WebhookSubscription, 0, len(dispatcher.subscriptions))
+	for _, subscription := range dispatcher.subscriptions {
+		subscription.Secret = ""
+		subscriptions = append(subscriptions, subscription)
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if subscriptions[i].CreatedAt == subscriptions[j].CreatedAt {
+			return subscriptions[i].Id < subscriptions[j].Id
+		}
+		return subscriptions[i].CreatedAt < subscriptions[j].CreatedAt
+	})
+	return subscriptions
+}
+
+// Event bus subscriber, queues a delivery for every subscription interested in event without blocking
+func (dispatcher *Dispatcher) Handle(event schema.PokemonEvent) {
+	dispatcher.mutex.RLock()
+	defer dispatcher.mutex.RUnlock()
+	for _, subscription := range dispatcher.subscriptions {
+		if subscribed(subscription, event.Type) {
+			dispatcher.enqueue(delivery{id: uuid.New().String(), subscription: subscription, event: event, attempt: 1})
+		}
+	}
+}
+
+// Dead letters ordered from oldest to newest
+func (dispatcher *Dispatcher) DeadLetters() []schema.WebhookDeadLetter {
+	dispatcher.deadMutex.Lock()
+	defer dispatcher.deadMutex.Unlock()
+	return append([]schema.WebhookDeadLetter{}, dispatcher.deadLetters...)
+}
+
+// Queues the listed dead letters, or all of them when ids is empty, for a fresh round of attempts.
+// Dead letters of deleted subscriptions are skipped and kept, nothing is delivered once a subscription is gone.
+func (dispatcher *Dispatcher) Replay(ids []string) schema.WebhookReplayResult {
+	wanted := map[string]bool{}
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	//Secrets may have been rotated since, deliveries are always signed with the current one
+	dispatcher.mutex.RLock()
+	subscriptions := make(map[string]schema.WebhookSubscription, len(dispatcher.subscriptions))
+	for id, subscription := range dispatcher.subscriptions {
+		subscriptions[id] = subscription
+	}
+	dispatcher.mutex.RUnlock()
+
+	dispatcher.deadMutex.Lock()
+	result := schema.WebhookReplayResult{Replayed: []schema.WebhookDeadLetter{}, Skipped: []schema.WebhookDeadLetter{}}
+	remaining := dispatcher.deadLetters[:0]
+	for _, deadLetter := range dispatcher.deadLetters {
+		if len(wanted) > 0 && !wanted[deadLetter.Id] {
+			remaining = append(remaining, deadLetter)
+			continue
+		}
+		if _, ok := subscriptions[deadLetter.SubscriptionId]; !ok {
+			result.Skipped = append(result.Skipped, deadLetter)
+			remaining = append(remaining, deadLetter)
+			continue
+		}
+		result.Replayed = append(result.Replayed, deadLetter)
+	}
+	dispatcher.deadLetters = remaining
+	dispatcher.deadMutex.Unlock()
+
+	for _, deadLetter := range result.Replayed {
+		dispatcher.enqueue(delivery{id: deadLetter.Id, subscription: subscriptions[deadLetter.SubscriptionId], event: deadLetter.Event, attempt: 1})
+	}
+	return result
+}
+
+// Stops the workers, deliveries still queued or waiting for a retry are dropped
+func (dispatcher *Dispatcher) Close() {
+	dispatcher.cancel()
+	dispatcher.workers.Wait()
+}
+
+func (dispatcher *Dispatcher) enqueue(item delivery) {
+	if dispatcher.ctx.Err() != nil {
+		return
+	}
+	select {
+	case dispatcher.queue <- item:
+	default:
+		dispatcher.bury(item, errors.New("delivery queue is full"))
+	}
+}
+
+func (dispatcher *Dispatcher) work() {
+	defer dispatcher.workers.Done()
+	for {
+		select {
+		case <-dispatcher.ctx.Done():
+			return
+		case item := <-dispatcher.queue:
+			dispatcher.attempt(item)
+		}
+	}
+}
+
+// Delivers item once and schedules a retry or buries it on failure
+func (dispatcher *Dispatcher) attempt(item delivery) {
+	err := dispatcher.send(item)
+	if err == nil {
+		return
+	}
+	if item.attempt >= dispatcher.config.MaxAttempts {
+		dispatcher.bury(item, err)
+		return
+	}
+	if dispatcher.logger != nil {
+		dispatcher.logger.WarnLogger.Println("Webhook delivery", item.id, "to", item.subscription.URL, "failed on attempt", item.attempt, "err:", err)
+	}
+	delay := Backoff(item.attempt, dispatcher.config.BaseDelay, dispatcher.config.MaxDelay)
+	item.attempt++
+	time.AfterFunc(delay, func() { dispatcher.enqueue(item) })
+}
+
+func (dispatcher *Dispatcher) send(item delivery) error {
+	body, err := json.Marshal(item.event)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(dispatcher.ctx, dispatcher.config.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, item.subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "Application/json")
+	req.Header.Set(EventHeader, item.event.Type)
+	req.Header.Set(DeliveryHeader, item.id)
+	req.Header.Set(TimestampHeader, timestamp)
+	if len(item.subscription.Secret) > 0 {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(item.subscription.Secret, timestamp, body))
+	}
+
+	resp, err := dispatcher.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("callback answered with status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Keeps a failed delivery as dead letter, dropping the oldest one when the list is full
+func (dispatcher *Dispatcher) bury(item delivery, err error) {
+	if dispatcher.logger != nil {
+		dispatcher.logger.ErrorLogger.Println("Webhook delivery", item.id, "to", item.subscription.URL, "moved to dead letters after", item.attempt, "attempts, err:", err)
+	}
+	dispatcher.deadMutex.Lock()
+	defer dispatcher.deadMutex.Unlock()
+	dispatcher.deadLetters = append(dispatcher.deadLetters, schema.WebhookDeadLetter{
+		Id:             item.id,
+		SubscriptionId: item.subscription.Id,
+		URL:            item.subscription.URL,
+		Event:          item.event,
+		Attempts:       item.attempt,
+		LastError:      err.Error(),
+		FailedAt:       time.Now().UTC().Format(time.RFC3339),
+	})
+	if overflow := len(dispatcher.deadLetters) - dispatcher.config.MaxDeadLetters; overflow > 0 {
+		dispatcher.deadLetters = append([]schema.WebhookDeadLetter{}, dispatcher.deadLetters[overflow:]...)
+	}
+}
+
+// HMAC-SHA256 of "<timestamp>.<body>" keyed with secret, hex encoded.
+// Receivers recompute it to check a delivery came from us and was not replayed later.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Delay before retrying after the given attempt: base doubled per attempt, capped at max
+func Backoff(attempt int, base time.Duration, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	return delay
+}
+
+func subscribed(subscription schema.WebhookSubscription, eventType string) bool {
+	for _, wanted := range subscription.Events {
+		if wanted == eventType || wanted == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func knownEvent(eventType string) bool {
+	switch eventType {
+	case "*", schema.EventCreated, schema.EventUpdated, schema.EventDeleted, schema.EventEvicted:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package webhooks
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"pokemon-service/schema"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRegister(t *testing.T) {
+	dispatcher := NewDispatcher(Config{}, nil)
+	defer dispatcher.Close()
+
+	inputs := []struct {
+		testName     string
+		subscription schema.WebhookSubscription
+		valid        bool
+	}{
+		{testName: "TestRegisterValid", subscription: schema.WebhookSubscription{URL: "https://example.com/hook", Events: []string{schema.EventCreated}}, valid: true},
+		{testName: "TestRegisterWildcard", subscription: schema.WebhookSubscription{URL: "http://example.com/hook", Events: []string{"*"}}, valid: true},
+		{testName: "TestRegisterBadScheme", subscription: schema.WebhookSubscription{URL: "ftp://example.com", Events: []string{schema.EventCreated}}},
+		{testName: "TestRegisterNoEvents", subscription: schema.WebhookSubscription{URL: "https://example.com/hook"}},
+		{testName: "TestRegisterUnknownEvent", subscription: schema.WebhookSubscription{URL: "https://example.com/hook", Events: []string{"pokemon.renamed"}}},
+	}
+
+	for _, item := range inputs {
+		subscription, err := dispatcher.Register(item.subscription)
+		if (err == nil) != item.valid {
+			t.Errorf("%v: unexpected error: %v", item.testName, err)
+			continue
+		}
+		if item.valid && (len(subscription.Id) <= 0 || len(subscription.Secret) <= 0) {
+			t.Errorf("%v: ID and secret should be generated: %+v", item.testName, subscription)
+		}
+	}
+	for _, subscription := range dispatcher.Subscriptions() {
+		if len(subscription.Secret) > 0 {
+			t.Errorf("secret leaked when listing subscriptions")
+		}
+	}
+	if len(dispatcher.Subscriptions()) != 2 {
+		t.Errorf("unexpected subscriptions: got %v want 2", len(dispatcher.Subscriptions()))
+	}
+}
+
+func TestSignedDeliveryWithRetry(t *testing.T) {
+	var calls atomic.Int32
+	delivered := make(chan bool, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		//First attempt fails so the dispatcher has to retry
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		expected := "sha256=" + Sign("secret", r.Header.Get(TimestampHeader), body)
+		delivered <- r.Header.Get(SignatureHeader) == expected && r.Header.Get(EventHeader) == schema.EventCreated
+	}))
+	defer server.Close()
+
+	dispatcher := NewDispatcher(Config{BaseDelay: 10 * time.Millisecond}, nil)
+	defer dispatcher.Close()
+	dispatcher.Register(schema.WebhookSubscription{URL: server.URL, Events: []string{schema.EventCreated}, Secret: "secret"})
+
+	dispatcher.Handle(schema.PokemonEvent{Type: schema.EventDeleted, PokemonId: "PK10001"})
+	dispatcher.Handle(schema.PokemonEvent{Type: schema.EventCreated, PokemonId: "PK10001"})
+
+	select {
+	case valid := <-delivered:
+		if !valid {
+			t.Error("delivery carried an invalid signature or event header")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("delivery was not retried")
+	}
+	if calls.Load() != 2 {
+		t.Errorf("unexpected attempts: got %v want 2", calls.Load())
+	}
+}
+
+func TestDeadLetterAndReplay(t *testing.T) {
+	var healthy atomic.Bool
+	delivered := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		delivered <- struct{}{}
+	}))
+	defer server.Close()
+
+	dispatcher := NewDispatcher(Config{MaxAttempts: 2, BaseDelay: time.Millisecond}, nil)
+	defer dispatcher.Close()
+	dispatcher.Register(schema.WebhookSubscription{URL: server.URL, Events: []string{"*"}})
+	dispatcher.Handle(schema.PokemonEvent{Type: schema.EventDeleted, PokemonId: "PK10001"})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(dispatcher.DeadLetters()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	deadLetters := dispatcher.DeadLetters()
+	if len(deadLetters) != 1 || deadLetters[0].Attempts != 2 {
+		t.Fatalf("unexpected dead letters: %+v", deadLetters)
+	}
+
+	healthy.Store(true)
+	if result := dispatcher.Replay(nil); len(result.Replayed) != 1 || len(result.Skipped) != 0 {
+		t.Errorf("unexpected replay: %+v", result)
+	}
+	select {
+	case <-delivered:
+	case <-time.After(2 * time.Second):
+		t.Fatal("replayed dead letter was not delivered")
+	}
+	if len(dispatcher.DeadLetters()) != 0 {
+		t.Errorf("replayed dead letter is still listed")
+	}
+}
+
+func TestReplayDeletedSubscription(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	dispatcher := NewDispatcher(Config{MaxAttempts: 1, BaseDelay: time.Millisecond}, nil)
+	defer dispatcher.Close()
+	subscription, _ := dispatcher.Register(schema.WebhookSubscription{URL: server.URL, Events: []string{"*"}})
+	dispatcher.Handle(schema.PokemonEvent{Type: schema.EventDeleted, PokemonId: "PK10001"})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(dispatcher.DeadLetters()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if len(dispatcher.DeadLetters()) != 1 {
+		t.Fatalf("unexpected dead letters: %+v", dispatcher.DeadLetters())
+	}
+	if err := dispatcher.Unregister(subscription.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	calls.Store(0)
+	result := dispatcher.Replay(nil)
+	if len(result.Replayed) != 0 || len(result.Skipped) != 1 {
+		t.Errorf("unexpected replay: %+v", result)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if calls.Load() != 0 {
+		t.Errorf("dead letter of a deleted subscription was delivered")
+	}
+	if len(dispatcher.DeadLetters()) != 1 {
+		t.Errorf("skipped dead letter should stay listed")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	inputs := []struct {
+		attempt int
+		delay   time.Duration
+	}{
+		{attempt: 1, delay: time.Second},
+		{attempt: 2, delay: 2 * time.Second},
+		{attempt: 4, delay: 8 * time.Second},
+		{attempt: 10, delay: 30 * time.Second},
+	}
+	for _, item := range inputs {
+		if delay := Backoff(item.attempt, time.Second, 30*time.Second); delay != item.delay {
+			t.Errorf("attempt %v: got %v want %v", item.attempt, delay, item.delay)
+		}
+	}
+}